@@ -1,7 +1,7 @@
-from fastapi import FastAPI
+from fastapi import FastAPI, File, Form, UploadFile
 from app.utils import get_embedding, base64_to_dataurl
-from app.prompts import fewshot_text_prompt, message_photo_prompt, message_audio_prompt, change_table_prompt
-from app.models import Table, ClassifyMessageOutput, InputMessage, InputPhoto, InputAudio, OutputAudio, InputChangeTable
+from app.prompts import fewshot_text_prompt, message_photo_prompt, context_text, message_audio_prompt, change_table_prompt
+from app.models import Table, ClassifyMessageOutput, InputMessage, InputPhoto, InputAudio, OutputAudio, InputChangeTable, RecognitionContext, PhotoType, AudioType
 from app.config import Config
 from langchain_openai import ChatOpenAI
 from transformers import AutoTokenizer, AutoConfig
@@ -9,6 +9,7 @@ import onnxruntime as ort
 from joblib import load
 from openai import AsyncOpenAI
 from pathlib import Path
+from typing import Optional
 import base64
 import io
 import warnings
@@ -46,7 +47,7 @@ clf = load(logreg_model_path)
 
 @app.post("/process_message", response_model=Table, summary="Обработка сообщения", description="Обрабатывает текстовое сообщение и возвращает таблицу.")
 async def process_message(input: InputMessage) -> Table:
-    massage = input.message
+    massage = input.message + context_text(input.context)
     prompt = fewshot_text_prompt.format_prompt(input=massage)
     table = await llm.ainvoke(prompt)
     return table
@@ -62,19 +63,30 @@ async def classify_message(input: InputMessage) -> ClassifyMessageOutput:
 
 @app.post("/process_photo", response_model=Table, summary="Обработка фото", description="Обрабатывает фото и возвращает таблицу.")
 async def process_photo(input: InputPhoto) -> Table:
-    base64_str = input.photo
-    file_type = input.type
+    return await recognize_photo(input.photo, input.type, input.context)
+
+@app.post("/process_photo_file", response_model=Table, summary="Обработка фото (multipart)", description="Обрабатывает фото, переданное как multipart/form-data, и возвращает таблицу.")
+async def process_photo_file(photo: UploadFile = File(...), type: PhotoType = Form(...), context: Optional[str] = Form(None)) -> Table:
+    base64_str = base64.b64encode(await photo.read()).decode()
+    recognition_context = RecognitionContext.model_validate_json(context) if context else None
+    return await recognize_photo(base64_str, type, recognition_context)
+
+async def recognize_photo(base64_str: str, file_type: str, context: Optional[RecognitionContext] = None) -> Table:
     dataurl = base64_to_dataurl(base64_str, file_type)
-    prompt = message_photo_prompt(dataurl)
+    prompt = message_photo_prompt(dataurl, context)
     table = await llm.ainvoke(prompt)
     return table
 
 @app.post("/transcribe_audio", response_model=OutputAudio, summary="Транскрипция аудио", description="Транскрибирует аудиофайл и возвращает текст.")
 async def transcribe_audio(input: InputAudio) -> OutputAudio:
-    base64_str = input.audio
-    file_type = input.type.lower()
-    audio_data = base64.b64decode(base64_str)
+    audio_data = base64.b64decode(input.audio)
+    return await transcribe(audio_data, input.type.lower())
+
+@app.post("/transcribe_audio_file", response_model=OutputAudio, summary="Транскрипция аудио (multipart)", description="Транскрибирует аудиофайл, переданный как multipart/form-data, и возвращает текст.")
+async def transcribe_audio_file(audio: UploadFile = File(...), type: AudioType = Form(...)) -> OutputAudio:
+    return await transcribe(await audio.read(), type.lower())
 
+async def transcribe(audio_data: bytes, file_type: str) -> OutputAudio:
     audio_file = io.BytesIO(audio_data)
     audio_file.name = f"audio.{file_type}"
 
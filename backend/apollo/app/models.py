@@ -1,6 +1,9 @@
 from pydantic import BaseModel, Field
 from typing import List, Union, Optional, Literal
 
+PhotoType = Literal["png", "jpeg", "jpg"]
+AudioType = Literal["mp3", "wav", "ogg", "oga"]
+
 class TableRow(BaseModel):
     date: Optional[str] = Field(None, description="Дата выполнения операции, если представлена в сообщении, в формате ДД.ММ или ДД.ММ.ГГГГ")
     division: str = Field(..., description="Подразделение, выполнявшее работу (например, АОР, Юг, Мир и т.д.)") #
@@ -10,12 +13,23 @@ class TableRow(BaseModel):
     per_operation: Union[int, float, None] = Field(None, description="Накопленный объём работ с начала операции (в гектарах)") #
     val_day: Union[int, float, None] = Field(None, description="Валовый сбор за день (в центнерах), если применимо")
     val_beginning: Union[int, float, None] = Field(None, description="Суммарный валовый сбор с начала операции (в центнерах), если применимо")
+    per_day_unit: Optional[str] = Field(None, description="Единица измерения per_day, если указана в сообщении (га)")
+    per_operation_unit: Optional[str] = Field(None, description="Единица измерения per_operation, если указана в сообщении (га)")
+    val_day_unit: Optional[str] = Field(None, description="Единица измерения val_day, если указана в сообщении (ц, т, кг, л, м3, шт)")
+    val_beginning_unit: Optional[str] = Field(None, description="Единица измерения val_beginning, если указана в сообщении (ц, т, кг, л, м3, шт)")
 
 class Table(BaseModel):
     table: List[TableRow]
 
+class RecognitionContext(BaseModel):
+    division: Optional[str] = Field(None, description="Подразделение по умолчанию для чата")
+    culture: Optional[str] = Field(None, description="Культура по умолчанию для чата")
+    operations: Optional[List[str]] = Field(None, description="Операции, которые обычно присылают в чат")
+    hints: Optional[str] = Field(None, description="Дополнительные подсказки для распознавания")
+
 class InputMessage(BaseModel):
     message: str = Field(..., description="Сообщение от пользователя")
+    context: Optional[RecognitionContext] = Field(None, description="Настройки чата, из которого пришло сообщение")
 
 class ClassifyMessageOutput(BaseModel):
     probability: float = Field(..., description="Вероятность того, что сообщение относится к классу 'Операция'")
@@ -23,11 +37,12 @@ class ClassifyMessageOutput(BaseModel):
     
 class InputPhoto(BaseModel):
     photo: str = Field(..., description="Фотография в формате base64")
-    type: Literal["png", "jpeg", "jpg"] = Field(..., description="Тип фотографии")
+    type: PhotoType = Field(..., description="Тип фотографии")
+    context: Optional[RecognitionContext] = Field(None, description="Настройки чата, из которого пришло фото")
 
 class InputAudio(BaseModel):
     audio: str = Field(..., description="Аудиофайл в формате base64")
-    type: Literal["mp3", "wav", "ogg", "oga"] = Field(..., description="Тип аудиофайла")
+    type: AudioType = Field(..., description="Тип аудиофайла")
 
 class OutputAudio(BaseModel):
     text: str = Field(..., description="Текст, полученный из аудиофайла")
@@ -103,6 +103,13 @@ suffix_text = f"""
     - Если указано только `"Вал X"`, то `val_beginning` не определяется  
     - Если значение не указано вовсе — установи `val_beginning = null`
 
+    14. Поля `per_day_unit`, `per_operation_unit`, `val_day_unit`, `val_beginning_unit` — единицы измерения соответствующих значений.
+    - Если в сообщении значение указано в тоннах, литрах, кубометрах или штуках (тюки, рулоны), не пересчитывай его:
+    запиши число как есть, а единицу — в поле `*_unit`: `т`, `л`, `м3`, `шт`.
+    Пример: "Вал 12 т" → `val_day = 12`, `val_day_unit = "т"`
+    - Если ты перевёл килограммы в центнеры по правилам выше, укажи `ц`.
+    - Если единица не указана, установи `null`.
+
 
 Вспомогательная информация:
     - ```cultures``` - список культур
@@ -209,13 +216,32 @@ change_table_prompt = PromptTemplate(
     suffix=suffix_text
 )
 
-def message_photo_prompt(dataurl: str):
+def context_text(context) -> str:
+    if context is None:
+        return ""
+
+    lines = []
+    if context.division:
+        lines.append(f"- если подразделение не указано, это {context.division}")
+    if context.culture:
+        lines.append(f"- если культура не указана, это {context.culture}")
+    if context.operations:
+        lines.append(f"- в этот чат обычно присылают операции: {', '.join(context.operations)}")
+    if context.hints:
+        lines.append(f"- {context.hints}")
+
+    if not lines:
+        return ""
+
+    return "\n\nОсобенности чата, из которого пришло сообщение:\n" + "\n".join(lines)
+
+def message_photo_prompt(dataurl: str, context=None):
     message_photo_prompt = [
         SystemMessage(content=suffix_text),
         HumanMessage(content=[
             {"type": "text", "text": f"""На основе этого фото и доплнительельной инфромации в таблицых  - ```cultures``` - список культур, ```operations``` - список операций,`
              ``units``` - список подразделений --- сделай правильную JSON таблицу с правильными значениями
-             ПРИ ОПРЕДЕЛЕНИИ ФИНАЛЬНОЙ ТАБЛИЦЫ ИСПОЛЬЗУЙ ПОЛЯ ИЗ ТАБЛИЦЫ С ФОТО В КОТОРЫЕ ПОДРАЗУМЕВАЮТ ИТОГИ ПО ПУ И ДЕНЬ"""},
+             ПРИ ОПРЕДЕЛЕНИИ ФИНАЛЬНОЙ ТАБЛИЦЫ ИСПОЛЬЗУЙ ПОЛЯ ИЗ ТАБЛИЦЫ С ФОТО В КОТОРЫЕ ПОДРАЗУМЕВАЮТ ИТОГИ ПО ПУ И ДЕНЬ""" + context_text(context)},
             {
                 "type": "image_url",
                 "image_url": {
@@ -2,7 +2,9 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
@@ -10,19 +12,47 @@ import (
 
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/clients"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/handlers/admin"
 	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/handlers/telegram"
 	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/handlers/whatsapp"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/managers/completeness"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/managers/dictionary"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/managers/digest"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/managers/export"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/managers/measure"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/managers/normalizer"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/managers/preprocessor"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/managers/progress"
 	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/managers/recognizer"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/managers/reminder"
 	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/managers/reporter"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/managers/review"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/managers/summary"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/managers/validator"
 	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/repositories"
 	"github.com/lild1tz/llm_coding_challenge/backend/libs/go/config"
+	"github.com/lild1tz/llm_coding_challenge/backend/libs/go/loctime"
 )
 
 type Config struct {
 	Services clients.Config
 
-	Recognizer recognizer.Config
-	Reporter   reporter.Config
+	Recognizer   recognizer.Config
+	Reporter     reporter.Config
+	Preprocessor preprocessor.Config
+	Normalizer   normalizer.Config
+	Validator    validator.Config
+	Progress     progress.Config
+	Digest       digest.Config
+	Reminder     reminder.Config
+
+	Admin admin.Config
+
+	// time zone of chat contexts without their own one
+	TimeZone string `json:"TIME_ZONE" cfgDefault:"Europe/Moscow"`
+
+	// dictionaries are reloaded on change notifications and every DictionaryReloadSeconds
+	DictionaryReloadSeconds int `json:"DICTIONARY_RELOAD_SECONDS" cfgDefault:"300"`
 
 	TurnOffTimeoutSecond int `json:"TURN_OFF_TIMEOUT_SECOND" cfgDefault:"1"`
 }
@@ -35,6 +65,11 @@ func main() {
 		log.Fatalf("failed to load config: %v", err)
 	}
 
+	err = loctime.SetDefault(cfg.TimeZone)
+	if err != nil {
+		log.Fatalf("failed to set default time zone: %v", err)
+	}
+
 	clients, err := clients.NewClients(cfg.Services)
 	if err != nil {
 		log.Fatalf("failed to create clients: %v", err)
@@ -44,22 +79,87 @@ func main() {
 
 	repositories := repositories.NewRepositories(clients.Postgres)
 
-	reporter := reporter.NewManager(ctx, cfg.Reporter, clients, repositories)
+	err = repositories.InformationRepo.Watch(ctx, time.Duration(cfg.DictionaryReloadSeconds)*time.Second)
+	if err != nil {
+		log.Printf("failed to load dictionaries, reading them from database: %v", err)
+	}
+
+	summary := summary.NewManager(repositories)
+	export := export.NewManager(repositories)
+	completeness := completeness.NewManager(repositories)
+
+	normalizer := normalizer.NewManager(cfg.Normalizer, repositories)
+
+	reporter := reporter.NewManager(ctx, cfg.Reporter, clients, repositories, summary, export, completeness, normalizer)
+
+	preprocessor := preprocessor.NewManager(cfg.Preprocessor)
 
-	recognizerManager := recognizer.NewManager(ctx, cfg.Recognizer, clients, repositories, reporter)
+	validator := validator.NewManager(cfg.Validator, repositories)
+
+	progress := progress.NewManager(cfg.Progress, repositories)
+
+	measure := measure.NewManager(repositories)
+
+	dictionary := dictionary.NewManager(repositories)
+
+	digest, err := digest.NewManager(cfg.Digest, clients, repositories)
+	if err != nil {
+		log.Fatalf("failed to create digest manager: %v", err)
+	}
+
+	review := review.NewManager(clients, repositories, reporter, progress, validator)
+
+	reminder := reminder.NewManager(cfg.Reminder, clients, repositories, reporter)
+
+	recognizerManager := recognizer.NewManager(ctx, cfg.Recognizer, clients, repositories, reporter, preprocessor, normalizer, measure, validator, progress, review)
 
 	clients.Whatsapp.AddEventHandler(whatsapp.NewHandler(ctx, clients, repositories, recognizerManager))
 
 	clients.Telegram.AddHandler("text", telegram.NewHandler(ctx, clients, repositories, recognizerManager))
 
+	var adminServer *http.Server
+
+	adminHandler, err := admin.NewHandler(cfg.Admin, repositories, normalizer, dictionary, digest)
+	if err != nil {
+		log.Printf("admin API is disabled: %v", err)
+	} else {
+		adminServer = &http.Server{
+			Addr:    cfg.Admin.Addr,
+			Handler: adminHandler,
+		}
+
+		go func() {
+			err := adminServer.ListenAndServe()
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Printf("failed to serve admin API: %v", err)
+			}
+		}()
+	}
+
 	clients.Whatsapp.Connect()
 	go clients.Telegram.Start(ctx)
 
+	// closes the reports that became due while hermes was down
+	reporter.StartScheduler(ctx)
+
+	// sends the digest missed while hermes was down
+	digest.Start(ctx)
+
+	reminder.Start(ctx)
+
 	// Listen to Ctrl+C (you can also do something else that prevents the program from exiting)
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 	<-c
 
 	cancel()
+
+	if adminServer != nil {
+		err = adminServer.Shutdown(context.Background())
+		if err != nil {
+			log.Printf("failed to shutdown admin API: %v", err)
+		}
+	}
+
 	time.Sleep(time.Duration(cfg.TurnOffTimeoutSecond) * time.Second)
 }
@@ -0,0 +1,273 @@
+// hermesctl maintains hermes reference data through the admin API.
+//
+// Usage:
+//
+//	hermesctl list [-at YYYY-MM-DD] [-deleted] <dictionary>
+//	hermesctl get <dictionary> <id>
+//	hermesctl create [-name ...] [-note ...] [-unit ...] [-pu ...] [-department ...] [-area HA] [-from YYYY-MM-DD] <dictionary>
+//	hermesctl update [-name ...] [-note ...] [-unit ...] [-pu ...] [-department ...] [-area HA] [-from YYYY-MM-DD] <dictionary> <id>
+//	hermesctl delete [-at YYYY-MM-DD] <dictionary> <id>
+//	hermesctl history [-id N] <dictionary>
+//	hermesctl export [-at YYYY-MM-DD] <dictionary> > file.csv
+//	hermesctl import <dictionary> <file.csv>
+//
+// Dictionaries are cultures, operations and units, update keeps the fields of
+// the entry that are not given. The API address and token are read from
+// HERMES_ADMIN_URL and HERMES_ADMIN_TOKEN.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const defaultAdminURL = "http://localhost:8080"
+
+type client struct {
+	baseURL string
+	token   string
+	user    string
+	http    *http.Client
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	c := &client{
+		baseURL: strings.TrimSuffix(getenv("HERMES_ADMIN_URL", defaultAdminURL), "/"),
+		token:   os.Getenv("HERMES_ADMIN_TOKEN"),
+		user:    getenv("USER", "hermesctl"),
+		http:    &http.Client{Timeout: time.Minute},
+	}
+
+	err := run(c, os.Args[1], os.Args[2:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "hermesctl:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: hermesctl list|get|create|update|delete|history|export|import [flags] <dictionary> [id|file]")
+	os.Exit(2)
+}
+
+func getenv(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+
+	return fallback
+}
+
+func run(c *client, command string, args []string) error {
+	fs := flag.NewFlagSet(command, flag.ExitOnError)
+
+	at := fs.String("at", "", "date YYYY-MM-DD")
+	deleted := fs.Bool("deleted", false, "include deleted and closed versions")
+	entryID := fs.String("id", "", "entry id")
+	body := map[string]*string{
+		"name":           fs.String("name", "", "culture or operation name, division for units"),
+		"note":           fs.String("note", "", "operation note"),
+		"expected_unit":  fs.String("unit", "", "measure unit of the operation gross values, e.g. т"),
+		"pu":             fs.String("pu", "", "unit PU"),
+		"department":     fs.String("department", "", "unit department"),
+		"effective_from": fs.String("from", "", "effective from YYYY-MM-DD, today if empty"),
+	}
+	area := fs.Float64("area", -1, "unit area, ha")
+
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	positional := fs.Args()
+	if len(positional) == 0 {
+		usage()
+	}
+
+	path := "/dictionaries/" + url.PathEscape(positional[0])
+
+	query := url.Values{}
+	if *at != "" {
+		query.Set("at", *at)
+	}
+
+	switch command {
+	case "list":
+		if *deleted {
+			query.Set("deleted", "true")
+		}
+		return c.do(http.MethodGet, path, query, nil, "")
+	case "get":
+		id, err := argAt(positional, 1, "id")
+		if err != nil {
+			return err
+		}
+		return c.do(http.MethodGet, path+"/"+id, nil, nil, "")
+	case "create", "update":
+		fields := make(map[string]any)
+		if command == "update" {
+			id, err := argAt(positional, 1, "id")
+			if err != nil {
+				return err
+			}
+
+			// the API replaces the whole entry
+			fields, err = c.currentFields(path + "/" + id)
+			if err != nil {
+				return err
+			}
+		}
+
+		for key, value := range body {
+			if *value != "" {
+				fields[key] = *value
+			}
+		}
+		if *area >= 0 {
+			fields["area"] = *area
+		}
+
+		data, err := json.Marshal(fields)
+		if err != nil {
+			return err
+		}
+
+		if command == "create" {
+			return c.do(http.MethodPost, path, nil, bytes.NewReader(data), "application/json")
+		}
+
+		id, err := argAt(positional, 1, "id")
+		if err != nil {
+			return err
+		}
+		return c.do(http.MethodPut, path+"/"+id, nil, bytes.NewReader(data), "application/json")
+	case "delete":
+		id, err := argAt(positional, 1, "id")
+		if err != nil {
+			return err
+		}
+		return c.do(http.MethodDelete, path+"/"+id, query, nil, "")
+	case "history":
+		if *entryID != "" {
+			query.Set("entry_id", *entryID)
+		}
+		return c.do(http.MethodGet, path+"/history", query, nil, "")
+	case "export":
+		return c.do(http.MethodGet, path+"/export", query, nil, "")
+	case "import":
+		name, err := argAt(positional, 1, "file")
+		if err != nil {
+			return err
+		}
+
+		file, err := os.Open(name)
+		if err != nil {
+			return fmt.Errorf("failed to open file: %w", err)
+		}
+		defer file.Close()
+
+		return c.do(http.MethodPost, path+"/import", nil, file, "text/csv")
+	}
+
+	usage()
+	return nil
+}
+
+func argAt(args []string, i int, name string) (string, error) {
+	if i >= len(args) {
+		return "", fmt.Errorf("%s is required", name)
+	}
+
+	return url.PathEscape(args[i]), nil
+}
+
+// editableFields are the fields of an entry the API accepts besides the date
+// the version is effective from.
+var editableFields = []string{"name", "note", "expected_unit", "pu", "department", "area"}
+
+// currentFields returns the editable fields of the entry in force.
+func (c *client) currentFields(path string) (map[string]any, error) {
+	resp, err := c.send(http.MethodGet, path, nil, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var entry map[string]any
+	err = json.NewDecoder(resp.Body).Decode(&entry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode entry: %w", err)
+	}
+
+	fields := make(map[string]any)
+	for _, key := range editableFields {
+		if value, ok := entry[key]; ok && value != nil {
+			fields[key] = value
+		}
+	}
+
+	return fields, nil
+}
+
+// do sends the request and copies the response body to stdout.
+func (c *client) do(method, path string, query url.Values, body io.Reader, contentType string) error {
+	resp, err := c.send(method, path, query, body, contentType)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	_, err = io.Copy(os.Stdout, resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	return nil
+}
+
+// send sends the request, the response of a failed request is an error.
+func (c *client) send(method, path string, query url.Values, body io.Reader, contentType string) (*http.Response, error) {
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequest(method, u, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	req.Header.Set("X-Changed-By", c.user)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		defer resp.Body.Close()
+
+		message, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+
+	return resp, nil
+}
@@ -67,6 +67,7 @@ require (
 	go.mau.fi/util v0.8.6 // indirect
 	golang.org/x/crypto v0.36.0 // indirect
 	golang.org/x/exp v0.0.0-20250305212735-054e65f0b394 // indirect
+	golang.org/x/image v0.25.0
 	golang.org/x/net v0.37.0 // indirect
 	golang.org/x/oauth2 v0.29.0
 	golang.org/x/sync v0.12.0 // indirect
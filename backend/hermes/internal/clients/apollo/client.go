@@ -1,11 +1,14 @@
 package apollo
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"strings"
 
@@ -30,15 +33,16 @@ func (c *client) Release() error {
 }
 
 type RequestBodyProcessMessage struct {
-	Message string `json:"message"`
+	Message string                   `json:"message"`
+	Context *models.RecognitionHints `json:"context,omitempty"`
 }
 
 type ResponseBodyProcessMessage struct {
 	Table models.Table `json:"table"`
 }
 
-func (c *client) PredictTableFromText(ctx context.Context, text string) (models.Table, error) {
-	jsonBody, err := json.Marshal(RequestBodyProcessMessage{Message: text})
+func (c *client) PredictTableFromText(ctx context.Context, text string, hints models.RecognitionHints) (models.Table, error) {
+	jsonBody, err := json.Marshal(RequestBodyProcessMessage{Message: text, Context: contextOf(hints)})
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request body: %w", err)
 	}
@@ -56,7 +60,7 @@ func (c *client) PredictTableFromText(ctx context.Context, text string) (models.
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to get response: %w", err)
+		return nil, fmt.Errorf("failed to get response: status %d", resp.StatusCode)
 	}
 
 	var responseBody ResponseBodyProcessMessage
@@ -98,7 +102,7 @@ func (c *client) CheckVerbiage(ctx context.Context, text string) (bool, error) {
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return false, fmt.Errorf("failed to get response: %w", err)
+		return false, fmt.Errorf("failed to get response: status %d", resp.StatusCode)
 	}
 
 	var responseBody ResponseBodyClassifyMessage
@@ -113,20 +117,50 @@ func (c *client) CheckVerbiage(ctx context.Context, text string) (bool, error) {
 }
 
 type RequestBodyPredictTableFromImage struct {
-	Photo string `json:"photo"`
-	Type  string `json:"type"`
+	Photo   string                   `json:"photo"`
+	Type    string                   `json:"type"`
+	Context *models.RecognitionHints `json:"context,omitempty"`
 }
 
 type ResponseBodyPredictTableFromImage struct {
 	Table models.Table `json:"table"`
 }
 
-func (c *client) PredictTableFromImage(ctx context.Context, image []byte) (models.Table, error) {
-	mime := mimetype.Detect(image)
-	extension := mime.Extension()
-	extension = strings.TrimPrefix(extension, ".")
+func (c *client) PredictTableFromImage(ctx context.Context, image io.Reader, hints models.RecognitionHints) (models.Table, error) {
+	extension, image, err := detectExtension(image)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+
+	if c.cfg.MediaTransport == MediaTransportMultipart {
+		fields := map[string]string{"type": extension}
+		if !hints.IsZero() {
+			hintsJSON, err := json.Marshal(hints)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal context: %w", err)
+			}
+			fields["context"] = string(hintsJSON)
+		}
+
+		var responseBody ResponseBodyPredictTableFromImage
+		err := c.postFile(ctx, "/process_photo_file", "photo", extension, fields, image, &responseBody)
+		if err != nil {
+			return nil, err
+		}
+
+		return responseBody.Table, nil
+	}
+
+	data, err := io.ReadAll(image)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
 
-	jsonBody, err := json.Marshal(RequestBodyPredictTableFromImage{Photo: base64.StdEncoding.EncodeToString(image), Type: extension})
+	jsonBody, err := json.Marshal(RequestBodyPredictTableFromImage{
+		Photo:   base64.StdEncoding.EncodeToString(data),
+		Type:    extension,
+		Context: contextOf(hints),
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request body: %w", err)
 	}
@@ -144,7 +178,7 @@ func (c *client) PredictTableFromImage(ctx context.Context, image []byte) (model
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to get response: %w", err)
+		return nil, fmt.Errorf("failed to get response: status %d", resp.StatusCode)
 	}
 
 	var responseBody ResponseBodyPredictTableFromImage
@@ -167,12 +201,28 @@ type ResponseBodyPredictTextFromAudio struct {
 	Text string `json:"text"`
 }
 
-func (c *client) PredictTextFromAudio(ctx context.Context, audio []byte) (string, error) {
-	mime := mimetype.Detect(audio)
-	extension := mime.Extension()
-	extension = strings.TrimPrefix(extension, ".")
+func (c *client) PredictTextFromAudio(ctx context.Context, audio io.Reader) (string, error) {
+	extension, audio, err := detectExtension(audio)
+	if err != nil {
+		return "", fmt.Errorf("failed to read audio: %w", err)
+	}
+
+	if c.cfg.MediaTransport == MediaTransportMultipart {
+		var responseBody ResponseBodyPredictTextFromAudio
+		err := c.postFile(ctx, "/transcribe_audio_file", "audio", extension, map[string]string{"type": extension}, audio, &responseBody)
+		if err != nil {
+			return "", err
+		}
 
-	jsonBody, err := json.Marshal(RequestBodyPredictTextFromAudio{Audio: base64.StdEncoding.EncodeToString(audio), Type: extension})
+		return responseBody.Text, nil
+	}
+
+	data, err := io.ReadAll(audio)
+	if err != nil {
+		return "", fmt.Errorf("failed to read audio: %w", err)
+	}
+
+	jsonBody, err := json.Marshal(RequestBodyPredictTextFromAudio{Audio: base64.StdEncoding.EncodeToString(data), Type: extension})
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal request body: %w", err)
 	}
@@ -190,7 +240,7 @@ func (c *client) PredictTextFromAudio(ctx context.Context, audio []byte) (string
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("failed to get response: %w", err)
+		return "", fmt.Errorf("failed to get response: status %d", resp.StatusCode)
 	}
 
 	var responseBody ResponseBodyPredictTextFromAudio
@@ -203,3 +253,85 @@ func (c *client) PredictTextFromAudio(ctx context.Context, audio []byte) (string
 
 	return responseBody.Text, nil
 }
+
+// contextOf omits the context from the request when there are no hints.
+func contextOf(hints models.RecognitionHints) *models.RecognitionHints {
+	if hints.IsZero() {
+		return nil
+	}
+
+	return &hints
+}
+
+// mimeHeaderSize is how much of the media mimetype looks at.
+const mimeHeaderSize = 3072
+
+// detectExtension peeks the beginning of the media for its type, the returned
+// reader still yields the whole media.
+func detectExtension(media io.Reader) (string, io.Reader, error) {
+	buffered := bufio.NewReaderSize(media, mimeHeaderSize)
+
+	head, err := buffered.Peek(mimeHeaderSize)
+	if err != nil && err != io.EOF {
+		return "", nil, err
+	}
+
+	mime := mimetype.Detect(head)
+
+	return strings.TrimPrefix(mime.Extension(), "."), buffered, nil
+}
+
+// postFile sends the file and the form fields as multipart/form-data. The body is written
+// through a pipe while the file is read, so the file is never held in memory.
+func (c *client) postFile(ctx context.Context, path string, field string, extension string, fields map[string]string, file io.Reader, responseBody any) error {
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+
+	go func() {
+		var err error
+		for name, value := range fields {
+			err = writer.WriteField(name, value)
+			if err != nil {
+				break
+			}
+		}
+		if err == nil {
+			var part io.Writer
+			part, err = writer.CreateFormFile(field, field+"."+extension)
+			if err == nil {
+				_, err = io.Copy(part, file)
+			}
+		}
+		if err == nil {
+			err = writer.Close()
+		}
+
+		pw.CloseWithError(err)
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.cfg.ApolloURL+path, pr)
+	if err != nil {
+		pr.Close()
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to get response: status %d", resp.StatusCode)
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(responseBody)
+	if err != nil {
+		return fmt.Errorf("failed to decode response body: %w", err)
+	}
+
+	return nil
+}
@@ -2,30 +2,50 @@ package apollo
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
 
 	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
 )
 
 type Client interface {
-	PredictTableFromText(ctx context.Context, text string) (models.Table, error)
-	PredictTableFromImage(ctx context.Context, image []byte) (models.Table, error)
-	PredictTextFromAudio(ctx context.Context, audio []byte) (string, error)
+	PredictTableFromText(ctx context.Context, text string, hints models.RecognitionHints) (models.Table, error)
+	PredictTableFromImage(ctx context.Context, image io.Reader, hints models.RecognitionHints) (models.Table, error)
+	PredictTextFromAudio(ctx context.Context, audio io.Reader) (string, error)
 
 	CheckVerbiage(ctx context.Context, text string) (bool, error)
 
 	Release() error
 }
 
+const (
+	MediaTransportJSON      = "json"
+	MediaTransportMultipart = "multipart"
+)
+
+var ErrUnknownMediaTransport = errors.New("unknown media transport")
+
 type Config struct {
 	ApolloURL string `json:"APOLLO_URL"`
 
+	// MediaTransport is the way photos and audio are sent to Apollo:
+	// "json" with base64 payload or "multipart" with streamed binary body.
+	MediaTransport string `json:"APOLLO_MEDIA_TRANSPORT" cfgDefault:"json"`
+
 	IsStub bool `json:"APOLLO_IS_STUB" cfgDefault:"true"`
 }
 
-func NewClient(cfg Config) Client {
+func NewClient(cfg Config) (Client, error) {
+	switch cfg.MediaTransport {
+	case MediaTransportJSON, MediaTransportMultipart:
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownMediaTransport, cfg.MediaTransport)
+	}
+
 	if cfg.IsStub {
-		return &stubClient{}
+		return &stubClient{}, nil
 	}
 
-	return newClient(cfg)
+	return newClient(cfg), nil
 }
@@ -2,9 +2,11 @@ package apollo
 
 import (
 	"context"
+	"io"
 	"time"
 
 	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
+	"github.com/lild1tz/llm_coding_challenge/backend/libs/go/loctime"
 )
 
 type stubClient struct {
@@ -14,10 +16,10 @@ func (c *stubClient) Release() error {
 	return nil
 }
 
-func (c *stubClient) PredictTableFromText(ctx context.Context, text string) (models.Table, error) {
+func (c *stubClient) PredictTableFromText(ctx context.Context, text string, hints models.RecognitionHints) (models.Table, error) {
 	return models.Table{
 		{
-			Date:         time.Now().Format("2006-01-02"),
+			Date:         models.NewDate(loctime.Transfer(time.Now())),
 			Division:     "АОР",
 			Operation:    "Внесение минеральных удобрений",
 			Culture:      "Пшеница озимая товарная",
@@ -29,10 +31,10 @@ func (c *stubClient) PredictTableFromText(ctx context.Context, text string) (mod
 	}, nil
 }
 
-func (c *stubClient) PredictTableFromImage(ctx context.Context, image []byte) (models.Table, error) {
+func (c *stubClient) PredictTableFromImage(ctx context.Context, image io.Reader, hints models.RecognitionHints) (models.Table, error) {
 	return models.Table{
 		{
-			Date:         time.Now().Format("2006-01-02"),
+			Date:         models.NewDate(loctime.Transfer(time.Now())),
 			Division:     "АОР",
 			Operation:    "Внесение минеральных удобрений",
 			Culture:      "Пшеница озимая товарная",
@@ -44,7 +46,7 @@ func (c *stubClient) PredictTableFromImage(ctx context.Context, image []byte) (m
 	}, nil
 }
 
-func (c *stubClient) PredictTextFromAudio(ctx context.Context, audio []byte) (string, error) {
+func (c *stubClient) PredictTextFromAudio(ctx context.Context, audio io.Reader) (string, error) {
 	return "test", nil
 }
 
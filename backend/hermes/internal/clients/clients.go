@@ -8,6 +8,7 @@ import (
 	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/clients/googledrive"
 	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/clients/minio"
 	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/clients/postgres"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/clients/smtp"
 	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/clients/telegram"
 	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/clients/whatsapp"
 )
@@ -19,6 +20,7 @@ type Config struct {
 	Apollo      apollo.Config
 	Telegram    telegram.Config
 	Minio       minio.Config
+	SMTP        smtp.Config
 }
 
 func NewClients(cfg Config) (*Clients, error) {
@@ -43,7 +45,7 @@ func NewClients(cfg Config) (*Clients, error) {
 		return nil, fmt.Errorf("failed to create googledrive client: %w", err)
 	}
 
-	apolloClient := apollo.NewClient(cfg.Apollo)
+	apolloClient, err := apollo.NewClient(cfg.Apollo)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create apollo client: %w", err)
 	}
@@ -58,6 +60,8 @@ func NewClients(cfg Config) (*Clients, error) {
 		return nil, fmt.Errorf("failed to create minio client: %w", err)
 	}
 
+	smtpClient := smtp.NewClient(cfg.SMTP)
+
 	return &Clients{
 		Postgres:    postgresClient,
 		Whatsapp:    whatsappClient,
@@ -65,6 +69,7 @@ func NewClients(cfg Config) (*Clients, error) {
 		Apollo:      apolloClient,
 		Telegram:    telegramClient,
 		Minio:       minioClient,
+		SMTP:        smtpClient,
 	}, nil
 }
 
@@ -75,6 +80,7 @@ type Clients struct {
 	Apollo      apollo.Client
 	Telegram    *telegram.Client
 	Minio       *minio.Client
+	SMTP        *smtp.Client
 }
 
 func (c *Clients) Release() error {
@@ -4,12 +4,12 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"regexp"
 	"strconv"
 	"strings"
 	"sync"
 
-	"github.com/gabriel-vasile/mimetype"
 	"github.com/gomutex/godocx"
 	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
 	"golang.org/x/oauth2/google"
@@ -101,16 +101,14 @@ func (c *Client) SaveMessage(ctx context.Context, fileName string, text string)
 	return nil
 }
 
-func (c *Client) SaveMedia(ctx context.Context, fileName string, media []byte) error {
-	mime := mimetype.Detect(media)
-
+func (c *Client) SaveMedia(ctx context.Context, fileName string, mimeType string, media io.Reader) error {
 	file := &drive.File{
 		Name:     fileName,
 		Parents:  []string{c.folderID},
-		MimeType: mime.String(),
+		MimeType: mimeType,
 	}
 
-	_, err := c.Drive.Files.Create(file).Media(bytes.NewReader(media)).Do()
+	_, err := c.Drive.Files.Create(file).Media(media).Do()
 	if err != nil {
 		return fmt.Errorf("failed to create file: %w", err)
 	}
@@ -136,11 +134,101 @@ func (c *Client) SaveTable(ctx context.Context, name string, table models.Table)
 		if err := c.addHeaders(fileID); err != nil {
 			return fmt.Errorf("add headers: %w", err)
 		}
+	} else if err := c.migrateHeaders(fileID); err != nil {
+		return fmt.Errorf("migrate headers: %w", err)
+	}
+
+	return c.appendData(fileID, table)
+}
+
+// ReplaceTable rewrites the table with the lines, e.g. when the lines of the
+// report are edited.
+func (c *Client) ReplaceTable(ctx context.Context, name string, table models.Table) error {
+	c.tableMutex.Lock()
+	defer c.tableMutex.Unlock()
+
+	fileID, err := c.findFileInFolder(name)
+	if err != nil {
+		return fmt.Errorf("find file: %w", err)
+	}
+
+	if fileID == "" {
+		fileID, err = c.createSpreadsheet(name)
+		if err != nil {
+			return fmt.Errorf("create spreadsheet: %w", err)
+		}
+	} else {
+		err = c.clearSheet(fileID)
+		if err != nil {
+			return fmt.Errorf("clear sheet: %w", err)
+		}
+	}
+
+	if err := c.addHeaders(fileID); err != nil {
+		return fmt.Errorf("add headers: %w", err)
+	}
+
+	if len(table) == 0 {
+		return nil
 	}
 
 	return c.appendData(fileID, table)
 }
 
+// GetTableRows returns the cells of the table lines as they are in the sheet
+// now, numbers without the formatting of the sheet.
+func (c *Client) GetTableRows(ctx context.Context, name string) ([][]string, error) {
+	c.tableMutex.Lock()
+	defer c.tableMutex.Unlock()
+
+	fileID, err := c.findFileInFolder(name)
+	if err != nil {
+		return nil, fmt.Errorf("find file: %w", err)
+	}
+
+	if fileID == "" {
+		return nil, fmt.Errorf("file not found")
+	}
+
+	resp, err := c.Sheets.Spreadsheets.Values.Get(fileID, "Sheet1!A2:K").
+		ValueRenderOption("UNFORMATTED_VALUE").Do()
+	if err != nil {
+		return nil, fmt.Errorf("get values: %w", err)
+	}
+
+	rows := make([][]string, len(resp.Values))
+	for i, values := range resp.Values {
+		rows[i] = make([]string, len(headers))
+		for j := 0; j < len(values) && j < len(headers); j++ {
+			rows[i][j] = strings.TrimSpace(fmt.Sprint(values[j]))
+		}
+	}
+
+	return rows, nil
+}
+
+// clearSheet removes the values, the highlights and the notes of the sheet.
+func (c *Client) clearSheet(spreadsheetID string) error {
+	_, err := c.Sheets.Spreadsheets.Values.Clear(spreadsheetID, "Sheet1", &sheets.ClearValuesRequest{}).Do()
+	if err != nil {
+		return err
+	}
+
+	sheetId, err := c.getSheetID(spreadsheetID, "Sheet1")
+	if err != nil {
+		return err
+	}
+
+	batchReq := &sheets.BatchUpdateSpreadsheetRequest{Requests: []*sheets.Request{{
+		UpdateCells: &sheets.UpdateCellsRequest{
+			Range:  &sheets.GridRange{SheetId: sheetId},
+			Fields: "userEnteredFormat,note",
+		},
+	}}}
+	_, err = c.Sheets.Spreadsheets.BatchUpdate(spreadsheetID, batchReq).Do()
+	return err
+}
+
 func (c *Client) findFileInFolder(name string) (string, error) {
 	query := fmt.Sprintf("name='%s' and mimeType='application/vnd.google-apps.spreadsheet' and parents in '%s'",
 		name, c.folderID)
@@ -170,31 +258,86 @@ func (c *Client) createSpreadsheet(name string) (string, error) {
 	return resp.Id, nil
 }
 
-func (c *Client) addHeaders(spreadsheetID string) error {
-	headers := []interface{}{"Дата", "Подразделение", "Операция", "Культура",
-		"За день, га", "С начала операции, га", "Вал за день, ц", "Вал с начала, ц"}
+// headers are the columns of the report tables, the unit of the gross values
+// depends on the operation and is given in a column of its own
+var headers = []interface{}{"Дата", "Подразделение", "ПУ", "Отделение", "Операция", "Культура",
+	"За день, га", "С начала операции, га", "Вал за день", "Вал с начала", "Единица вала"}
 
+func (c *Client) addHeaders(spreadsheetID string) error {
 	vr := &sheets.ValueRange{
 		Values: [][]interface{}{headers},
 	}
 
-	_, err := c.Sheets.Spreadsheets.Values.Update(spreadsheetID, "Sheet1!A1:H1", vr).
+	_, err := c.Sheets.Spreadsheets.Values.Update(spreadsheetID, "Sheet1!A1:K1", vr).
 		ValueInputOption("RAW").Do()
 	return err
 }
 
+// migrateHeaders brings the header of the tables created before the current
+// columns up to date. The PU and the department columns are inserted into
+// the oldest tables, the old lines are left with the new columns empty.
+func (c *Client) migrateHeaders(spreadsheetID string) error {
+	resp, err := c.Sheets.Spreadsheets.Values.Get(spreadsheetID, "Sheet1!A1:K1").Do()
+	if err != nil {
+		return err
+	}
+
+	var current []interface{}
+	if len(resp.Values) > 0 {
+		current = resp.Values[0]
+	}
+
+	if fmt.Sprint(current) == fmt.Sprint(headers) {
+		return nil
+	}
+
+	// the oldest header is "Дата", "Подразделение", "Операция", ...
+	if len(current) < 3 || fmt.Sprint(current[2]) != "Операция" {
+		return c.addHeaders(spreadsheetID)
+	}
+
+	sheetId, err := c.getSheetID(spreadsheetID, "Sheet1")
+	if err != nil {
+		return err
+	}
+
+	batchReq := &sheets.BatchUpdateSpreadsheetRequest{Requests: []*sheets.Request{{
+		InsertDimension: &sheets.InsertDimensionRequest{
+			Range: &sheets.DimensionRange{
+				SheetId:    sheetId,
+				Dimension:  "COLUMNS",
+				StartIndex: 2, // Column C
+				EndIndex:   4,
+			},
+		},
+	}}}
+	_, err = c.Sheets.Spreadsheets.BatchUpdate(spreadsheetID, batchReq).Do()
+	if err != nil {
+		return err
+	}
+
+	return c.addHeaders(spreadsheetID)
+}
+
 func (c *Client) appendData(spreadsheetID string, table models.Table) error {
 	values := make([][]interface{}, len(table))
 	for i, row := range table {
 		values[i] = []interface{}{
-			row.Date,
+			row.Date.String(),
 			row.Division,
+			row.PU,
+			row.Department,
 			row.Operation,
 			row.Culture,
 			row.PerDay,
 			row.PerOperation,
 			row.ValDay,
 			row.ValBeginning,
+			"",
+		}
+
+		if row.ValDay != 0 || row.ValBeginning != 0 {
+			values[i][10] = row.ValUnit()
 		}
 	}
 
@@ -246,16 +389,18 @@ func (c *Client) appendData(spreadsheetID string, table models.Table) error {
 
 		if row.DivisionYellow {
 			gridRange := createGridRange(sheetId, rowNumber, 1) // Column B
-			requests = append(requests, createUpdateCellRequest(gridRange))
+			requests = append(requests, createUpdateCellRequest(gridRange, yellow, suggestionNote(row.DivisionSuggestion)))
 		}
 		if row.OperationYellow {
-			gridRange := createGridRange(sheetId, rowNumber, 2) // Column C
-			requests = append(requests, createUpdateCellRequest(gridRange))
+			gridRange := createGridRange(sheetId, rowNumber, 4) // Column E
+			requests = append(requests, createUpdateCellRequest(gridRange, yellow, suggestionNote(row.OperationSuggestion)))
 		}
 		if row.CultureYellow {
-			gridRange := createGridRange(sheetId, rowNumber, 3) // Column D
-			requests = append(requests, createUpdateCellRequest(gridRange))
+			gridRange := createGridRange(sheetId, rowNumber, 5) // Column F
+			requests = append(requests, createUpdateCellRequest(gridRange, yellow, suggestionNote(row.CultureSuggestion)))
 		}
+
+		requests = append(requests, createFlagRequests(sheetId, rowNumber, row.Flags)...)
 	}
 
 	if len(requests) > 0 {
@@ -302,18 +447,74 @@ func createGridRange(sheetId int64, rowNumber, columnIndex int) *sheets.GridRang
 	}
 }
 
-func createUpdateCellRequest(gridRange *sheets.GridRange) *sheets.Request {
+func suggestionNote(suggestion string) string {
+	if suggestion == "" {
+		return ""
+	}
+
+	return "Возможно: " + suggestion
+}
+
+var (
+	yellow = sheetsColor(models.YellowColor)
+
+	// flagColumns are the sheet columns of the flagged fields
+	flagColumns = map[string]int{
+		"date":          0, // Column A
+		"per_day":       6, // Column G
+		"per_operation": 7, // Column H
+		"val_day":       8, // Column I
+		"val_beginning": 9, // Column J
+	}
+)
+
+// createFlagRequests colors the flagged cells, a cell with several flags gets
+// the color of the first one and all the messages in its note.
+func createFlagRequests(sheetId int64, rowNumber int, flags []models.Flag) []*sheets.Request {
+	colors := make(map[int]*sheets.Color)
+	notes := make(map[int][]string)
+	var columns []int
+
+	for _, flag := range flags {
+		column, ok := flagColumns[flag.Field]
+		if !ok {
+			continue
+		}
+
+		if _, ok := colors[column]; !ok {
+			colors[column] = sheetsColor(models.FlagColor(flag.Code))
+			columns = append(columns, column)
+		}
+
+		notes[column] = append(notes[column], flag.Message)
+	}
+
+	requests := make([]*sheets.Request, 0, len(columns))
+	for _, column := range columns {
+		gridRange := createGridRange(sheetId, rowNumber, column)
+		requests = append(requests, createUpdateCellRequest(gridRange, colors[column], strings.Join(notes[column], "\n")))
+	}
+
+	return requests
+}
+
+func sheetsColor(color models.Color) *sheets.Color {
+	return &sheets.Color{Red: color.Red, Green: color.Green, Blue: color.Blue}
+}
+
+func createUpdateCellRequest(gridRange *sheets.GridRange, color *sheets.Color, note string) *sheets.Request {
 	return &sheets.Request{
 		UpdateCells: &sheets.UpdateCellsRequest{
 			Range:  gridRange,
-			Fields: "userEnteredFormat.backgroundColor",
+			Fields: "userEnteredFormat.backgroundColor,note",
 			Rows: []*sheets.RowData{
 				{
 					Values: []*sheets.CellData{
 						{
 							UserEnteredFormat: &sheets.CellFormat{
-								BackgroundColor: &sheets.Color{Red: 1.0, Green: 1.0, Blue: 0.0},
+								BackgroundColor: color,
 							},
+							Note: note,
 						},
 					},
 				},
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
@@ -77,7 +78,7 @@ func (c *Client) Release() error {
 	return nil
 }
 
-func (c *Client) UploadFile(ctx context.Context, fileName string, data []byte) (string, error) {
+func (c *Client) UploadFile(ctx context.Context, fileName string, file io.Reader) (string, error) {
 	initResp, err := c.S3.CreateMultipartUpload(ctx, &s3lib.CreateMultipartUploadInput{
 		Bucket: &c.cfg.Bucket,
 		Key:    &fileName,
@@ -91,12 +92,15 @@ func (c *Client) UploadFile(ctx context.Context, fileName string, data []byte) (
 	partNumber := int32(1)
 	buffer := make([]byte, c.cfg.ChunkSize)
 
-	file := bytes.NewReader(data)
-
 	for {
-
-		n, err := file.Read(buffer)
-		if err != nil && err.Error() != "EOF" {
+		// every part but the last must be of the full chunk size
+		n, err := io.ReadFull(file, buffer)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			c.S3.AbortMultipartUpload(ctx, &s3lib.AbortMultipartUploadInput{
+				Bucket:   &c.cfg.Bucket,
+				Key:      &fileName,
+				UploadId: uploadID,
+			})
 			return "", fmt.Errorf("failed to read file: %w", err)
 		}
 		if n == 0 {
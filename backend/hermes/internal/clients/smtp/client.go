@@ -0,0 +1,144 @@
+package smtp
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net"
+	gosmtp "net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Config struct {
+	// mail is not sent when Host is empty
+	Host     string `json:"SMTP_HOST"`
+	Port     int    `json:"SMTP_PORT" cfgDefault:"587"`
+	Username string `json:"SMTP_USERNAME"`
+	Password string `json:"SMTP_PASSWORD"`
+	From     string `json:"SMTP_FROM"`
+}
+
+var ErrNotConfigured = errors.New("smtp is not configured")
+
+func NewClient(cfg Config) *Client {
+	return &Client{cfg: cfg}
+}
+
+type Client struct {
+	cfg Config
+}
+
+// Attachment is a file attached to the mail.
+type Attachment struct {
+	Name     string
+	MimeType string
+	Data     []byte
+}
+
+func (c *Client) IsConfigured() bool {
+	return c.cfg.Host != ""
+}
+
+// SendMail sends the plain text mail with the attachments. The server must
+// support STARTTLS when a username is set.
+func (c *Client) SendMail(ctx context.Context, to []string, subject string, body string, attachments []Attachment) error {
+	if !c.IsConfigured() {
+		return ErrNotConfigured
+	}
+
+	message, err := c.compose(to, subject, body, attachments)
+	if err != nil {
+		return fmt.Errorf("failed to compose mail: %w", err)
+	}
+
+	var auth gosmtp.Auth
+	if c.cfg.Username != "" {
+		auth = gosmtp.PlainAuth("", c.cfg.Username, c.cfg.Password, c.cfg.Host)
+	}
+
+	addr := net.JoinHostPort(c.cfg.Host, strconv.Itoa(c.cfg.Port))
+
+	done := make(chan error, 1)
+	go func() {
+		done <- gosmtp.SendMail(addr, auth, c.cfg.From, to, message)
+	}()
+
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		return fmt.Errorf("failed to send mail: %w", ctx.Err())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to send mail: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Client) compose(to []string, subject string, body string, attachments []Attachment) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	headers := []string{
+		"From: " + c.cfg.From,
+		"To: " + strings.Join(to, ", "),
+		"Subject: " + mime.BEncoding.Encode("utf-8", subject),
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		`Content-Type: multipart/mixed; boundary="` + writer.Boundary() + `"`,
+	}
+	buf.WriteString(strings.Join(headers, "\r\n") + "\r\n\r\n")
+
+	err := writePart(writer, textproto.MIMEHeader{
+		"Content-Type":              {"text/plain; charset=utf-8"},
+		"Content-Transfer-Encoding": {"base64"},
+	}, []byte(body))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, attachment := range attachments {
+		err := writePart(writer, textproto.MIMEHeader{
+			"Content-Type":              {attachment.MimeType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Name})},
+		}, attachment.Data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writePart writes the data in base64 lines of 76 characters.
+func writePart(writer *multipart.Writer, header textproto.MIMEHeader, data []byte) error {
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return err
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		_, err = part.Write([]byte(encoded[:76] + "\r\n"))
+		if err != nil {
+			return err
+		}
+		encoded = encoded[76:]
+	}
+
+	_, err = part.Write([]byte(encoded + "\r\n"))
+	return err
+}
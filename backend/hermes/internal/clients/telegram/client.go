@@ -62,8 +62,25 @@ func (c *Client) Start(ctx context.Context) error {
 	return nil
 }
 
-func (c *Client) SendReport(ctx context.Context, chatName string, url string) error {
-	msg := tgbotapi.NewMessage(models.ToTelegramChatName(chatName), url)
+// SendReport sends the report summary formatted as Telegram HTML.
+func (c *Client) SendReport(ctx context.Context, chatName string, summary string) error {
+	msg := tgbotapi.NewMessage(models.ToTelegramChatName(chatName), summary)
+	msg.ParseMode = tgbotapi.ModeHTML
+	_, err := c.Bot.Send(msg)
+	return err
+}
+
+// SendDocument sends the file with a Telegram HTML caption.
+func (c *Client) SendDocument(ctx context.Context, chatName string, fileName string, data []byte, caption string) error {
+	msg := tgbotapi.NewDocument(models.ToTelegramChatName(chatName), tgbotapi.FileBytes{Name: fileName, Bytes: data})
+	msg.Caption = caption
+	msg.ParseMode = tgbotapi.ModeHTML
+	_, err := c.Bot.Send(msg)
+	return err
+}
+
+func (c *Client) SendText(ctx context.Context, chatName string, text string) error {
+	msg := tgbotapi.NewMessage(models.ToTelegramChatName(chatName), text)
 	_, err := c.Bot.Send(msg)
 	return err
 }
@@ -75,14 +75,75 @@ func (c *Client) Start() error {
 	return nil
 }
 
-func (c *Client) SendReport(ctx context.Context, chatID string, listenerID int, url string) error {
+// SendReport sends the report summary formatted with WhatsApp markup.
+func (c *Client) SendReport(ctx context.Context, chatID string, listenerID int, summary string) error {
+	return c.SendText(ctx, chatID, summary)
+}
+
+// SendDocument uploads the file and sends it with the caption.
+func (c *Client) SendDocument(ctx context.Context, chatID string, fileName string, mimeType string, data []byte, caption string) error {
+	jid, err := types.ParseJID(chatID)
+	if err != nil {
+		return fmt.Errorf("failed to parse JID: %w", err)
+	}
+
+	uploaded, err := c.Client.Upload(ctx, data, whatsmeow.MediaDocument)
+	if err != nil {
+		return fmt.Errorf("failed to upload WhatsApp document: %w", err)
+	}
+
+	document := &waE2E.DocumentMessage{
+		URL:           proto.String(uploaded.URL),
+		DirectPath:    proto.String(uploaded.DirectPath),
+		MediaKey:      uploaded.MediaKey,
+		FileEncSHA256: uploaded.FileEncSHA256,
+		FileSHA256:    uploaded.FileSHA256,
+		FileLength:    proto.Uint64(uploaded.FileLength),
+		Mimetype:      proto.String(mimeType),
+		FileName:      proto.String(fileName),
+		Title:         proto.String(fileName),
+	}
+	if caption != "" {
+		document.Caption = proto.String(caption)
+	}
+
+	_, err = c.Client.SendMessage(ctx, jid, &waE2E.Message{DocumentMessage: document})
+	if err != nil {
+		return fmt.Errorf("failed to send WhatsApp document: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Client) SendText(ctx context.Context, chatID string, text string) error {
+	jid, err := types.ParseJID(chatID)
+	if err != nil {
+		return fmt.Errorf("failed to parse JID: %w", err)
+	}
+
+	_, err = c.Client.SendMessage(ctx, jid, &waE2E.Message{
+		Conversation: proto.String(text),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send WhatsApp message: %w", err)
+	}
+
+	return nil
+}
+
+// SendMentions sends the text mentioning the accounts, the text refers to an
+// account as "@" followed by the user part of its JID.
+func (c *Client) SendMentions(ctx context.Context, chatID string, text string, mentioned []string) error {
 	jid, err := types.ParseJID(chatID)
 	if err != nil {
 		return fmt.Errorf("failed to parse JID: %w", err)
 	}
 
 	_, err = c.Client.SendMessage(ctx, jid, &waE2E.Message{
-		Conversation: proto.String(url),
+		ExtendedTextMessage: &waE2E.ExtendedTextMessage{
+			Text:        proto.String(text),
+			ContextInfo: &waE2E.ContextInfo{MentionedJID: mentioned},
+		},
 	})
 	if err != nil {
 		return fmt.Errorf("failed to send WhatsApp message: %w", err)
@@ -0,0 +1,122 @@
+package admin
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/repositories/information"
+)
+
+// listAliases returns aliases of the dictionary, ?pending=true for the ones waiting for approval.
+func (h *Handler) listAliases(w http.ResponseWriter, r *http.Request) {
+	dictionary := models.Dictionary(r.URL.Query().Get("dictionary"))
+
+	dictionaries := models.Dictionaries
+	if dictionary != "" {
+		if !dictionary.IsValid() {
+			writeError(w, http.StatusBadRequest, "unknown dictionary")
+			return
+		}
+
+		dictionaries = []models.Dictionary{dictionary}
+	}
+
+	pendingOnly := r.URL.Query().Get("pending") == "true"
+
+	result := make([]models.Alias, 0)
+	for _, dictionary := range dictionaries {
+		aliases, err := h.repositories.InformationRepo.ListAliases(r.Context(), dictionary, pendingOnly)
+		if err != nil {
+			log.Printf("failed to list aliases: %v", err)
+			writeError(w, http.StatusInternalServerError, "failed to list aliases")
+			return
+		}
+
+		result = append(result, aliases...)
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+type requestBodyAddAlias struct {
+	Alias string `json:"alias"`
+	Value string `json:"value"`
+	// the alias is global when chat context is not set
+	ChatContextID *int `json:"chat_context_id"`
+}
+
+// addAlias records a correction made by an admin. The dictionary is detected by the value.
+func (h *Handler) addAlias(w http.ResponseWriter, r *http.Request) {
+	var body requestBodyAddAlias
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil || body.Alias == "" || body.Value == "" {
+		writeError(w, http.StatusBadRequest, "alias and value are required")
+		return
+	}
+
+	var alias models.Alias
+	if body.ChatContextID != nil {
+		alias, err = h.normalizer.LearnCorrection(r.Context(), body.Alias, body.Value, *body.ChatContextID, models.AliasSourceAdmin)
+	} else {
+		alias, err = h.normalizer.LearnGlobalAlias(r.Context(), body.Alias, body.Value, models.AliasSourceAdmin)
+	}
+	if errors.Is(err, information.ErrUnknownValue) {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	if err != nil {
+		log.Printf("failed to add alias: %v", err)
+		writeError(w, http.StatusInternalServerError, "failed to add alias")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, alias)
+}
+
+func (h *Handler) approveAlias(w http.ResponseWriter, r *http.Request) {
+	dictionary := models.Dictionary(r.PathValue("dictionary"))
+	id, ok := pathID(r, "id")
+	if !dictionary.IsValid() || !ok {
+		writeError(w, http.StatusNotFound, "alias not found")
+		return
+	}
+
+	err := h.repositories.InformationRepo.ApproveAlias(r.Context(), dictionary, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "alias not found")
+		return
+	}
+	if err != nil {
+		log.Printf("failed to approve alias: %v", err)
+		writeError(w, http.StatusInternalServerError, "failed to approve alias")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) deleteAlias(w http.ResponseWriter, r *http.Request) {
+	dictionary := models.Dictionary(r.PathValue("dictionary"))
+	id, ok := pathID(r, "id")
+	if !dictionary.IsValid() || !ok {
+		writeError(w, http.StatusNotFound, "alias not found")
+		return
+	}
+
+	err := h.repositories.InformationRepo.DeleteAlias(r.Context(), dictionary, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "alias not found")
+		return
+	}
+	if err != nil {
+		log.Printf("failed to delete alias: %v", err)
+		writeError(w, http.StatusInternalServerError, "failed to delete alias")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,203 @@
+package admin
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
+	"github.com/lild1tz/llm_coding_challenge/backend/libs/go/loctime"
+)
+
+func (h *Handler) listChatContexts(w http.ResponseWriter, r *http.Request) {
+	chatContexts, err := h.repositories.ChatsRepo.ListChatContexts(r.Context())
+	if err != nil {
+		log.Printf("failed to list chat contexts: %v", err)
+		writeError(w, http.StatusInternalServerError, "failed to list chat contexts")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, chatContexts)
+}
+
+func (h *Handler) getChatContextSettings(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.chatContextID(w, r)
+	if !ok {
+		return
+	}
+
+	settings, err := h.repositories.ChatsRepo.GetSettings(r.Context(), id)
+	if err != nil {
+		log.Printf("failed to get chat context settings: %v", err)
+		writeError(w, http.StatusInternalServerError, "failed to get chat context settings")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, settings)
+}
+
+type requestBodyChatContextSettings struct {
+	DefaultDivision    string   `json:"default_division"`
+	DefaultCulture     string   `json:"default_culture"`
+	ExpectedOperations []string `json:"expected_operations"`
+	PromptHints        string   `json:"prompt_hints"`
+	ReportSchedule     []string `json:"report_schedule"`
+	TimeZone           string   `json:"time_zone"`
+	ReportDelivery     string   `json:"report_delivery"`
+	ReviewRequired     bool     `json:"review_required"`
+	RemindBefore       *int     `json:"remind_before_minutes"`
+	EscalateAfter      *int     `json:"escalate_after_minutes"`
+}
+
+// updateChatContextSettings replaces the settings of the chat context. The
+// culture and the operations must be in the dictionaries in force, the
+// schedule, the time zone, the report delivery and the reminders must be valid.
+func (h *Handler) updateChatContextSettings(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.chatContextID(w, r)
+	if !ok {
+		return
+	}
+
+	var body requestBodyChatContextSettings
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	settings := models.ChatContextSettings{
+		ChatContextID:      id,
+		DefaultDivision:    strings.TrimSpace(body.DefaultDivision),
+		DefaultCulture:     strings.TrimSpace(body.DefaultCulture),
+		ExpectedOperations: make([]string, 0, len(body.ExpectedOperations)),
+		PromptHints:        strings.TrimSpace(body.PromptHints),
+		ReportSchedule:     make([]string, 0, len(body.ReportSchedule)),
+		TimeZone:           strings.TrimSpace(body.TimeZone),
+		ReportDelivery:     models.ReportDelivery(strings.TrimSpace(body.ReportDelivery)),
+		ReviewRequired:     body.ReviewRequired,
+		RemindBefore:       body.RemindBefore,
+		EscalateAfter:      body.EscalateAfter,
+	}
+
+	if settings.ReportDelivery == "" {
+		settings.ReportDelivery = models.DeliveryLink
+	}
+	if !settings.ReportDelivery.IsValid() {
+		writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("unknown report delivery %q", settings.ReportDelivery))
+		return
+	}
+
+	if settings.RemindBefore != nil && *settings.RemindBefore <= 0 {
+		writeError(w, http.StatusUnprocessableEntity, "reminder minutes must be positive")
+		return
+	}
+	if settings.EscalateAfter != nil && *settings.EscalateAfter < 0 {
+		writeError(w, http.StatusUnprocessableEntity, "escalation minutes must not be negative")
+		return
+	}
+
+	for _, cutoff := range body.ReportSchedule {
+		cutoff = strings.TrimSpace(cutoff)
+		if cutoff != "" {
+			settings.ReportSchedule = append(settings.ReportSchedule, cutoff)
+		}
+	}
+
+	_, err = models.ParseSchedule(settings.ReportSchedule)
+	if err != nil && !errors.Is(err, models.ErrScheduleEmpty) {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	_, err = loctime.Load(settings.TimeZone)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	for _, operation := range body.ExpectedOperations {
+		operation = strings.TrimSpace(operation)
+		if operation != "" && !slices.Contains(settings.ExpectedOperations, operation) {
+			settings.ExpectedOperations = append(settings.ExpectedOperations, operation)
+		}
+	}
+
+	err = h.checkSettings(r, settings)
+	if errors.Is(err, errUnknownValue) {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	if err != nil {
+		log.Printf("failed to check chat context settings: %v", err)
+		writeError(w, http.StatusInternalServerError, "failed to check chat context settings")
+		return
+	}
+
+	settings, err = h.repositories.ChatsRepo.SaveSettings(r.Context(), settings)
+	if err != nil {
+		log.Printf("failed to save chat context settings: %v", err)
+		writeError(w, http.StatusInternalServerError, "failed to save chat context settings")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, settings)
+}
+
+var errUnknownValue = errors.New("unknown dictionary value")
+
+func (h *Handler) checkSettings(r *http.Request, settings models.ChatContextSettings) error {
+	if settings.DefaultCulture != "" {
+		cultures, err := h.repositories.InformationRepo.GetCultures(r.Context(), time.Now())
+		if err != nil {
+			return fmt.Errorf("failed to get cultures: %w", err)
+		}
+
+		if !slices.Contains(cultures, settings.DefaultCulture) {
+			return fmt.Errorf("%w: culture %s", errUnknownValue, settings.DefaultCulture)
+		}
+	}
+
+	if len(settings.ExpectedOperations) > 0 {
+		operations, err := h.repositories.InformationRepo.GetOperations(r.Context(), time.Now())
+		if err != nil {
+			return fmt.Errorf("failed to get operations: %w", err)
+		}
+
+		for _, operation := range settings.ExpectedOperations {
+			if !slices.Contains(operations, operation) {
+				return fmt.Errorf("%w: operation %s", errUnknownValue, operation)
+			}
+		}
+	}
+
+	return nil
+}
+
+// chatContextID reads the id of an existing chat context from the path, the
+// error response is written when it is not found.
+func (h *Handler) chatContextID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, ok := pathID(r, "id")
+	if !ok {
+		writeError(w, http.StatusNotFound, "chat context not found")
+		return 0, false
+	}
+
+	_, err := h.repositories.ChatsRepo.GetChatContextName(r.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "chat context not found")
+		return 0, false
+	}
+	if err != nil {
+		log.Printf("failed to get chat context: %v", err)
+		writeError(w, http.StatusInternalServerError, "failed to get chat context")
+		return 0, false
+	}
+
+	return id, true
+}
@@ -0,0 +1,287 @@
+package admin
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/managers/dictionary"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
+)
+
+// changedByHeader names the person in the dictionary history, the token is shared.
+const changedByHeader = "X-Changed-By"
+
+func changedBy(r *http.Request) string {
+	if name := r.Header.Get(changedByHeader); name != "" {
+		return name
+	}
+
+	return "admin"
+}
+
+func pathDictionary(w http.ResponseWriter, r *http.Request) (models.Dictionary, bool) {
+	d := models.Dictionary(r.PathValue("dictionary"))
+	if !d.IsValid() {
+		writeError(w, http.StatusNotFound, "unknown dictionary")
+		return "", false
+	}
+
+	return d, true
+}
+
+// queryDate parses the optional YYYY-MM-DD query parameter.
+func queryDate(r *http.Request, name string) (*time.Time, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	date, err := dictionary.ParseDate(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &date, nil
+}
+
+func (h *Handler) writeDictionaryError(w http.ResponseWriter, action string, err error) {
+	switch {
+	case errors.Is(err, dictionary.ErrInvalidEntry):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, sql.ErrNoRows):
+		writeError(w, http.StatusNotFound, "entry not found")
+	default:
+		log.Printf("failed to %s: %v", action, err)
+		writeError(w, http.StatusInternalServerError, "failed to "+action)
+	}
+}
+
+// listEntries returns current entries, ?at=YYYY-MM-DD for the ones in force
+// at the date, ?deleted=true for every version.
+func (h *Handler) listEntries(w http.ResponseWriter, r *http.Request) {
+	d, ok := pathDictionary(w, r)
+	if !ok {
+		return
+	}
+
+	at, err := queryDate(r, "at")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	entries, err := h.dictionary.List(r.Context(), d, at, r.URL.Query().Get("deleted") == "true")
+	if err != nil {
+		h.writeDictionaryError(w, "list entries", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, entries)
+}
+
+func (h *Handler) getEntry(w http.ResponseWriter, r *http.Request) {
+	d, ok := pathDictionary(w, r)
+	if !ok {
+		return
+	}
+
+	id, ok := pathID(r, "id")
+	if !ok {
+		writeError(w, http.StatusNotFound, "entry not found")
+		return
+	}
+
+	entry, err := h.dictionary.Get(r.Context(), d, id)
+	if err != nil {
+		h.writeDictionaryError(w, "get entry", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, entry)
+}
+
+type requestBodyEntry struct {
+	Name string `json:"name"`
+	Note string `json:"note"`
+	// measure unit code of the gross values of the operation
+	ExpectedUnit string   `json:"expected_unit"`
+	PU           string   `json:"pu"`
+	Department   string   `json:"department"`
+	Area         *float64 `json:"area"`
+	// YYYY-MM-DD, today if empty
+	EffectiveFrom string `json:"effective_from"`
+}
+
+func decodeEntry(r *http.Request, d models.Dictionary) (models.DictionaryEntry, error) {
+	var body requestBodyEntry
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		return models.DictionaryEntry{}, fmt.Errorf("%w: %v", dictionary.ErrInvalidEntry, err)
+	}
+
+	entry := models.DictionaryEntry{
+		Dictionary:   d,
+		Name:         body.Name,
+		Note:         body.Note,
+		ExpectedUnit: body.ExpectedUnit,
+		PU:           body.PU,
+		Department:   body.Department,
+		Area:         body.Area,
+	}
+
+	if body.EffectiveFrom != "" {
+		entry.EffectiveFrom, err = dictionary.ParseDate(body.EffectiveFrom)
+		if err != nil {
+			return models.DictionaryEntry{}, err
+		}
+	}
+
+	return entry, nil
+}
+
+func (h *Handler) createEntry(w http.ResponseWriter, r *http.Request) {
+	d, ok := pathDictionary(w, r)
+	if !ok {
+		return
+	}
+
+	entry, err := decodeEntry(r, d)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	entry, err = h.dictionary.Create(r.Context(), entry, changedBy(r))
+	if err != nil {
+		h.writeDictionaryError(w, "create entry", err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, entry)
+}
+
+// updateEntry returns the new version of the entry, its id differs from the
+// requested one when the change is effective later than the current version.
+func (h *Handler) updateEntry(w http.ResponseWriter, r *http.Request) {
+	d, ok := pathDictionary(w, r)
+	if !ok {
+		return
+	}
+
+	id, ok := pathID(r, "id")
+	if !ok {
+		writeError(w, http.StatusNotFound, "entry not found")
+		return
+	}
+
+	entry, err := decodeEntry(r, d)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	entry, err = h.dictionary.Update(r.Context(), id, entry, changedBy(r))
+	if err != nil {
+		h.writeDictionaryError(w, "update entry", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, entry)
+}
+
+// deleteEntry soft-deletes the entry from today, ?at=YYYY-MM-DD for another date.
+func (h *Handler) deleteEntry(w http.ResponseWriter, r *http.Request) {
+	d, ok := pathDictionary(w, r)
+	if !ok {
+		return
+	}
+
+	id, ok := pathID(r, "id")
+	if !ok {
+		writeError(w, http.StatusNotFound, "entry not found")
+		return
+	}
+
+	at, err := queryDate(r, "at")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = h.dictionary.Delete(r.Context(), d, id, at, changedBy(r))
+	if err != nil {
+		h.writeDictionaryError(w, "delete entry", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getHistory returns changes of the dictionary, ?entry_id= for a single entry.
+func (h *Handler) getHistory(w http.ResponseWriter, r *http.Request) {
+	d, ok := pathDictionary(w, r)
+	if !ok {
+		return
+	}
+
+	var entryID *int
+	if value := r.URL.Query().Get("entry_id"); value != "" {
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid entry_id")
+			return
+		}
+
+		entryID = &id
+	}
+
+	changes, err := h.dictionary.History(r.Context(), d, entryID)
+	if err != nil {
+		h.writeDictionaryError(w, "get history", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, changes)
+}
+
+func (h *Handler) exportEntries(w http.ResponseWriter, r *http.Request) {
+	d, ok := pathDictionary(w, r)
+	if !ok {
+		return
+	}
+
+	at, err := queryDate(r, "at")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", string(d)+".csv"))
+
+	err = h.dictionary.Export(r.Context(), d, at, w)
+	if err != nil {
+		log.Printf("failed to export %s: %v", d, err)
+	}
+}
+
+func (h *Handler) importEntries(w http.ResponseWriter, r *http.Request) {
+	d, ok := pathDictionary(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.dictionary.Import(r.Context(), d, r.Body, changedBy(r))
+	if err != nil {
+		h.writeDictionaryError(w, "import entries", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
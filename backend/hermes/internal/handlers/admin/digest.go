@@ -0,0 +1,137 @@
+package admin
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
+)
+
+func (h *Handler) listDigestRecipients(w http.ResponseWriter, r *http.Request) {
+	recipients, err := h.repositories.DigestsRepo.ListRecipients(r.Context())
+	if err != nil {
+		log.Printf("failed to list digest recipients: %v", err)
+		writeError(w, http.StatusInternalServerError, "failed to list digest recipients")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, recipients)
+}
+
+type requestBodyDigestRecipient struct {
+	ChatID *int   `json:"chat_id"`
+	Email  string `json:"email"`
+}
+
+// addDigestRecipient adds a chat known to hermes or an email.
+func (h *Handler) addDigestRecipient(w http.ResponseWriter, r *http.Request) {
+	var body requestBodyDigestRecipient
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	recipient := models.DigestRecipient{ChatID: body.ChatID, Email: strings.TrimSpace(body.Email)}
+	err = recipient.Validate()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if recipient.Email != "" {
+		address, err := mail.ParseAddress(recipient.Email)
+		if err != nil {
+			writeError(w, http.StatusUnprocessableEntity, "invalid email")
+			return
+		}
+		recipient.Email = address.Address
+	}
+
+	if recipient.ChatID != nil {
+		_, _, err = h.repositories.ChatsRepo.GetChatType(r.Context(), *recipient.ChatID)
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusUnprocessableEntity, "chat not found")
+			return
+		}
+		if err != nil {
+			log.Printf("failed to get chat: %v", err)
+			writeError(w, http.StatusInternalServerError, "failed to add digest recipient")
+			return
+		}
+	}
+
+	recipient, err = h.repositories.DigestsRepo.AddRecipient(r.Context(), recipient)
+	if err != nil {
+		log.Printf("failed to add digest recipient: %v", err)
+		writeError(w, http.StatusInternalServerError, "failed to add digest recipient")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, recipient)
+}
+
+func (h *Handler) deleteDigestRecipient(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(r, "id")
+	if !ok {
+		writeError(w, http.StatusNotFound, "digest recipient not found")
+		return
+	}
+
+	err := h.repositories.DigestsRepo.DeleteRecipient(r.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "digest recipient not found")
+		return
+	}
+	if err != nil {
+		log.Printf("failed to delete digest recipient: %v", err)
+		writeError(w, http.StatusInternalServerError, "failed to delete digest recipient")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getDigest previews the digest of the reports closed in (from, to], the
+// last day by default. Nothing is sent.
+func (h *Handler) getDigest(w http.ResponseWriter, r *http.Request) {
+	to := time.Now()
+	from := to.Add(-24 * time.Hour)
+
+	for _, param := range []struct {
+		name  string
+		value *time.Time
+	}{{"from", &from}, {"to", &to}} {
+		text := r.URL.Query().Get(param.name)
+		if text == "" {
+			continue
+		}
+
+		parsed, err := time.Parse(time.RFC3339, text)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid "+param.name+", expected RFC 3339")
+			return
+		}
+		*param.value = parsed
+	}
+
+	if !from.Before(to) {
+		writeError(w, http.StatusBadRequest, "from must be before to")
+		return
+	}
+
+	digest, err := h.digest.Build(r.Context(), from, to)
+	if err != nil {
+		log.Printf("failed to build digest: %v", err)
+		writeError(w, http.StatusInternalServerError, "failed to build digest")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, digest)
+}
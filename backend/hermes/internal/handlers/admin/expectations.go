@@ -0,0 +1,152 @@
+package admin
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
+)
+
+const seasonLayout = "2006-01-02"
+
+func (h *Handler) listExpectations(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.chatContextID(w, r)
+	if !ok {
+		return
+	}
+
+	expectations, err := h.repositories.ReportsRepo.ListExpectations(r.Context(), id)
+	if err != nil {
+		log.Printf("failed to list report expectations: %v", err)
+		writeError(w, http.StatusInternalServerError, "failed to list report expectations")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, expectations)
+}
+
+type requestBodyExpectation struct {
+	Division   string `json:"division"`
+	Operation  string `json:"operation"`
+	SeasonFrom string `json:"season_from"`
+	SeasonTo   string `json:"season_to"`
+}
+
+// addExpectation adds a division and an operation expected in the reports of
+// the chat context, both must be in the dictionaries in force. The season is
+// optional, its days are given as YYYY-MM-DD.
+func (h *Handler) addExpectation(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.chatContextID(w, r)
+	if !ok {
+		return
+	}
+
+	var body requestBodyExpectation
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	expectation := models.ReportExpectation{
+		ChatContextID: id,
+		Division:      strings.TrimSpace(body.Division),
+		Operation:     strings.TrimSpace(body.Operation),
+	}
+
+	expectation.SeasonFrom, err = parseSeasonDay(body.SeasonFrom)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid season_from, expected YYYY-MM-DD")
+		return
+	}
+
+	expectation.SeasonTo, err = parseSeasonDay(body.SeasonTo)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid season_to, expected YYYY-MM-DD")
+		return
+	}
+
+	if expectation.SeasonFrom != nil && expectation.SeasonTo != nil && expectation.SeasonFrom.After(*expectation.SeasonTo) {
+		writeError(w, http.StatusUnprocessableEntity, "season_from must not be after season_to")
+		return
+	}
+
+	divisions, err := h.repositories.InformationRepo.GetDivisions(r.Context(), time.Now())
+	if err != nil {
+		log.Printf("failed to get divisions: %v", err)
+		writeError(w, http.StatusInternalServerError, "failed to add report expectation")
+		return
+	}
+
+	if !slices.Contains(divisions, expectation.Division) {
+		writeError(w, http.StatusUnprocessableEntity, "unknown division "+expectation.Division)
+		return
+	}
+
+	operations, err := h.repositories.InformationRepo.GetOperations(r.Context(), time.Now())
+	if err != nil {
+		log.Printf("failed to get operations: %v", err)
+		writeError(w, http.StatusInternalServerError, "failed to add report expectation")
+		return
+	}
+
+	if !slices.Contains(operations, expectation.Operation) {
+		writeError(w, http.StatusUnprocessableEntity, "unknown operation "+expectation.Operation)
+		return
+	}
+
+	expectation, err = h.repositories.ReportsRepo.AddExpectation(r.Context(), expectation)
+	if err != nil {
+		log.Printf("failed to add report expectation: %v", err)
+		writeError(w, http.StatusInternalServerError, "failed to add report expectation")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, expectation)
+}
+
+// parseSeasonDay parses a day of the season, an empty value is not set.
+func parseSeasonDay(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	day, err := time.Parse(seasonLayout, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &day, nil
+}
+
+func (h *Handler) deleteExpectation(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.chatContextID(w, r)
+	if !ok {
+		return
+	}
+
+	expectationID, ok := pathID(r, "expectationID")
+	if !ok {
+		writeError(w, http.StatusNotFound, "report expectation not found")
+		return
+	}
+
+	err := h.repositories.ReportsRepo.DeleteExpectation(r.Context(), id, expectationID)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "report expectation not found")
+		return
+	}
+	if err != nil {
+		log.Printf("failed to delete report expectation: %v", err)
+		writeError(w, http.StatusInternalServerError, "failed to delete report expectation")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
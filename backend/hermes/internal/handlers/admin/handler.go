@@ -0,0 +1,121 @@
+package admin
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/managers/dictionary"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/managers/digest"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/managers/normalizer"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/repositories"
+)
+
+type Config struct {
+	Addr string `json:"ADMIN_ADDR" cfgDefault:":8080"`
+
+	// requests must carry "Authorization: Bearer <token>", the admin API is
+	// not served without the token
+	Token string `json:"ADMIN_TOKEN"`
+}
+
+var ErrNoToken = errors.New("admin token is not set")
+
+func NewHandler(
+	cfg Config,
+	repositories *repositories.Repositories,
+	normalizer *normalizer.Manager,
+	dictionary *dictionary.Manager,
+	digest *digest.Manager,
+) (http.Handler, error) {
+	if cfg.Token == "" {
+		return nil, ErrNoToken
+	}
+
+	h := &Handler{
+		repositories: repositories,
+		normalizer:   normalizer,
+		dictionary:   dictionary,
+		digest:       digest,
+	}
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /aliases", h.listAliases)
+	mux.HandleFunc("POST /aliases", h.addAlias)
+	mux.HandleFunc("POST /aliases/{dictionary}/{id}/approve", h.approveAlias)
+	mux.HandleFunc("DELETE /aliases/{dictionary}/{id}", h.deleteAlias)
+
+	mux.HandleFunc("GET /dictionaries/{dictionary}", h.listEntries)
+	mux.HandleFunc("POST /dictionaries/{dictionary}", h.createEntry)
+	mux.HandleFunc("GET /dictionaries/{dictionary}/history", h.getHistory)
+	mux.HandleFunc("GET /dictionaries/{dictionary}/export", h.exportEntries)
+	mux.HandleFunc("POST /dictionaries/{dictionary}/import", h.importEntries)
+	mux.HandleFunc("GET /dictionaries/{dictionary}/{id}", h.getEntry)
+	mux.HandleFunc("PUT /dictionaries/{dictionary}/{id}", h.updateEntry)
+	mux.HandleFunc("DELETE /dictionaries/{dictionary}/{id}", h.deleteEntry)
+
+	mux.HandleFunc("GET /reports/{id}", h.getReport)
+
+	mux.HandleFunc("GET /chat-contexts", h.listChatContexts)
+	mux.HandleFunc("GET /chat-contexts/{id}/settings", h.getChatContextSettings)
+	mux.HandleFunc("PUT /chat-contexts/{id}/settings", h.updateChatContextSettings)
+	mux.HandleFunc("GET /chat-contexts/{id}/reporters", h.listExpectedReporters)
+	mux.HandleFunc("POST /chat-contexts/{id}/reporters", h.addExpectedReporter)
+	mux.HandleFunc("DELETE /chat-contexts/{id}/reporters/{reporterID}", h.deleteExpectedReporter)
+	mux.HandleFunc("GET /chat-contexts/{id}/expectations", h.listExpectations)
+	mux.HandleFunc("POST /chat-contexts/{id}/expectations", h.addExpectation)
+	mux.HandleFunc("DELETE /chat-contexts/{id}/expectations/{expectationID}", h.deleteExpectation)
+
+	mux.HandleFunc("GET /workers", h.listWorkers)
+
+	mux.HandleFunc("GET /digest", h.getDigest)
+	mux.HandleFunc("GET /digest/recipients", h.listDigestRecipients)
+	mux.HandleFunc("POST /digest/recipients", h.addDigestRecipient)
+	mux.HandleFunc("DELETE /digest/recipients/{id}", h.deleteDigestRecipient)
+
+	return authorize(cfg.Token, mux), nil
+}
+
+// Handler serves the admin API used to maintain reference data and settings.
+type Handler struct {
+	repositories *repositories.Repositories
+	normalizer   *normalizer.Manager
+	dictionary   *dictionary.Manager
+	digest       *digest.Manager
+}
+
+func authorize(token string, next http.Handler) http.Handler {
+	expected := []byte("Bearer " + token)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			writeError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	err := json.NewEncoder(w).Encode(body)
+	if err != nil {
+		log.Printf("failed to write response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+func pathID(r *http.Request, name string) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue(name))
+	return id, err == nil && id > 0
+}
@@ -0,0 +1,124 @@
+package admin
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
+)
+
+func (h *Handler) listWorkers(w http.ResponseWriter, r *http.Request) {
+	workers, err := h.repositories.WorkersRepo.ListContacts(r.Context())
+	if err != nil {
+		log.Printf("failed to list workers: %v", err)
+		writeError(w, http.StatusInternalServerError, "failed to list workers")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, workers)
+}
+
+func (h *Handler) listExpectedReporters(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.chatContextID(w, r)
+	if !ok {
+		return
+	}
+
+	reporters, err := h.repositories.RemindersRepo.ListExpectedReporters(r.Context(), id)
+	if err != nil {
+		log.Printf("failed to list expected reporters: %v", err)
+		writeError(w, http.StatusInternalServerError, "failed to list expected reporters")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, reporters)
+}
+
+type requestBodyExpectedReporter struct {
+	WorkerID int    `json:"worker_id"`
+	Division string `json:"division"`
+}
+
+// addExpectedReporter adds a worker known to hermes, the division must be in
+// the dictionary in force.
+func (h *Handler) addExpectedReporter(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.chatContextID(w, r)
+	if !ok {
+		return
+	}
+
+	var body requestBodyExpectedReporter
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	contact, err := h.repositories.WorkersRepo.GetContact(r.Context(), body.WorkerID)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusUnprocessableEntity, "worker not found")
+		return
+	}
+	if err != nil {
+		log.Printf("failed to get worker: %v", err)
+		writeError(w, http.StatusInternalServerError, "failed to add expected reporter")
+		return
+	}
+
+	reporter := models.ExpectedReporter{ChatContextID: id, Division: strings.TrimSpace(body.Division), Contact: contact}
+
+	if reporter.Division != "" {
+		divisions, err := h.repositories.InformationRepo.GetDivisions(r.Context(), time.Now())
+		if err != nil {
+			log.Printf("failed to get divisions: %v", err)
+			writeError(w, http.StatusInternalServerError, "failed to add expected reporter")
+			return
+		}
+
+		if !slices.Contains(divisions, reporter.Division) {
+			writeError(w, http.StatusUnprocessableEntity, "unknown division "+reporter.Division)
+			return
+		}
+	}
+
+	reporter, err = h.repositories.RemindersRepo.AddExpectedReporter(r.Context(), reporter)
+	if err != nil {
+		log.Printf("failed to add expected reporter: %v", err)
+		writeError(w, http.StatusInternalServerError, "failed to add expected reporter")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, reporter)
+}
+
+func (h *Handler) deleteExpectedReporter(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.chatContextID(w, r)
+	if !ok {
+		return
+	}
+
+	reporterID, ok := pathID(r, "reporterID")
+	if !ok {
+		writeError(w, http.StatusNotFound, "expected reporter not found")
+		return
+	}
+
+	err := h.repositories.RemindersRepo.DeleteExpectedReporter(r.Context(), id, reporterID)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "expected reporter not found")
+		return
+	}
+	if err != nil {
+		log.Printf("failed to delete expected reporter: %v", err)
+		writeError(w, http.StatusInternalServerError, "failed to delete expected reporter")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,30 @@
+package admin
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+)
+
+// getReport returns the report with its messages, media, senders and lines.
+func (h *Handler) getReport(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(r, "id")
+	if !ok {
+		writeError(w, http.StatusNotFound, "report not found")
+		return
+	}
+
+	report, err := h.repositories.ReportsRepo.GetReport(r.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "report not found")
+		return
+	}
+	if err != nil {
+		log.Printf("failed to get report: %v", err)
+		writeError(w, http.StatusInternalServerError, "failed to get report")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
@@ -3,7 +3,6 @@ package telegram
 import (
 	"context"
 	"fmt"
-	"io"
 	"net/http"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
@@ -125,14 +124,14 @@ func (h *Handler) handleImageMessage(ctx context.Context, fileID string, textMes
 	}
 	defer resp.Body.Close()
 
-	data, err := io.ReadAll(resp.Body)
+	media, err := models.SpoolMedia(resp.Body)
 	if err != nil {
 		return fmt.Errorf("Ошибка чтения данных: %v", err)
 	}
 
 	h.recognizerManager.AsyncProcessImageMessage(models.ImageMessage{
 		TextMessage: textMessage,
-		Image:       data,
+		Image:       media,
 	})
 
 	return nil
@@ -150,14 +149,14 @@ func (h *Handler) handleAudioMessage(ctx context.Context, fileID string, textMes
 	}
 	defer resp.Body.Close()
 
-	data, err := io.ReadAll(resp.Body)
+	media, err := models.SpoolMedia(resp.Body)
 	if err != nil {
 		return fmt.Errorf("Ошибка чтения данных: %v", err)
 	}
 
 	h.recognizerManager.AsyncProcessAudioMessage(models.AudioMessage{
 		TextMessage: textMessage,
-		Audio:       data,
+		Audio:       media,
 	})
 
 	return nil
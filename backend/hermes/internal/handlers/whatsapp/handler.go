@@ -4,8 +4,8 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"os"
 
-	"github.com/gabriel-vasile/mimetype"
 	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/clients"
 	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/managers/recognizer"
 	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
@@ -73,6 +73,8 @@ func (h *Handler) Handle(evt interface{}) {
 			fmt.Println("URL:", msg.ImageMessage.GetURL())
 
 			go h.handleImageMessage(h.shutdownCtx, msg.GetImageMessage(), textMessage)
+		} else if msg.StickerMessage != nil {
+			go h.handleImageMessage(h.shutdownCtx, msg.GetStickerMessage(), textMessage)
 		} else if msg.AudioMessage != nil {
 			fmt.Println("Тип: аудио")
 			fmt.Println("URL:", msg.AudioMessage.GetURL())
@@ -83,25 +85,28 @@ func (h *Handler) Handle(evt interface{}) {
 }
 
 func (h *Handler) handleImageMessage(ctx context.Context, msg whatsmeow.DownloadableMessage, textMessage models.TextMessage) error {
-	data, err := h.clients.Whatsapp.Download(msg)
+	image, err := models.DownloadMedia(func(file *os.File) error {
+		return h.clients.Whatsapp.DownloadToFile(msg, file)
+	})
 	if err != nil {
 		log.Printf("failed to download image: %v", err)
 		return err
 	}
 
-	mime := mimetype.Detect(data)
-	fmt.Printf("Detected MIME type: %s\n", mime.String())
+	fmt.Printf("Detected MIME type: %s\n", image.MIME)
 
 	h.recognizerManager.AsyncProcessImageMessage(models.ImageMessage{
 		TextMessage: textMessage,
-		Image:       data,
+		Image:       image,
 	})
 
 	return nil
 }
 
 func (h *Handler) handleAudioMessage(ctx context.Context, msg whatsmeow.DownloadableMessage, textMessage models.TextMessage) error {
-	audioData, err := h.clients.Whatsapp.Download(msg)
+	audio, err := models.DownloadMedia(func(file *os.File) error {
+		return h.clients.Whatsapp.DownloadToFile(msg, file)
+	})
 	if err != nil {
 		log.Printf("failed to download audio: %v", err)
 		return err
@@ -109,7 +114,7 @@ func (h *Handler) handleAudioMessage(ctx context.Context, msg whatsmeow.Download
 
 	h.recognizerManager.AsyncProcessAudioMessage(models.AudioMessage{
 		TextMessage: textMessage,
-		Audio:       audioData,
+		Audio:       audio,
 	})
 
 	return nil
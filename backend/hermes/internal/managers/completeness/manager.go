@@ -0,0 +1,110 @@
+package completeness
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/repositories"
+)
+
+func NewManager(repositories *repositories.Repositories) *Manager {
+	return &Manager{
+		repositories: repositories,
+	}
+}
+
+// Manager checks that reports cover the divisions and the operations expected
+// in the chat contexts.
+type Manager struct {
+	repositories *repositories.Repositories
+}
+
+// Check calculates the completeness of the report against the expectations
+// active on the report day and saves it.
+func (m *Manager) Check(ctx context.Context, reportID int) (models.Completeness, error) {
+	details, err := m.repositories.ReportsRepo.GetReport(ctx, reportID)
+	if err != nil {
+		return models.Completeness{}, fmt.Errorf("failed to get report: %w", err)
+	}
+
+	expectations, err := m.repositories.ReportsRepo.ListExpectations(ctx, details.ChatContextID)
+	if err != nil {
+		return models.Completeness{}, fmt.Errorf("failed to list expectations: %w", err)
+	}
+
+	day := reportDay(details.Report)
+
+	active := make([]models.ReportExpectation, 0, len(expectations))
+	for _, expectation := range expectations {
+		if expectation.IsActive(day) {
+			active = append(active, expectation)
+		}
+	}
+
+	completeness := Calculate(active, details.Lines)
+	completeness.ReportID = reportID
+
+	err = m.repositories.ReportsRepo.SaveCompleteness(ctx, completeness)
+	if err != nil {
+		return models.Completeness{}, fmt.Errorf("failed to save completeness: %w", err)
+	}
+
+	return completeness, nil
+}
+
+// Calculate counts the expectations covered by the lines. An expectation is
+// covered by a line of the same division and operation, case insensitive.
+func Calculate(expectations []models.ReportExpectation, lines []models.ReportLine) models.Completeness {
+	type key struct {
+		division  string
+		operation string
+	}
+
+	reported := make(map[key]bool)
+	for _, line := range lines {
+		reported[key{division: strings.ToLower(line.Division), operation: strings.ToLower(line.Operation)}] = true
+	}
+
+	completeness := models.Completeness{
+		Missing: make([]models.ExpectedItem, 0),
+	}
+
+	seen := make(map[key]bool)
+	for _, expectation := range expectations {
+		k := key{division: strings.ToLower(expectation.Division), operation: strings.ToLower(expectation.Operation)}
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+
+		completeness.Expected++
+		if reported[k] {
+			completeness.Covered++
+			continue
+		}
+
+		completeness.Missing = append(completeness.Missing, models.ExpectedItem{
+			Division:  expectation.Division,
+			Operation: expectation.Operation,
+		})
+	}
+
+	return completeness
+}
+
+// reportDay is the moment the report was finished or is to be closed, in the
+// location of its chat context.
+func reportDay(report models.Report) time.Time {
+	end := time.Now()
+	switch {
+	case report.FinishedAt != nil:
+		end = *report.FinishedAt
+	case report.ClosesAt != nil:
+		end = *report.ClosesAt
+	}
+
+	return end.In(report.Location())
+}
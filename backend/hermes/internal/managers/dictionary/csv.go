@@ -0,0 +1,243 @@
+package dictionary
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
+)
+
+// columns are the CSV columns of the dictionary, "name" is "division" for units.
+var columns = map[models.Dictionary][]string{
+	models.DictionaryCultures:   {"id", "name", "effective_from"},
+	models.DictionaryOperations: {"id", "name", "note", "expected_unit", "effective_from"},
+	models.DictionaryUnits:      {"id", "division", "pu", "department", "area", "effective_from"},
+}
+
+// ImportResult counts what the import did with the rows.
+type ImportResult struct {
+	Created   int `json:"created"`
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+}
+
+// Export writes the entries in force at the date (current ones if nil) as CSV.
+func (m *Manager) Export(ctx context.Context, dictionary models.Dictionary, at *time.Time, w io.Writer) error {
+	entries, err := m.List(ctx, dictionary, at, false)
+	if err != nil {
+		return fmt.Errorf("failed to list entries: %w", err)
+	}
+
+	writer := csv.NewWriter(w)
+
+	header := columns[dictionary]
+	err = writer.Write(header)
+	if err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+
+	for _, entry := range entries {
+		record := make([]string, 0, len(header))
+		for _, column := range header {
+			record = append(record, entryField(entry, column))
+		}
+
+		err = writer.Write(record)
+		if err != nil {
+			return fmt.Errorf("failed to write entry: %w", err)
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// Import creates and updates entries from CSV with a header row. Rows with
+// an id update that entry; other rows update the current entry with the same
+// name or create a new one. All rows are validated before anything is written.
+func (m *Manager) Import(ctx context.Context, dictionary models.Dictionary, r io.Reader, changedBy string) (ImportResult, error) {
+	if !dictionary.IsValid() {
+		return ImportResult{}, fmt.Errorf("%w: unknown dictionary %q", ErrInvalidEntry, dictionary)
+	}
+
+	type row struct {
+		id    int
+		entry models.DictionaryEntry
+	}
+
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return ImportResult{}, fmt.Errorf("%w: failed to read header: %v", ErrInvalidEntry, err)
+	}
+
+	index := make(map[string]int, len(header))
+	for i, column := range header {
+		index[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+
+	var rows []row
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return ImportResult{}, fmt.Errorf("%w: line %d: %v", ErrInvalidEntry, line, err)
+		}
+
+		field := func(column string) string {
+			i, ok := index[column]
+			if !ok || i >= len(record) {
+				return ""
+			}
+
+			return strings.TrimSpace(record[i])
+		}
+
+		var current row
+		if id := field("id"); id != "" {
+			current.id, err = strconv.Atoi(id)
+			if err != nil {
+				return ImportResult{}, fmt.Errorf("%w: line %d: invalid id %q", ErrInvalidEntry, line, id)
+			}
+		}
+
+		current.entry = models.DictionaryEntry{
+			Dictionary:   dictionary,
+			Name:         field(columns[dictionary][1]),
+			Note:         field("note"),
+			PU:           field("pu"),
+			Department:   field("department"),
+			ExpectedUnit: field("expected_unit"),
+		}
+
+		if area := field("area"); area != "" {
+			value, err := strconv.ParseFloat(strings.ReplaceAll(area, ",", "."), 64)
+			if err != nil {
+				return ImportResult{}, fmt.Errorf("%w: line %d: invalid area %q", ErrInvalidEntry, line, area)
+			}
+
+			current.entry.Area = &value
+		}
+
+		if date := field("effective_from"); date != "" {
+			current.entry.EffectiveFrom, err = ParseDate(date)
+			if err != nil {
+				return ImportResult{}, fmt.Errorf("line %d: %w", line, err)
+			}
+		}
+
+		current.entry, err = prepare(current.entry)
+		if err != nil {
+			return ImportResult{}, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		err = m.checkExpectedUnit(ctx, current.entry)
+		if err != nil {
+			return ImportResult{}, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		rows = append(rows, current)
+	}
+
+	existing, err := m.List(ctx, dictionary, nil, false)
+	if err != nil {
+		return ImportResult{}, fmt.Errorf("failed to list entries: %w", err)
+	}
+
+	byID := make(map[int]models.DictionaryEntry, len(existing))
+	byName := make(map[string]models.DictionaryEntry, len(existing))
+	for _, entry := range existing {
+		byID[entry.ID] = entry
+		byName[entryKey(entry)] = entry
+	}
+
+	var result ImportResult
+	for _, row := range rows {
+		current, ok := byID[row.id]
+		if !ok {
+			current, ok = byName[entryKey(row.entry)]
+		}
+
+		switch {
+		case !ok:
+			_, err = m.repositories.InformationRepo.CreateEntry(ctx, row.entry, changedBy)
+			if err != nil {
+				return result, fmt.Errorf("failed to create %q: %w", row.entry.Name, err)
+			}
+			result.Created++
+		case sameEntry(current, row.entry):
+			result.Unchanged++
+		default:
+			_, err = m.repositories.InformationRepo.UpdateEntry(ctx, current.ID, row.entry, changedBy)
+			if err != nil {
+				return result, fmt.Errorf("failed to update %q: %w", row.entry.Name, err)
+			}
+			result.Updated++
+		}
+	}
+
+	return result, nil
+}
+
+func entryField(entry models.DictionaryEntry, column string) string {
+	switch column {
+	case "id":
+		return strconv.Itoa(entry.ID)
+	case "name", "division":
+		return entry.Name
+	case "note":
+		return entry.Note
+	case "pu":
+		return entry.PU
+	case "department":
+		return entry.Department
+	case "expected_unit":
+		return entry.ExpectedUnit
+	case "area":
+		if entry.Area == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*entry.Area, 'f', -1, 64)
+	case "effective_from":
+		return entry.EffectiveFrom.Format(dateLayout)
+	}
+
+	return ""
+}
+
+// entryKey identifies an entry without id. A division has several units,
+// so units are identified by all their fields.
+func entryKey(entry models.DictionaryEntry) string {
+	key := strings.ToLower(entry.Name)
+	if entry.Dictionary == models.DictionaryUnits {
+		key += "|" + strings.ToLower(entry.PU) + "|" + strings.ToLower(entry.Department)
+	}
+
+	return key
+}
+
+// sameEntry ignores the effective date of rows that do not change anything,
+// so exported files can be imported back as is.
+func sameEntry(a, b models.DictionaryEntry) bool {
+	return a.Name == b.Name && a.Note == b.Note && a.PU == b.PU && a.Department == b.Department &&
+		sameArea(a.Area, b.Area) && a.ExpectedUnit == b.ExpectedUnit
+}
+
+func sameArea(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
@@ -0,0 +1,150 @@
+package dictionary
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/repositories"
+	"github.com/lild1tz/llm_coding_challenge/backend/libs/go/loctime"
+)
+
+const dateLayout = "2006-01-02"
+
+var ErrInvalidEntry = errors.New("invalid dictionary entry")
+
+func NewManager(repositories *repositories.Repositories) *Manager {
+	return &Manager{
+		repositories: repositories,
+	}
+}
+
+// Manager maintains the reference data: cultures, operations and units.
+type Manager struct {
+	repositories *repositories.Repositories
+}
+
+func (m *Manager) List(ctx context.Context, dictionary models.Dictionary, at *time.Time, withDeleted bool) ([]models.DictionaryEntry, error) {
+	return m.repositories.InformationRepo.ListEntries(ctx, dictionary, at, withDeleted)
+}
+
+func (m *Manager) Get(ctx context.Context, dictionary models.Dictionary, entryID int) (models.DictionaryEntry, error) {
+	return m.repositories.InformationRepo.GetEntry(ctx, dictionary, entryID)
+}
+
+func (m *Manager) Create(ctx context.Context, entry models.DictionaryEntry, changedBy string) (models.DictionaryEntry, error) {
+	entry, err := prepare(entry)
+	if err != nil {
+		return models.DictionaryEntry{}, err
+	}
+
+	err = m.checkExpectedUnit(ctx, entry)
+	if err != nil {
+		return models.DictionaryEntry{}, err
+	}
+
+	return m.repositories.InformationRepo.CreateEntry(ctx, entry, changedBy)
+}
+
+func (m *Manager) Update(ctx context.Context, entryID int, entry models.DictionaryEntry, changedBy string) (models.DictionaryEntry, error) {
+	entry, err := prepare(entry)
+	if err != nil {
+		return models.DictionaryEntry{}, err
+	}
+
+	err = m.checkExpectedUnit(ctx, entry)
+	if err != nil {
+		return models.DictionaryEntry{}, err
+	}
+
+	return m.repositories.InformationRepo.UpdateEntry(ctx, entryID, entry, changedBy)
+}
+
+// Delete marks the entry deleted from the moment, today if at is nil.
+func (m *Manager) Delete(ctx context.Context, dictionary models.Dictionary, entryID int, at *time.Time, changedBy string) error {
+	deletedAt := today()
+	if at != nil {
+		deletedAt = *at
+	}
+
+	return m.repositories.InformationRepo.DeleteEntry(ctx, dictionary, entryID, deletedAt, changedBy)
+}
+
+func (m *Manager) History(ctx context.Context, dictionary models.Dictionary, entryID *int) ([]models.DictionaryChange, error) {
+	return m.repositories.InformationRepo.GetHistory(ctx, dictionary, entryID)
+}
+
+// prepare trims the fields, checks the ones required by the dictionary
+// and makes the entry effective from today if the date is not set.
+func prepare(entry models.DictionaryEntry) (models.DictionaryEntry, error) {
+	if !entry.Dictionary.IsValid() {
+		return entry, fmt.Errorf("%w: unknown dictionary %q", ErrInvalidEntry, entry.Dictionary)
+	}
+
+	entry.Name = strings.TrimSpace(entry.Name)
+	entry.Note = strings.TrimSpace(entry.Note)
+	entry.PU = strings.TrimSpace(entry.PU)
+	entry.Department = strings.TrimSpace(entry.Department)
+	entry.ExpectedUnit = strings.TrimSpace(entry.ExpectedUnit)
+
+	if entry.Name == "" {
+		return entry, fmt.Errorf("%w: name is required", ErrInvalidEntry)
+	}
+
+	// a unit without departments, like the seeded АОР, has an empty one
+	if entry.Dictionary == models.DictionaryUnits && entry.PU == "" {
+		return entry, fmt.Errorf("%w: pu is required for units", ErrInvalidEntry)
+	}
+
+	if entry.Area != nil && (entry.Dictionary != models.DictionaryUnits || *entry.Area < 0) {
+		return entry, fmt.Errorf("%w: area must be a positive number of hectares for units", ErrInvalidEntry)
+	}
+
+	if entry.ExpectedUnit != "" && entry.Dictionary != models.DictionaryOperations {
+		return entry, fmt.Errorf("%w: expected unit is set for operations only", ErrInvalidEntry)
+	}
+
+	if entry.EffectiveFrom.IsZero() {
+		entry.EffectiveFrom = today()
+	}
+
+	return entry, nil
+}
+
+// checkExpectedUnit makes sure the unit is one of hermes_data.measure_units.
+func (m *Manager) checkExpectedUnit(ctx context.Context, entry models.DictionaryEntry) error {
+	if entry.ExpectedUnit == "" {
+		return nil
+	}
+
+	units, err := m.repositories.InformationRepo.GetMeasureUnits(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, unit := range units {
+		if unit.Code == entry.ExpectedUnit {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: unknown unit %q", ErrInvalidEntry, entry.ExpectedUnit)
+}
+
+func today() time.Time {
+	now := loctime.Transfer(time.Now())
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// ParseDate parses dates in the format used by the API and CSV files.
+func ParseDate(value string) (time.Time, error) {
+	date, err := time.Parse(dateLayout, strings.TrimSpace(value))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: date %q must be YYYY-MM-DD", ErrInvalidEntry, value)
+	}
+
+	return date, nil
+}
@@ -0,0 +1,305 @@
+package digest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/clients"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/clients/smtp"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/managers/export"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/managers/summary"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/repositories"
+	"github.com/lild1tz/llm_coding_challenge/backend/libs/go/loctime"
+)
+
+type Config struct {
+	// Schedule are the cutoffs of the digest separated by ";", see models.Schedule,
+	// they are in the default time zone
+	Schedule string `json:"DIGEST_SCHEDULE" cfgDefault:"07:00"`
+
+	// the digest is sent Delay seconds after the cutoff, so that the reports
+	// closed by the same cutoff are finished
+	Delay int `json:"DIGEST_DELAY_SECONDS" cfgDefault:"120"`
+
+	// due digests are checked every Interval seconds
+	Interval int `json:"DIGEST_INTERVAL_SECONDS" cfgDefault:"60"`
+
+	// the digest waits for the reports of the period pending review at most
+	// ReviewTimeout seconds after it is due, then it is sent without them
+	ReviewTimeout int `json:"DIGEST_REVIEW_TIMEOUT_SECONDS" cfgDefault:"10800"`
+
+	// a digest claimed but not delivered in ClaimTimeout seconds, e.g. by an
+	// instance that crashed, is sent by another instance
+	ClaimTimeout int `json:"DIGEST_CLAIM_TIMEOUT_SECONDS" cfgDefault:"600"`
+}
+
+func NewManager(cfg Config, clients *clients.Clients, repositories *repositories.Repositories) (*Manager, error) {
+	schedule, err := models.ParseSchedule(strings.Split(cfg.Schedule, ";"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse digest schedule: %w", err)
+	}
+
+	return &Manager{
+		clients:      clients,
+		repositories: repositories,
+		schedule:     schedule,
+		delay:        time.Duration(cfg.Delay) * time.Second,
+		interval:     time.Duration(cfg.Interval) * time.Second,
+
+		reviewTimeout: time.Duration(cfg.ReviewTimeout) * time.Second,
+		claimTimeout:  time.Duration(cfg.ClaimTimeout) * time.Second,
+	}, nil
+}
+
+// Manager sends the digest of the reports of all chat contexts to the
+// digest recipients after every cutoff of the schedule. Among several
+// instances the digest of a period is sent by the one that claims it first,
+// a digest that failed to be delivered is tried again.
+type Manager struct {
+	clients      *clients.Clients
+	repositories *repositories.Repositories
+
+	schedule models.Schedule
+	delay    time.Duration
+	interval time.Duration
+
+	reviewTimeout time.Duration
+	claimTimeout  time.Duration
+
+	// last is the end of the last period handled by the instance
+	last time.Time
+}
+
+// Start sends the digest of the last period if it was missed while hermes
+// was down, then sends the digests in the background until the context is done.
+func (m *Manager) Start(ctx context.Context) {
+	m.sendDueDigest(ctx)
+
+	if m.interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.sendDueDigest(ctx)
+			}
+		}
+	}()
+}
+
+func (m *Manager) sendDueDigest(ctx context.Context) {
+	now := time.Now()
+
+	from, to, ok := m.lastPeriod(now.Add(-m.delay))
+	if !ok || !to.After(m.last) {
+		return
+	}
+
+	if now.Before(to.Add(m.delay + m.reviewTimeout)) {
+		pending, err := m.hasPendingReview(ctx, from, to)
+		if err != nil {
+			log.Printf("failed to check reports pending review: %v", err)
+			return
+		}
+		if pending {
+			return
+		}
+	}
+
+	done, err := m.send(ctx, from, to)
+	if err != nil {
+		log.Printf("failed to send digest: %v", err)
+	}
+	if done {
+		m.last = to
+	}
+}
+
+// lastPeriod returns the period ended by the last cutoff at or before t.
+func (m *Manager) lastPeriod(t time.Time) (time.Time, time.Time, bool) {
+	to, ok := m.schedule.Prev(t.In(loctime.Default()))
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+
+	from, ok := m.schedule.Prev(to.Add(-time.Nanosecond))
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+
+	return from, to, true
+}
+
+// send delivers the digest of the period unless another instance has sent
+// or is sending it. It is done when the digest is sent, the period is tried
+// again otherwise. A digest that reached some of the recipients is not sent
+// again to spare them a copy.
+func (m *Manager) send(ctx context.Context, from, to time.Time) (bool, error) {
+	recipients, err := m.repositories.DigestsRepo.ListRecipients(ctx)
+	if err != nil {
+		return false, err
+	}
+	if len(recipients) == 0 {
+		return true, nil
+	}
+
+	digest, err := m.Build(ctx, from, to)
+	if err != nil {
+		return false, err
+	}
+
+	claimed, err := m.repositories.DigestsRepo.ClaimDigest(ctx, from, to, time.Now().Add(-m.claimTimeout))
+	if err != nil {
+		return false, err
+	}
+	if !claimed {
+		return m.repositories.DigestsRepo.IsDigestSent(ctx, to)
+	}
+
+	log.Printf("sending digest of %s - %s: %d reports", from, to, digest.Reports)
+
+	delivered, err := m.Deliver(ctx, digest, recipients)
+	if delivered == 0 {
+		releaseErr := m.repositories.DigestsRepo.ReleaseDigest(ctx, to)
+		return false, errors.Join(err, releaseErr)
+	}
+
+	markErr := m.repositories.DigestsRepo.MarkDigestSent(ctx, to)
+
+	return markErr == nil, errors.Join(err, markErr)
+}
+
+// hasPendingReview reports whether a report closed in (from, to] waits for the review.
+func (m *Manager) hasPendingReview(ctx context.Context, from, to time.Time) (bool, error) {
+	reports, err := m.repositories.ReportsRepo.GetClosedReports(ctx, from, to)
+	if err != nil {
+		return false, err
+	}
+
+	for _, report := range reports {
+		if report.Status == models.StatusPendingReview {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// Build totals the approved reports closed in (from, to] with the deltas
+// from the period of the same length before it.
+func (m *Manager) Build(ctx context.Context, from, to time.Time) (models.Digest, error) {
+	reports, unreviewed, err := m.getReports(ctx, from, to)
+	if err != nil {
+		return models.Digest{}, err
+	}
+
+	previous, _, err := m.getReports(ctx, from.Add(-to.Sub(from)), from)
+	if err != nil {
+		return models.Digest{}, err
+	}
+
+	digest := summary.Digest(from, to, reports, previous)
+	digest.Unreviewed = unreviewed
+
+	return digest, nil
+}
+
+// getReports returns the approved reports closed in (from, to] and the
+// number of the others.
+func (m *Manager) getReports(ctx context.Context, from, to time.Time) ([]models.ReportDetails, int, error) {
+	reports, err := m.repositories.ReportsRepo.GetClosedReports(ctx, from, to)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	unreviewed := 0
+	details := make([]models.ReportDetails, 0, len(reports))
+	for _, report := range reports {
+		if report.Status != models.StatusApproved {
+			unreviewed++
+			continue
+		}
+
+		reportDetails, err := m.repositories.ReportsRepo.GetReport(ctx, report.ID)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		details = append(details, reportDetails)
+	}
+
+	return details, unreviewed, nil
+}
+
+// Deliver sends the digest with its spreadsheet to the chats and the emails
+// of the recipients and returns how many got it, a failed recipient does not
+// stop the others.
+func (m *Manager) Deliver(ctx context.Context, digest models.Digest, recipients []models.DigestRecipient) (int, error) {
+	file, err := export.DigestXLSX(digest)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	var errs []error
+
+	for _, recipient := range recipients {
+		if recipient.ChatID != nil {
+			err = m.sendToChat(ctx, *recipient.ChatID, digest, file)
+		} else {
+			err = m.clients.SMTP.SendMail(ctx, []string{recipient.Email}, summary.DigestTitle(digest), summary.FormatDigestText(digest),
+				[]smtp.Attachment{{Name: file.Name, MimeType: file.MimeType, Data: file.Data}})
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to send digest to recipient %d: %w", recipient.ID, err))
+			continue
+		}
+
+		delivered++
+	}
+
+	return delivered, errors.Join(errs...)
+}
+
+func (m *Manager) sendToChat(ctx context.Context, chatID int, digest models.Digest, file export.File) error {
+	chatType, chatName, err := m.repositories.ChatsRepo.GetChatType(ctx, chatID)
+	if err != nil {
+		return err
+	}
+
+	switch chatType {
+	case "whatsapp":
+		listenerID, err := m.repositories.ChatsRepo.GetListenerID(ctx, chatID)
+		if err != nil {
+			return fmt.Errorf("failed to get listener ID: %w", err)
+		}
+
+		err = m.clients.Whatsapp.SendReport(ctx, chatName, listenerID, summary.FormatDigestWhatsApp(digest))
+		if err != nil {
+			return err
+		}
+
+		return m.clients.Whatsapp.SendDocument(ctx, chatName, file.Name, file.MimeType, file.Data, "")
+	case "telegram":
+		err = m.clients.Telegram.SendReport(ctx, chatName, summary.FormatDigestTelegram(digest))
+		if err != nil {
+			return err
+		}
+
+		return m.clients.Telegram.SendDocument(ctx, chatName, file.Name, file.Data, "")
+	}
+
+	return fmt.Errorf("unknown chat type %q", chatType)
+}
@@ -0,0 +1,49 @@
+package export
+
+import (
+	"fmt"
+
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
+)
+
+var digestHeaders = []string{"Операция", "Культура", "Подразделение",
+	"За день, га", "Изменение за день, га", "С начала операции, га",
+	"Вал за день", "Изменение вала за день", "Вал с начала", "Единица вала"}
+
+// DigestXLSX renders the rows of the digest as a workbook named after the end
+// of the period in its time zone.
+func DigestXLSX(digest models.Digest) (File, error) {
+	s := sheet{
+		name:    "Сводка",
+		headers: digestHeaders,
+		widths:  []float64{18, 18, 18, 14, 14, 14, 14, 14, 14, 10},
+		wrapped: -1,
+		rows:    make([][]cell, 0, len(digest.Rows)),
+	}
+
+	for _, row := range digest.Rows {
+		s.rows = append(s.rows, []cell{
+			textCell(row.Operation),
+			textCell(row.Culture),
+			textCell(row.Division),
+			numberCell(row.PerDay),
+			numberCell(row.PerDayDelta),
+			numberCell(row.PerOperation),
+			numberCell(row.ValDay),
+			numberCell(row.ValDayDelta),
+			numberCell(row.ValBeginning),
+			textCell(row.ValUnit),
+		})
+	}
+
+	data, err := s.render()
+	if err != nil {
+		return File{}, fmt.Errorf("failed to render digest: %w", err)
+	}
+
+	return File{
+		Name:     digest.To.Format("15ч02.01.2006") + "_AgroScientists_сводка.xlsx",
+		MimeType: XLSXMimeType,
+		Data:     data,
+	}, nil
+}
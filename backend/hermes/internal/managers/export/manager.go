@@ -0,0 +1,48 @@
+package export
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/repositories"
+)
+
+func NewManager(repositories *repositories.Repositories) *Manager {
+	return &Manager{
+		repositories: repositories,
+	}
+}
+
+// Manager renders reports as files for recipients without access to the
+// Drive tables.
+type Manager struct {
+	repositories *repositories.Repositories
+}
+
+// File is a rendered report.
+type File struct {
+	Name     string
+	MimeType string
+	Data     []byte
+}
+
+// ExportXLSX renders the lines of the report as an XLSX table named after the
+// Drive table of the report.
+func (m *Manager) ExportXLSX(ctx context.Context, reportID int, chatContextName string) (File, error) {
+	details, err := m.repositories.ReportsRepo.GetReport(ctx, reportID)
+	if err != nil {
+		return File{}, fmt.Errorf("failed to get report: %w", err)
+	}
+
+	data, err := ReportXLSX(details)
+	if err != nil {
+		return File{}, fmt.Errorf("failed to render report: %w", err)
+	}
+
+	return File{
+		Name:     models.GetXlsxName(details.StartedAt, details.Location(), chatContextName),
+		MimeType: XLSXMimeType,
+		Data:     data,
+	}, nil
+}
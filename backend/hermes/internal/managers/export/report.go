@@ -0,0 +1,118 @@
+package export
+
+import (
+	"strings"
+
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
+)
+
+// reportHeaders are the columns of the Drive table followed by the notes,
+// the notes column holds what the Drive table keeps in cell notes.
+var reportHeaders = []string{"Дата", "Подразделение", "ПУ", "Отделение", "Операция", "Культура",
+	"За день, га", "С начала операции, га", "Вал за день", "Вал с начала", "Единица вала", "Примечания"}
+
+const (
+	columnDate = iota
+	columnDivision
+	columnPU
+	columnDepartment
+	columnOperation
+	columnCulture
+	columnPerDay
+	columnPerOperation
+	columnValDay
+	columnValBeginning
+	columnValUnit
+	columnNotes
+)
+
+// flagColumns are the columns of the flagged fields
+var flagColumns = map[string]int{
+	"date":          columnDate,
+	"per_day":       columnPerDay,
+	"per_operation": columnPerOperation,
+	"val_day":       columnValDay,
+	"val_beginning": columnValBeginning,
+}
+
+// ReportXLSX renders the lines of the report as a workbook. Cells are
+// highlighted as in the Drive table: unmatched values in yellow, flagged
+// values in the color of their first flag.
+func ReportXLSX(details models.ReportDetails) ([]byte, error) {
+	s := sheet{
+		name:    "Отчёт",
+		headers: reportHeaders,
+		widths:  []float64{22, 18, 18, 18, 18, 18, 14, 14, 14, 14, 10, 60},
+		wrapped: columnNotes,
+		rows:    make([][]cell, 0, len(details.Lines)),
+	}
+
+	for _, reportLine := range details.Lines {
+		s.rows = append(s.rows, lineCells(reportLine.Line))
+	}
+
+	return s.render()
+}
+
+func lineCells(line models.Line) []cell {
+	cells := []cell{
+		columnDate:         textCell(line.Date.String()),
+		columnDivision:     textCell(line.Division),
+		columnPU:           textCell(line.PU),
+		columnDepartment:   textCell(line.Department),
+		columnOperation:    textCell(line.Operation),
+		columnCulture:      textCell(line.Culture),
+		columnPerDay:       numberCell(line.PerDay),
+		columnPerOperation: numberCell(line.PerOperation),
+		columnValDay:       numberCell(line.ValDay),
+		columnValBeginning: numberCell(line.ValBeginning),
+		columnValUnit:      {},
+		columnNotes:        {},
+	}
+
+	if line.ValDay != 0 || line.ValBeginning != 0 {
+		cells[columnValUnit] = textCell(line.ValUnit())
+	}
+
+	var notes []string
+
+	yellow := models.YellowColor
+	highlights := []struct {
+		column     int
+		marked     bool
+		suggestion string
+	}{
+		{columnDivision, line.DivisionYellow, line.DivisionSuggestion},
+		{columnOperation, line.OperationYellow, line.OperationSuggestion},
+		{columnCulture, line.CultureYellow, line.CultureSuggestion},
+	}
+	for _, highlight := range highlights {
+		if !highlight.marked {
+			continue
+		}
+
+		cells[highlight.column].fill = &yellow
+		if highlight.suggestion != "" {
+			notes = append(notes, reportHeaders[highlight.column]+": возможно, "+highlight.suggestion)
+		}
+	}
+
+	for _, flag := range line.Flags {
+		column, ok := flagColumns[flag.Field]
+		if !ok {
+			continue
+		}
+
+		// a cell with several flags gets the color of the first one
+		if cells[column].fill == nil {
+			color := models.FlagColor(flag.Code)
+			cells[column].fill = &color
+		}
+
+		notes = append(notes, reportHeaders[column]+": "+flag.Message)
+	}
+
+	cells[columnNotes].text = strings.Join(notes, "\n")
+
+	return cells
+}
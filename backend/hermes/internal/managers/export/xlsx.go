@@ -0,0 +1,266 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
+)
+
+const XLSXMimeType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// cell is a value of the sheet, a string or a number, with its fill.
+type cell struct {
+	text   string
+	number *float64
+	fill   *models.Color
+}
+
+func textCell(text string) cell {
+	return cell{text: text}
+}
+
+func numberCell(value float64) cell {
+	return cell{number: &value}
+}
+
+// sheet is the only sheet of a workbook: a bold header row kept visible
+// while scrolling, with a filter, and the rows under it.
+type sheet struct {
+	name    string
+	headers []string
+	// widths are the widths of the columns in characters
+	widths []float64
+	// wrapped is the column of multiline text, -1 for none
+	wrapped int
+
+	rows [][]cell
+}
+
+// render writes the sheet as an XLSX workbook.
+func (s sheet) render() ([]byte, error) {
+	styles := newStyleSheet()
+	worksheet := s.renderWorksheet(styles)
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", contentTypes},
+		{"_rels/.rels", rootRels},
+		{"xl/workbook.xml", renderWorkbook(s.name)},
+		{"xl/_rels/workbook.xml.rels", workbookRels},
+		{"xl/styles.xml", styles.render()},
+		{"xl/worksheets/sheet1.xml", worksheet},
+	}
+
+	for _, part := range parts {
+		w, err := archive.Create(part.name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s: %w", part.name, err)
+		}
+
+		_, err = w.Write([]byte(part.content))
+		if err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", part.name, err)
+		}
+	}
+
+	err := archive.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to close archive: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// styleSheet collects the fills of the sheet, every fill is a cell format.
+type styleSheet struct {
+	fills []models.Color
+	index map[models.Color]int
+}
+
+// the first formats are the default one, the bold header and the wrapped text
+const (
+	styleDefault = iota
+	styleHeader
+	styleWrapped
+	styleFills
+)
+
+func newStyleSheet() *styleSheet {
+	return &styleSheet{index: make(map[models.Color]int)}
+}
+
+// style returns the cell format of the fill.
+func (s *styleSheet) style(fill *models.Color) int {
+	if fill == nil {
+		return styleDefault
+	}
+
+	i, ok := s.index[*fill]
+	if !ok {
+		i = len(s.fills)
+		s.fills = append(s.fills, *fill)
+		s.index[*fill] = i
+	}
+
+	return styleFills + i
+}
+
+func (s *styleSheet) render() string {
+	var b strings.Builder
+
+	b.WriteString(xml.Header)
+	b.WriteString(`<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	b.WriteString(`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>`)
+
+	// the first two fills are reserved by the format
+	fmt.Fprintf(&b, `<fills count="%d"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill>`, len(s.fills)+2)
+	for _, fill := range s.fills {
+		fmt.Fprintf(&b, `<fill><patternFill patternType="solid"><fgColor rgb="%s"/><bgColor indexed="64"/></patternFill></fill>`, argb(fill))
+	}
+	b.WriteString(`</fills>`)
+
+	b.WriteString(`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>`)
+	b.WriteString(`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>`)
+
+	fmt.Fprintf(&b, `<cellXfs count="%d">`, len(s.fills)+styleFills)
+	b.WriteString(`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>`)
+	b.WriteString(`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>`)
+	b.WriteString(`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0" applyAlignment="1"><alignment wrapText="1" vertical="top"/></xf>`)
+	for i := range s.fills {
+		fmt.Fprintf(&b, `<xf numFmtId="0" fontId="0" fillId="%d" borderId="0" xfId="0" applyFill="1"/>`, i+2)
+	}
+	b.WriteString(`</cellXfs>`)
+
+	b.WriteString(`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>`)
+	b.WriteString(`</styleSheet>`)
+
+	return b.String()
+}
+
+func argb(color models.Color) string {
+	component := func(value float64) int {
+		return int(math.Round(math.Max(0, math.Min(1, value)) * 255))
+	}
+
+	return fmt.Sprintf("FF%02X%02X%02X", component(color.Red), component(color.Green), component(color.Blue))
+}
+
+func (s sheet) renderWorksheet(styles *styleSheet) string {
+	var b strings.Builder
+
+	b.WriteString(xml.Header)
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	b.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+
+	if len(s.widths) > 0 {
+		b.WriteString(`<cols>`)
+		for i, width := range s.widths {
+			fmt.Fprintf(&b, `<col min="%d" max="%d" width="%s" customWidth="1"/>`, i+1, i+1, strconv.FormatFloat(width, 'f', -1, 64))
+		}
+		b.WriteString(`</cols>`)
+	}
+
+	b.WriteString(`<sheetData>`)
+
+	header := make([]cell, len(s.headers))
+	for i, title := range s.headers {
+		header[i] = textCell(title)
+	}
+	s.renderRow(&b, 1, header, styles, true)
+
+	for i, row := range s.rows {
+		s.renderRow(&b, i+2, row, styles, false)
+	}
+
+	b.WriteString(`</sheetData>`)
+	if len(s.rows) > 0 && len(s.headers) > 0 {
+		fmt.Fprintf(&b, `<autoFilter ref="A1:%s%d"/>`, columnName(len(s.headers)-1), len(s.rows)+1)
+	}
+	b.WriteString(`</worksheet>`)
+
+	return b.String()
+}
+
+func (s sheet) renderRow(b *strings.Builder, number int, row []cell, styles *styleSheet, header bool) {
+	fmt.Fprintf(b, `<row r="%d">`, number)
+
+	for j, c := range row {
+		ref := columnName(j) + strconv.Itoa(number)
+
+		style := styles.style(c.fill)
+		switch {
+		case header:
+			style = styleHeader
+		case j == s.wrapped && c.fill == nil:
+			style = styleWrapped
+		}
+
+		if c.number != nil {
+			fmt.Fprintf(b, `<c r="%s" s="%d"><v>%s</v></c>`, ref, style, strconv.FormatFloat(*c.number, 'f', -1, 64))
+			continue
+		}
+
+		if c.text == "" {
+			if style != styleDefault {
+				fmt.Fprintf(b, `<c r="%s" s="%d"/>`, ref, style)
+			}
+			continue
+		}
+
+		fmt.Fprintf(b, `<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, style, escape(c.text))
+	}
+
+	b.WriteString(`</row>`)
+}
+
+// columnName returns the letters of the zero based column.
+func columnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+
+	return name
+}
+
+func escape(text string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(text))
+
+	return b.String()
+}
+
+func renderWorkbook(sheetName string) string {
+	return xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="` + escape(sheetName) + `" sheetId="1" r:id="rId1"/></sheets>` +
+		`</workbook>`
+}
+
+const contentTypes = xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+	`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+	`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+	`</Types>`
+
+const rootRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+const workbookRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+	`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+	`</Relationships>`
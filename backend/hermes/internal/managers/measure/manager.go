@@ -0,0 +1,137 @@
+package measure
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/repositories"
+)
+
+func NewManager(repositories *repositories.Repositories) *Manager {
+	return &Manager{
+		repositories: repositories,
+	}
+}
+
+// Manager converts the reported quantities to the units of the table:
+// hectares for the areas and centners, or the unit expected by the
+// operation, for the gross values.
+type Manager struct {
+	repositories *repositories.Repositories
+}
+
+// precision of the converted values
+const precision = 1e6
+
+// quantity is a numeric field of the line.
+type quantity struct {
+	field string
+	value *float64
+	unit  *string
+	// gross values follow the unit expected by the operation
+	gross bool
+}
+
+func quantities(line *models.Line) []quantity {
+	return []quantity{
+		{field: "per_day", value: &line.PerDay, unit: &line.PerDayUnit},
+		{field: "per_operation", value: &line.PerOperation, unit: &line.PerOperationUnit},
+		{field: "val_day", value: &line.ValDay, unit: &line.ValDayUnit, gross: true},
+		{field: "val_beginning", value: &line.ValBeginning, unit: &line.ValBeginningUnit, gross: true},
+	}
+}
+
+// ConvertTable converts the values reported in other units and flags the ones
+// whose unit is unknown or of another dimension, those are kept as reported.
+func (m *Manager) ConvertTable(ctx context.Context, at time.Time, table models.Table) models.Table {
+	units, err := m.repositories.InformationRepo.GetMeasureUnits(ctx)
+	if err != nil {
+		log.Printf("failed to get measure units: %v", err)
+	}
+
+	expected, err := m.repositories.InformationRepo.GetExpectedUnits(ctx, at)
+	if err != nil {
+		log.Printf("failed to get expected units: %v", err)
+	}
+
+	for i := range table {
+		line := &table[i]
+
+		for _, q := range quantities(line) {
+			target := models.AreaUnit
+			if q.gross {
+				target = models.MassUnit
+				if unit, ok := expected[line.Operation]; ok {
+					target = unit
+				}
+			}
+
+			reported := strings.TrimSpace(*q.unit)
+			if reported == "" || *q.value == 0 || strings.EqualFold(reported, target) {
+				*q.unit = target
+				continue
+			}
+
+			value, flag := convert(units, *q.value, reported, target)
+			if flag != "" {
+				line.Flags = append(line.Flags, models.Flag{
+					Code:    models.FlagUnitMismatch,
+					Field:   q.field,
+					Message: flag,
+				})
+				continue
+			}
+
+			if line.Measures == nil {
+				line.Measures = make(map[string]models.Measure)
+			}
+			line.Measures[q.field] = models.Measure{Value: *q.value, Unit: reported}
+
+			*q.value = value
+			*q.unit = target
+		}
+	}
+
+	return table
+}
+
+// convert returns the value in the target unit, or the reason it can not be converted.
+func convert(units []models.MeasureUnit, value float64, from, to string) (float64, string) {
+	source, ok := find(units, from)
+	if !ok {
+		return value, fmt.Sprintf("Неизвестная единица «%s», ожидается %s", from, to)
+	}
+
+	target, ok := find(units, to)
+	if !ok {
+		return value, fmt.Sprintf("Неизвестная единица «%s»", to)
+	}
+
+	if source.Dimension != target.Dimension {
+		return value, fmt.Sprintf("Указано %s %s, ожидается %s", formatNumber(value), source.Code, target.Code)
+	}
+
+	// 37400 кг is 374 ц, not 374.00000000000006
+	converted := value * source.Factor / target.Factor
+	return math.Round(converted*precision) / precision, ""
+}
+
+func find(units []models.MeasureUnit, text string) (models.MeasureUnit, bool) {
+	for _, unit := range units {
+		if unit.Matches(text) {
+			return unit, true
+		}
+	}
+
+	return models.MeasureUnit{}, false
+}
+
+func formatNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
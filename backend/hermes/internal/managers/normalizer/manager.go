@@ -0,0 +1,232 @@
+package normalizer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/repositories"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/repositories/information"
+)
+
+type Config struct {
+	// values scored above AutoThreshold are replaced with the dictionary entry
+	AutoThreshold float64 `json:"FUZZY_AUTO_THRESHOLD" cfgDefault:"0.9"`
+	// values scored above SuggestThreshold stay yellow with a suggested entry
+	SuggestThreshold float64 `json:"FUZZY_SUGGEST_THRESHOLD" cfgDefault:"0.7"`
+	// two best candidates closer than AmbiguityMargin are never auto-substituted
+	AmbiguityMargin float64 `json:"FUZZY_AMBIGUITY_MARGIN" cfgDefault:"0.03"`
+}
+
+func NewManager(cfg Config, repositories *repositories.Repositories) *Manager {
+	return &Manager{
+		repositories:     repositories,
+		autoThreshold:    cfg.AutoThreshold,
+		suggestThreshold: cfg.SuggestThreshold,
+		ambiguityMargin:  cfg.AmbiguityMargin,
+	}
+}
+
+// Manager matches recognized values against the dictionaries
+// (cultures, operations, units).
+type Manager struct {
+	repositories *repositories.Repositories
+
+	autoThreshold    float64
+	suggestThreshold float64
+	ambiguityMargin  float64
+}
+
+// Match is the result of matching a raw value against a dictionary.
+type Match struct {
+	Value      string
+	Suggestion string
+	Yellow     bool
+}
+
+// NormalizeTable matches the table against the dictionaries in force at the moment the report was sent.
+func (m *Manager) NormalizeTable(ctx context.Context, chatContextID int, at time.Time, table models.Table) models.Table {
+	checks, err := m.repositories.InformationRepo.ValidateTable(ctx, at, table)
+	if err != nil {
+		log.Printf("failed to validate table: %v", err)
+		checks = make([]information.LineCheck, len(table))
+	}
+
+	cultures, err := m.repositories.InformationRepo.GetCultures(ctx, at)
+	if err != nil {
+		log.Printf("failed to get cultures: %v", err)
+	}
+
+	operations, err := m.repositories.InformationRepo.GetOperations(ctx, at)
+	if err != nil {
+		log.Printf("failed to get operations: %v", err)
+	}
+
+	divisions, err := m.repositories.InformationRepo.GetDivisions(ctx, at)
+	if err != nil {
+		log.Printf("failed to get divisions: %v", err)
+	}
+
+	units, err := m.repositories.InformationRepo.GetUnits(ctx, at)
+	if err != nil {
+		log.Printf("failed to get units: %v", err)
+	}
+
+	cultureAliases := m.getAliases(ctx, models.DictionaryCultures, chatContextID)
+	operationAliases := m.getAliases(ctx, models.DictionaryOperations, chatContextID)
+	divisionAliases := m.getAliases(ctx, models.DictionaryUnits, chatContextID)
+
+	for i, row := range table {
+		table[i].CultureRaw = row.Culture
+		table[i].OperationRaw = row.Operation
+		table[i].DivisionRaw = row.Division
+
+		if !checks[i].Culture {
+			match := m.matchWithAliases(row.Culture, cultures, cultureAliases)
+			table[i].Culture = match.Value
+			table[i].CultureSuggestion = match.Suggestion
+			table[i].CultureYellow = match.Yellow
+		}
+
+		if !checks[i].Operation {
+			match := m.matchWithAliases(row.Operation, operations, operationAliases)
+			table[i].Operation = match.Value
+			table[i].OperationSuggestion = match.Suggestion
+			table[i].OperationYellow = match.Yellow
+		}
+
+		if !checks[i].Division {
+			match := m.matchWithAliases(row.Division, divisions, divisionAliases)
+			table[i].Division = match.Value
+			table[i].DivisionSuggestion = match.Suggestion
+			table[i].DivisionYellow = match.Yellow
+		}
+
+		m.resolveUnit(&table[i], divisions, units)
+	}
+
+	return table
+}
+
+// getAliases returns dictionary values by folded alias.
+// Aliases of the chat context take precedence over global ones.
+func (m *Manager) getAliases(ctx context.Context, dictionary models.Dictionary, chatContextID int) map[string]string {
+	aliases, err := m.repositories.InformationRepo.GetAliases(ctx, dictionary, chatContextID)
+	if err != nil {
+		log.Printf("failed to get %s aliases: %v", dictionary, err)
+		return nil
+	}
+
+	result := make(map[string]string, len(aliases))
+	for _, alias := range aliases {
+		if alias.ChatContextID == nil || *alias.ChatContextID != chatContextID {
+			result[fold(alias.Alias)] = alias.Value
+		}
+	}
+
+	for _, alias := range aliases {
+		if alias.ChatContextID != nil && *alias.ChatContextID == chatContextID {
+			result[fold(alias.Alias)] = alias.Value
+		}
+	}
+
+	return result
+}
+
+func (m *Manager) matchWithAliases(raw string, candidates []string, aliases map[string]string) Match {
+	if value, ok := aliases[fold(raw)]; ok {
+		return Match{Value: value}
+	}
+
+	return m.Match(raw, candidates)
+}
+
+// LearnCorrection stores the alias for the corrected value in the chat context.
+// The dictionary is detected by the value, which must be an existing entry.
+func (m *Manager) LearnCorrection(ctx context.Context, raw string, value string, chatContextID int, source string) (models.Alias, error) {
+	return m.learn(ctx, models.Alias{Alias: raw, Value: value, ChatContextID: &chatContextID, Source: source})
+}
+
+// LearnGlobalAlias stores the alias that applies to every chat context without approval.
+func (m *Manager) LearnGlobalAlias(ctx context.Context, raw string, value string, source string) (models.Alias, error) {
+	return m.learn(ctx, models.Alias{Alias: raw, Value: value, Global: true, Source: source})
+}
+
+func (m *Manager) learn(ctx context.Context, alias models.Alias) (models.Alias, error) {
+	var err error
+	alias.Dictionary, alias.Value, err = m.findEntry(ctx, alias.Value)
+	if err != nil {
+		return models.Alias{}, err
+	}
+
+	alias.ID, err = m.repositories.InformationRepo.AddAlias(ctx, alias)
+	if err != nil {
+		return models.Alias{}, fmt.Errorf("failed to add alias: %w", err)
+	}
+
+	return alias, nil
+}
+
+func (m *Manager) findEntry(ctx context.Context, value string) (models.Dictionary, string, error) {
+	lookups := []struct {
+		dictionary models.Dictionary
+		get        func(ctx context.Context, at time.Time) ([]string, error)
+	}{
+		{models.DictionaryCultures, m.repositories.InformationRepo.GetCultures},
+		{models.DictionaryOperations, m.repositories.InformationRepo.GetOperations},
+		{models.DictionaryUnits, m.repositories.InformationRepo.GetDivisions},
+	}
+
+	var errs []error
+	for _, lookup := range lookups {
+		entries, err := lookup.get(ctx, time.Now())
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		for _, entry := range entries {
+			if fold(entry) == fold(value) {
+				return lookup.dictionary, entry, nil
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return "", "", fmt.Errorf("failed to get dictionaries: %w", errors.Join(errs...))
+	}
+
+	return "", "", fmt.Errorf("%w: %s", information.ErrUnknownValue, value)
+}
+
+// Match finds the dictionary entry for the raw value.
+func (m *Manager) Match(raw string, candidates []string) Match {
+	var best, second float64
+	var bestValue string
+
+	for _, candidate := range candidates {
+		if candidate == raw {
+			return Match{Value: raw}
+		}
+
+		score := similarity(raw, candidate)
+		if score > best {
+			best, second = score, best
+			bestValue = candidate
+		} else if score > second {
+			second = score
+		}
+	}
+
+	switch {
+	case best >= m.autoThreshold && best-second >= m.ambiguityMargin:
+		return Match{Value: bestValue}
+	case best >= m.suggestThreshold:
+		return Match{Value: raw, Suggestion: bestValue, Yellow: true}
+	}
+
+	return Match{Value: raw, Yellow: true}
+}
@@ -0,0 +1,230 @@
+package normalizer
+
+import (
+	"strings"
+	"unicode"
+)
+
+// abbreviations are expanded before matching. Keys are folded tokens.
+var abbreviations = map[string]string{
+	"оз":      "озимая",
+	"яр":      "яровой",
+	"пш":      "пшеница",
+	"подс":    "подсолнечник",
+	"подсолн": "подсолнечник",
+	"кук":     "кукуруза",
+	"яч":      "ячмень",
+	"сах":     "сахарная",
+	"св":      "свекла",
+	"тов":     "товарная",
+	"сем":     "семенная",
+	"конд":    "кондитерский",
+	"мн":      "многолетние",
+	"тр":      "травы",
+	"культ":   "культивация",
+	"предп":   "предпосевная",
+	"междур":  "междурядная",
+	"гербиц":  "гербицидная",
+	"фунгиц":  "функицидная",
+	"инсект":  "инсектицидная",
+	"обр":     "обработка",
+	"вн":      "внесение",
+	"мин":     "минеральных",
+	"удобр":   "удобрений",
+	"диск":    "дискование",
+	"бор":     "боронование",
+}
+
+// endings are cut from tokens so that "озимая" and "озимый" are equal.
+var endings = []string{
+	"ами", "ями", "ого", "его", "ому", "ему", "ыми", "ими",
+	"ая", "яя", "ое", "ее", "ые", "ие", "ый", "ий", "ой", "ую", "юю", "ов", "ев", "ах", "ях", "ам", "ям",
+	"а", "я", "ы", "и", "е", "о", "у", "ю", "ь", "й",
+}
+
+const minStemLength = 3
+
+// fold lowercases the string, replaces "ё" with "е" and keeps only letters
+// and digits separated by single spaces.
+func fold(s string) string {
+	var b strings.Builder
+	space := false
+
+	for _, r := range strings.ToLower(s) {
+		if r == 'ё' {
+			r = 'е'
+		}
+
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			space = false
+			b.WriteRune(r)
+			continue
+		}
+
+		space = true
+	}
+
+	return b.String()
+}
+
+func tokens(folded string) []string {
+	fields := strings.Fields(folded)
+	result := make([]string, 0, len(fields))
+
+	for _, field := range fields {
+		if expanded, ok := abbreviations[field]; ok {
+			field = expanded
+		}
+
+		result = append(result, stem(field))
+	}
+
+	return result
+}
+
+func stem(token string) string {
+	runes := []rune(token)
+
+	for _, ending := range endings {
+		n := len([]rune(ending))
+		if len(runes)-n >= minStemLength && strings.HasSuffix(token, ending) {
+			return string(runes[:len(runes)-n])
+		}
+	}
+
+	return token
+}
+
+// similarity scores how well the raw value matches the dictionary entry, 0..1.
+func similarity(raw, candidate string) float64 {
+	a, b := fold(raw), fold(candidate)
+	if a == "" || b == "" {
+		return 0
+	}
+
+	if a == b {
+		return 1
+	}
+
+	return max(dice(trigrams(a), trigrams(b)), tokenScore(tokens(a), tokens(b)))
+}
+
+// tokenScore matches every raw token with the closest candidate token.
+// Candidate tokens left unmatched lower the score, so the shortest entry
+// that contains all raw tokens wins.
+func tokenScore(raw, candidate []string) float64 {
+	if len(raw) == 0 || len(candidate) == 0 {
+		return 0
+	}
+
+	matched := make([]bool, len(candidate))
+
+	var total float64
+	for _, r := range raw {
+		best, bestIndex := 0.0, -1
+		for i, c := range candidate {
+			score := tokenSimilarity(r, c)
+			if score > best {
+				best, bestIndex = score, i
+			}
+		}
+
+		if best >= 0.8 {
+			matched[bestIndex] = true
+		}
+
+		total += best
+	}
+
+	coverage := total / float64(len(raw))
+
+	matchedCount := 0
+	for _, ok := range matched {
+		if ok {
+			matchedCount++
+		}
+	}
+
+	return coverage * (0.7 + 0.3*float64(matchedCount)/float64(len(candidate)))
+}
+
+func tokenSimilarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+
+	ra, rb := []rune(a), []rune(b)
+
+	// abbreviation that is not in the dictionary, e.g. "подсолн"
+	shorter, longer := a, b
+	if len(ra) > len(rb) {
+		shorter, longer = b, a
+	}
+	if len([]rune(shorter)) >= 2 && strings.HasPrefix(longer, shorter) {
+		return 0.9
+	}
+
+	distance := levenshtein(ra, rb)
+
+	return 1 - float64(distance)/float64(max(len(ra), len(rb)))
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
+
+func trigrams(s string) map[string]int {
+	result := make(map[string]int)
+
+	for _, word := range strings.Fields(s) {
+		runes := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			result[string(runes[i:i+3])]++
+		}
+	}
+
+	return result
+}
+
+func dice(a, b map[string]int) float64 {
+	var common, total int
+
+	for gram, count := range a {
+		common += min(count, b[gram])
+		total += count
+	}
+
+	for _, count := range b {
+		total += count
+	}
+
+	if total == 0 {
+		return 0
+	}
+
+	return 2 * float64(common) / float64(total)
+}
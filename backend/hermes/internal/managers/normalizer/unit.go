@@ -0,0 +1,124 @@
+package normalizer
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
+)
+
+var (
+	// "Отд 17", "отделение №17", "отд.17"
+	departmentPattern = regexp.MustCompile(`(?i)(?:^|[^\p{L}])(?:отд(?:еление)?|№)\.?\s*№?\s*(\d+)`)
+	// "ПУ Север", "ПУ 7"; a number after ПУ is a department in practice
+	puPattern = regexp.MustCompile(`(?i)(?:^|[^\p{L}])пу\.?\s*([\p{L}-]+|\d+)`)
+)
+
+// unitHints are the parts of the unit mentioned in the raw division.
+type unitHints struct {
+	division   string
+	pu         string
+	department string
+}
+
+func parseUnitHints(raw string, divisions []string) unitHints {
+	var hints unitHints
+
+	if match := departmentPattern.FindStringSubmatch(raw); match != nil {
+		hints.department = match[1]
+	}
+
+	if match := puPattern.FindStringSubmatch(raw); match != nil {
+		if isDigits(match[1]) {
+			if hints.department == "" {
+				hints.department = match[1]
+			}
+		} else {
+			hints.pu = match[1]
+		}
+	}
+
+	// the division may come together with PU or department, e.g. "АОР отд 17"
+	folded := " " + fold(raw) + " "
+	for _, division := range divisions {
+		if len(division) > len(hints.division) && strings.Contains(folded, " "+fold(division)+" ") {
+			hints.division = division
+		}
+	}
+
+	return hints
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return s != ""
+}
+
+// resolveUnit finds the unit of the line by its division, PU and department.
+// The division is inferred from PU and department when it was not recognized.
+func (m *Manager) resolveUnit(line *models.Line, divisions []string, units []models.DictionaryEntry) {
+	hints := parseUnitHints(line.DivisionRaw, divisions)
+	if !line.DivisionYellow {
+		hints.division = line.Division
+	}
+
+	if hints.division == "" && hints.pu == "" && hints.department == "" {
+		return
+	}
+
+	candidates := make([]models.DictionaryEntry, 0)
+	for _, unit := range units {
+		if hints.division != "" && unit.Name != hints.division {
+			continue
+		}
+
+		if hints.pu != "" && fold(unit.PU) != fold(hints.pu) {
+			continue
+		}
+
+		if hints.department != "" && unit.Department != hints.department {
+			continue
+		}
+
+		candidates = append(candidates, unit)
+	}
+
+	if len(candidates) == 0 {
+		return
+	}
+
+	division := candidates[0].Name
+	for _, candidate := range candidates[1:] {
+		if candidate.Name != division {
+			return
+		}
+	}
+
+	if line.DivisionYellow {
+		line.Division = division
+		line.DivisionSuggestion = ""
+		line.DivisionYellow = false
+	}
+
+	if len(candidates) == 1 {
+		line.UnitID = candidates[0].ID
+		line.PU = candidates[0].PU
+		line.Department = candidates[0].Department
+		return
+	}
+
+	// several departments of the same PU
+	pu := candidates[0].PU
+	for _, candidate := range candidates[1:] {
+		if candidate.PU != pu {
+			return
+		}
+	}
+
+	line.PU = pu
+}
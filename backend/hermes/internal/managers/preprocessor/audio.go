@@ -0,0 +1,287 @@
+package preprocessor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
+)
+
+var (
+	ErrUnsupportedAudio = errors.New("audio format is not supported")
+	ErrAudioTooLong     = errors.New("audio is too long")
+	ErrAudioTooLarge    = errors.New("audio is too large")
+)
+
+// AudioInfo is the information read from the audio container headers.
+type AudioInfo struct {
+	// Format is the file extension accepted by Apollo (mp3, wav, ogg, oga).
+	Format string
+	Codec  string
+	Size   int
+
+	// Duration is zero when it can not be read from the headers.
+	Duration time.Duration
+}
+
+func (m *Manager) AudioMaxDuration() time.Duration {
+	return time.Duration(m.audioMaxDurationSeconds) * time.Second
+}
+
+func (m *Manager) AudioMaxSize() int {
+	return m.audioMaxSizeBytes
+}
+
+// InspectAudio detects format, codec and duration of the audio and checks
+// them against the configured limits. Only the headers are read from the
+// file. The returned info is filled as far as it could be parsed even when
+// an error is returned.
+func (m *Manager) InspectAudio(media models.Media) (AudioInfo, error) {
+	info := AudioInfo{
+		Format: strings.TrimPrefix(media.Extension, "."),
+		Size:   int(media.Size),
+	}
+
+	file, err := media.Open()
+	if err != nil {
+		return info, fmt.Errorf("failed to open audio: %w", err)
+	}
+	defer file.Close()
+
+	switch info.Format {
+	case "ogg", "oga":
+		info.Codec, info.Duration = parseOgg(file, media.Size)
+	case "wav":
+		info.Codec, info.Duration = parseWav(file, media.Size)
+	case "mp3":
+		info.Codec, info.Duration = parseMp3(file, media.Size)
+	default:
+		return info, fmt.Errorf("%w: %s", ErrUnsupportedAudio, media.MIME)
+	}
+
+	if m.audioMaxSizeBytes > 0 && info.Size > m.audioMaxSizeBytes {
+		return info, fmt.Errorf("%w: %d bytes", ErrAudioTooLarge, info.Size)
+	}
+
+	if m.audioMaxDurationSeconds > 0 && info.Duration > m.AudioMaxDuration() {
+		return info, fmt.Errorf("%w: %s", ErrAudioTooLong, info.Duration)
+	}
+
+	return info, nil
+}
+
+// readAt reads up to n bytes at the offset, fewer at the end of the file.
+func readAt(r io.ReaderAt, offset, n int64) []byte {
+	if offset < 0 || n <= 0 {
+		return nil
+	}
+
+	buf := make([]byte, n)
+	read, _ := r.ReadAt(buf, offset)
+
+	return buf[:read]
+}
+
+const (
+	oggPageHeaderSize = 27
+	// header, 255 segments of 255 bytes
+	oggMaxPageSize = oggPageHeaderSize + 255 + 255*255
+)
+
+// parseOgg reads codec from the first page and duration from the granule
+// position of the last page.
+func parseOgg(r io.ReaderAt, size int64) (string, time.Duration) {
+	data := readAt(r, 0, oggMaxPageSize)
+	if len(data) < oggPageHeaderSize || !bytes.HasPrefix(data, []byte("OggS")) {
+		return "unknown", 0
+	}
+
+	segments := int(data[26])
+	bodyStart := oggPageHeaderSize + segments
+	if bodyStart > len(data) {
+		return "unknown", 0
+	}
+	body := data[bodyStart:]
+
+	var codec string
+	var sampleRate, preSkip int64
+
+	switch {
+	case bytes.HasPrefix(body, []byte("OpusHead")) && len(body) >= 12:
+		codec = "opus"
+		// opus granule position is always counted at 48 kHz
+		sampleRate = 48000
+		preSkip = int64(binary.LittleEndian.Uint16(body[10:]))
+	case bytes.HasPrefix(body, []byte("\x01vorbis")) && len(body) >= 16:
+		codec = "vorbis"
+		sampleRate = int64(binary.LittleEndian.Uint32(body[12:]))
+	default:
+		return "unknown", 0
+	}
+
+	// the last page starts within the last max page size bytes
+	tail := readAt(r, max(0, size-oggMaxPageSize), oggMaxPageSize)
+
+	last := bytes.LastIndex(tail, []byte("OggS"))
+	if last < 0 || last+oggPageHeaderSize > len(tail) || sampleRate == 0 {
+		return codec, 0
+	}
+
+	granule := int64(binary.LittleEndian.Uint64(tail[last+6:]))
+	samples := granule - preSkip
+	if samples <= 0 {
+		return codec, 0
+	}
+
+	return codec, time.Duration(samples * int64(time.Second) / sampleRate)
+}
+
+func parseWav(r io.ReaderAt, size int64) (string, time.Duration) {
+	header := readAt(r, 0, 12)
+	if len(header) < 12 || string(header[:4]) != "RIFF" || string(header[8:12]) != "WAVE" {
+		return "unknown", 0
+	}
+
+	codec := "unknown"
+	var byteRate int64
+
+	offset := int64(12)
+	for offset+8 <= size {
+		chunkHeader := readAt(r, offset, 8)
+		if len(chunkHeader) < 8 {
+			break
+		}
+
+		id := string(chunkHeader[:4])
+		chunkSize := int64(binary.LittleEndian.Uint32(chunkHeader[4:]))
+
+		switch id {
+		case "fmt ":
+			chunk := readAt(r, offset+8, 16)
+			if len(chunk) < 12 {
+				return codec, 0
+			}
+
+			switch binary.LittleEndian.Uint16(chunk) {
+			case 1:
+				codec = "pcm"
+			case 3:
+				codec = "float"
+			case 6:
+				codec = "alaw"
+			case 7:
+				codec = "ulaw"
+			}
+
+			byteRate = int64(binary.LittleEndian.Uint32(chunk[8:]))
+		case "data":
+			if byteRate == 0 {
+				return codec, 0
+			}
+
+			// streamed files may have unknown data size
+			chunkSize = min(chunkSize, size-offset-8)
+
+			return codec, time.Duration(chunkSize * int64(time.Second) / byteRate)
+		}
+
+		// chunks are word aligned
+		offset += 8 + chunkSize + chunkSize%2
+	}
+
+	return codec, 0
+}
+
+var (
+	mp3Bitrates = map[bool][16]int64{
+		true:  {0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0},
+		false: {0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0},
+	}
+	mp3SampleRates = [3]int64{44100, 48000, 32000}
+)
+
+// mp3SearchSize is how far after the ID3 tag the first frame is looked for.
+const mp3SearchSize = 64 << 10
+
+// parseMp3 reads the first MPEG Layer III frame header. Duration is taken
+// from the Xing/Info header for VBR files or estimated from the bitrate.
+func parseMp3(r io.ReaderAt, size int64) (string, time.Duration) {
+	start := int64(0)
+	if tag := readAt(r, 0, 10); len(tag) == 10 && string(tag[:3]) == "ID3" {
+		tagSize := int64(tag[6]&0x7f)<<21 | int64(tag[7]&0x7f)<<14 | int64(tag[8]&0x7f)<<7 | int64(tag[9]&0x7f)
+		start = 10 + tagSize
+		if tag[5]&0x10 != 0 {
+			start += 10
+		}
+	}
+
+	data := readAt(r, start, mp3SearchSize)
+
+	offset := 0
+	for ; offset+4 <= len(data); offset++ {
+		if data[offset] == 0xFF && data[offset+1]&0xE0 == 0xE0 {
+			break
+		}
+	}
+	if offset+4 > len(data) {
+		return "unknown", 0
+	}
+
+	header := data[offset : offset+4]
+
+	version := (header[1] >> 3) & 0x03 // 3 - MPEG1, 2 - MPEG2, 0 - MPEG2.5
+	layer := (header[1] >> 1) & 0x03   // 1 - Layer III
+	bitrateIndex := header[2] >> 4
+	sampleRateIndex := (header[2] >> 2) & 0x03
+	mono := header[3]>>6 == 3
+
+	if version == 1 || layer != 1 || sampleRateIndex == 3 {
+		return "unknown", 0
+	}
+
+	mpeg1 := version == 3
+
+	sampleRate := mp3SampleRates[sampleRateIndex]
+	samplesPerFrame := int64(1152)
+	switch version {
+	case 2:
+		sampleRate /= 2
+		samplesPerFrame = 576
+	case 0:
+		sampleRate /= 4
+		samplesPerFrame = 576
+	}
+
+	// Xing/Info header follows the side information of the first frame
+	sideInfo := 32
+	switch {
+	case mpeg1 && mono:
+		sideInfo = 17
+	case !mpeg1 && !mono:
+		sideInfo = 17
+	case !mpeg1 && mono:
+		sideInfo = 9
+	}
+
+	xing := offset + 4 + sideInfo
+	if xing+12 <= len(data) {
+		tag := string(data[xing : xing+4])
+		flags := binary.BigEndian.Uint32(data[xing+4:])
+		if (tag == "Xing" || tag == "Info") && flags&0x01 != 0 {
+			frames := int64(binary.BigEndian.Uint32(data[xing+8:]))
+			return "mp3", time.Duration(frames * samplesPerFrame * int64(time.Second) / sampleRate)
+		}
+	}
+
+	bitrate := mp3Bitrates[mpeg1][bitrateIndex] * 1000
+	if bitrate == 0 {
+		return "mp3", 0
+	}
+
+	return "mp3", time.Duration((size - start - int64(offset)) * 8 * int64(time.Second) / bitrate)
+}
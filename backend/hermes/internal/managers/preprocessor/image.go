@@ -0,0 +1,119 @@
+package preprocessor
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"strings"
+
+	_ "image/gif"
+
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
+	_ "golang.org/x/image/bmp"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/tiff"
+	_ "golang.org/x/image/webp"
+)
+
+var (
+	ErrNotImage         = errors.New("file is not an image")
+	ErrUnsupportedImage = errors.New("image format is not supported")
+)
+
+// jpegHeadSize is how much of a JPEG file is read for the EXIF orientation,
+// the APP1 segment holding it is at most 64 KiB and comes first.
+const jpegHeadSize = 128 << 10
+
+// NormalizeImage prepares the image for Apollo: converts it to jpeg or png,
+// applies EXIF orientation and downsizes it to the configured max dimension.
+// Images that are already acceptable are returned unchanged, otherwise the
+// result is written to a new temporary file.
+func (m *Manager) NormalizeImage(media models.Media) (models.Media, error) {
+	if !strings.HasPrefix(media.MIME, "image/") {
+		return models.Media{}, fmt.Errorf("%w: %s", ErrNotImage, media.MIME)
+	}
+
+	file, err := media.Open()
+	if err != nil {
+		return models.Media{}, fmt.Errorf("failed to open image: %w", err)
+	}
+	defer file.Close()
+
+	cfg, format, err := image.DecodeConfig(file)
+	if err != nil {
+		return models.Media{}, fmt.Errorf("%w: %s", ErrUnsupportedImage, media.MIME)
+	}
+
+	orientation := 1
+	if format == "jpeg" {
+		head := make([]byte, jpegHeadSize)
+		n, _ := file.ReadAt(head, 0)
+		orientation = jpegOrientation(head[:n])
+	}
+
+	needResize := m.imageMaxDimension > 0 && max(cfg.Width, cfg.Height) > m.imageMaxDimension
+
+	if (format == "jpeg" || format == "png") && orientation == 1 && !needResize {
+		return media, nil
+	}
+
+	_, err = file.Seek(0, io.SeekStart)
+	if err != nil {
+		return models.Media{}, fmt.Errorf("failed to rewind image: %w", err)
+	}
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return models.Media{}, fmt.Errorf("failed to decode %s image: %w", format, err)
+	}
+
+	if needResize {
+		img = m.resize(img)
+	}
+
+	img = applyOrientation(img, orientation)
+
+	return models.DownloadMedia(func(out *os.File) error {
+		var err error
+		if format == "png" || format == "gif" || !isOpaque(img) {
+			err = png.Encode(out, img)
+		} else {
+			err = jpeg.Encode(out, img, &jpeg.Options{Quality: m.imageJPEGQuality})
+		}
+		if err != nil {
+			return fmt.Errorf("failed to encode image: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (m *Manager) resize(img image.Image) image.Image {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	if w >= h {
+		h = max(1, h*m.imageMaxDimension/w)
+		w = m.imageMaxDimension
+	} else {
+		w = max(1, w*m.imageMaxDimension/h)
+		h = m.imageMaxDimension
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+
+	return dst
+}
+
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+
+	return true
+}
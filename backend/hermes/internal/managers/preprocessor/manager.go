@@ -0,0 +1,28 @@
+package preprocessor
+
+type Config struct {
+	ImageMaxDimension int `json:"IMAGE_MAX_DIMENSION" cfgDefault:"2048"`
+	ImageJPEGQuality  int `json:"IMAGE_JPEG_QUALITY" cfgDefault:"85"`
+
+	AudioMaxDurationSeconds int `json:"AUDIO_MAX_DURATION_SECONDS" cfgDefault:"300"`
+	// 25 MB is the upload limit of the transcription API
+	AudioMaxSizeBytes int `json:"AUDIO_MAX_SIZE_BYTES" cfgDefault:"26214400"`
+}
+
+func NewManager(cfg Config) *Manager {
+	return &Manager{
+		imageMaxDimension:       cfg.ImageMaxDimension,
+		imageJPEGQuality:        cfg.ImageJPEGQuality,
+		audioMaxDurationSeconds: cfg.AudioMaxDurationSeconds,
+		audioMaxSizeBytes:       cfg.AudioMaxSizeBytes,
+	}
+}
+
+// Manager prepares media received from messengers before it is sent to Apollo.
+type Manager struct {
+	imageMaxDimension int
+	imageJPEGQuality  int
+
+	audioMaxDurationSeconds int
+	audioMaxSizeBytes       int
+}
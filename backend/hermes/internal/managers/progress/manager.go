@@ -0,0 +1,232 @@
+package progress
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/repositories"
+)
+
+// reported cumulative values may differ from computed ones by rounding,
+// the same tolerance is used by the progress_totals view
+const (
+	toleranceAbsolute = 1
+	toleranceRelative = 0.05
+)
+
+type Config struct {
+	// SeasonStartMonth is the first month of a season, a season is named by
+	// the year it ends in: with 9, crops sown in September 2024 belong to
+	// season 2025
+	SeasonStartMonth int `json:"PROGRESS_SEASON_START_MONTH" cfgDefault:"1"`
+}
+
+func NewManager(cfg Config, repositories *repositories.Repositories) *Manager {
+	return &Manager{
+		repositories:     repositories,
+		seasonStartMonth: time.Month(max(1, min(12, cfg.SeasonStartMonth))),
+	}
+}
+
+// Manager keeps the running totals of report lines per division, operation,
+// culture and season.
+type Manager struct {
+	repositories *repositories.Repositories
+
+	seasonStartMonth time.Month
+}
+
+// Season returns the season the date belongs to.
+func (m *Manager) Season(date time.Time) int {
+	if m.seasonStartMonth > time.January && date.Month() >= m.seasonStartMonth {
+		return date.Year() + 1
+	}
+
+	return date.Year()
+}
+
+// Track records the lines of the message in the ledger, fills missing
+// cumulative values from the previous reports and flags the reported ones
+// that differ from the computed totals. Lines with unresolved division,
+// operation or culture are not tracked. The lines replace the entries of the
+// message recorded before, e.g. when it is recognized again or edited, and
+// the totals of the later entries are recomputed.
+func (m *Manager) Track(ctx context.Context, messageID int, at time.Time, table models.Table) models.Table {
+	voided, err := m.repositories.ProgressRepo.DeleteMessageEntries(ctx, messageID)
+	if err != nil {
+		log.Printf("failed to delete progress entries of message %d: %v", messageID, err)
+	}
+
+	from := make(map[models.ProgressKey]time.Time)
+	for _, entry := range voided {
+		if date, ok := from[entry.ProgressKey]; !ok || entry.Date.Before(date) {
+			from[entry.ProgressKey] = entry.Date
+		}
+	}
+	for key, date := range from {
+		m.recompute(ctx, key, date)
+	}
+
+	for i, line := range table {
+		if line.Division == "" || line.Operation == "" || line.Culture == "" ||
+			line.DivisionYellow || line.OperationYellow || line.CultureYellow {
+			continue
+		}
+
+		// a range is accounted on its last day
+		date := line.Date.To
+		if date.IsZero() {
+			date = models.NewDate(at).To
+		}
+
+		key := models.ProgressKey{
+			Season:    m.Season(date),
+			Division:  line.Division,
+			Operation: line.Operation,
+			Culture:   line.Culture,
+		}
+
+		last, err := m.repositories.ProgressRepo.GetLast(ctx, key, date)
+		hasHistory := err == nil
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			log.Printf("failed to get progress: %v", err)
+			continue
+		}
+
+		entry := models.ProgressEntry{
+			ProgressKey: key,
+			Date:        date,
+			MessageID:   messageID,
+			PerDay:      line.PerDay,
+			ValDay:      line.ValDay,
+		}
+
+		if line.PerOperation > 0 {
+			entry.PerOperationReported = &line.PerOperation
+		}
+		if line.ValBeginning > 0 {
+			entry.ValBeginningReported = &line.ValBeginning
+		}
+
+		entry.PerOperationComputed = computed(hasHistory, last.PerOperationComputed, line.PerDay, entry.PerOperationReported)
+		entry.ValBeginningComputed = computed(hasHistory, last.ValBeginningComputed, line.ValDay, entry.ValBeginningReported)
+
+		if hasHistory {
+			table[i] = reconcile(table[i], entry)
+		}
+
+		err = m.repositories.ProgressRepo.AddEntry(ctx, entry)
+		if err != nil {
+			log.Printf("failed to add progress entry: %v", err)
+			continue
+		}
+
+		// a back-dated line changes the totals of the days after it
+		m.recompute(ctx, key, date.AddDate(0, 0, 1))
+	}
+
+	return table
+}
+
+// recompute continues the running totals of the key over the entries dated
+// from the date on.
+func (m *Manager) recompute(ctx context.Context, key models.ProgressKey, from time.Time) {
+	entries, err := m.repositories.ProgressRepo.ListEntries(ctx, key, from)
+	if err != nil {
+		log.Printf("failed to list progress entries: %v", err)
+		return
+	}
+	if len(entries) == 0 {
+		return
+	}
+
+	last, err := m.repositories.ProgressRepo.GetLast(ctx, key, from.AddDate(0, 0, -1))
+	hasHistory := err == nil
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		log.Printf("failed to get progress: %v", err)
+		return
+	}
+
+	for _, entry := range entries {
+		perOperation := computed(hasHistory, last.PerOperationComputed, entry.PerDay, entry.PerOperationReported)
+		valBeginning := computed(hasHistory, last.ValBeginningComputed, entry.ValDay, entry.ValBeginningReported)
+
+		if perOperation != entry.PerOperationComputed || valBeginning != entry.ValBeginningComputed {
+			entry.PerOperationComputed, entry.ValBeginningComputed = perOperation, valBeginning
+
+			err = m.repositories.ProgressRepo.UpdateComputed(ctx, entry)
+			if err != nil {
+				log.Printf("failed to update progress entry: %v", err)
+			}
+		}
+
+		last, hasHistory = entry, true
+	}
+}
+
+// computed continues the running total. The first entry of the key starts
+// from the reported value, as the operation may have begun before Hermes.
+func computed(hasHistory bool, previous, daily float64, reported *float64) float64 {
+	if hasHistory {
+		return previous + daily
+	}
+
+	if reported != nil {
+		return *reported
+	}
+
+	return daily
+}
+
+func reconcile(line models.Line, entry models.ProgressEntry) models.Line {
+	switch {
+	case entry.PerOperationReported == nil && entry.PerOperationComputed > 0:
+		line.PerOperation = entry.PerOperationComputed
+		line.Flags = append(line.Flags, models.Flag{
+			Code:    models.FlagCumulativeFilled,
+			Field:   "per_operation",
+			Message: fmt.Sprintf("Рассчитано по отчётам: %s га", formatNumber(entry.PerOperationComputed)),
+		})
+	case entry.PerOperationReported != nil && differs(*entry.PerOperationReported, entry.PerOperationComputed):
+		line.Flags = append(line.Flags, models.Flag{
+			Code:  models.FlagCumulativeMismatch,
+			Field: "per_operation",
+			Message: fmt.Sprintf("С начала операции %s га, по отчётам %s га",
+				formatNumber(*entry.PerOperationReported), formatNumber(entry.PerOperationComputed)),
+		})
+	}
+
+	switch {
+	case entry.ValBeginningReported == nil && entry.ValBeginningComputed > 0:
+		line.ValBeginning = entry.ValBeginningComputed
+		line.Flags = append(line.Flags, models.Flag{
+			Code:    models.FlagCumulativeFilled,
+			Field:   "val_beginning",
+			Message: fmt.Sprintf("Рассчитано по отчётам: %s %s", formatNumber(entry.ValBeginningComputed), line.ValUnit()),
+		})
+	case entry.ValBeginningReported != nil && differs(*entry.ValBeginningReported, entry.ValBeginningComputed):
+		line.Flags = append(line.Flags, models.Flag{
+			Code:  models.FlagCumulativeMismatch,
+			Field: "val_beginning",
+			Message: fmt.Sprintf("Вал с начала %s %s, по отчётам %s %s",
+				formatNumber(*entry.ValBeginningReported), line.ValUnit(), formatNumber(entry.ValBeginningComputed), line.ValUnit()),
+		})
+	}
+
+	return line
+}
+
+func differs(reported, computed float64) bool {
+	return math.Abs(reported-computed) > max(toleranceAbsolute, computed*toleranceRelative)
+}
+
+func formatNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -6,29 +6,64 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"strings"
+	"sync"
 	"time"
 
-	"github.com/gabriel-vasile/mimetype"
 	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/clients"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/managers/measure"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/managers/normalizer"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/managers/preprocessor"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/managers/progress"
 	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/managers/reporter"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/managers/review"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/managers/validator"
 	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
 	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/repositories"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/repositories/information"
 )
 
 type Config struct {
 	FilterVerbiage bool `json:"FILTER_VERBIAGE" cfgDefault:"true"`
 
 	AddChatContextName bool `json:"ADD_CHAT_CONTEXT_NAME" cfgDefault:"true"`
+
+	// in USD, used only for the cost estimate stored with the message
+	TranscriptionCostPerMinute float64 `json:"TRANSCRIPTION_COST_PER_MINUTE" cfgDefault:"0.006"`
 }
 
-func NewManager(shutdownCtx context.Context, cfg Config, clients *clients.Clients, repositories *repositories.Repositories, reporter *reporter.Manager) *Manager {
+const fixCommand = "/fix"
+
+const imageRejectedReply = "Не удалось обработать файл: отправьте фото таблицы в формате JPEG, PNG, WebP, GIF или BMP."
+
+func NewManager(
+	shutdownCtx context.Context,
+	cfg Config,
+	clients *clients.Clients,
+	repositories *repositories.Repositories,
+	reporter *reporter.Manager,
+	preprocessor *preprocessor.Manager,
+	normalizer *normalizer.Manager,
+	measure *measure.Manager,
+	validator *validator.Manager,
+	progress *progress.Manager,
+	review *review.Manager,
+) *Manager {
 	return &Manager{
 		shutdownCtx:        shutdownCtx,
 		clients:            clients,
 		repositories:       repositories,
 		reporter:           reporter,
+		preprocessor:       preprocessor,
+		normalizer:         normalizer,
+		measure:            measure,
+		validator:          validator,
+		progress:           progress,
+		review:             review,
 		filterVerbiage:     cfg.FilterVerbiage,
 		addChatContextName: cfg.AddChatContextName,
+
+		transcriptionCostPerMinute: cfg.TranscriptionCostPerMinute,
 	}
 }
 
@@ -41,9 +76,23 @@ type Manager struct {
 
 	reporter *reporter.Manager
 
+	preprocessor *preprocessor.Manager
+
+	normalizer *normalizer.Manager
+
+	measure *measure.Manager
+
+	validator *validator.Manager
+
+	progress *progress.Manager
+
+	review *review.Manager
+
 	// feature flags
 	filterVerbiage     bool
 	addChatContextName bool
+
+	transcriptionCostPerMinute float64
 }
 
 func (m *Manager) AsyncProcessTextMessage(message models.TextMessage) {
@@ -54,6 +103,14 @@ func (m *Manager) AsyncProcessTextMessage(message models.TextMessage) {
 }
 
 func (m *Manager) ProcessTextMessage(ctx context.Context, message models.TextMessage) error {
+	if raw, value, ok := parseFixCommand(message.Text); ok {
+		return m.processFixCommand(ctx, message, raw, value)
+	}
+
+	if command, ok := review.ParseCommand(message.Text); ok {
+		return m.processReviewCommand(ctx, message, command)
+	}
+
 	// pre-processing (filter verbiage)
 	var err error
 
@@ -101,7 +158,12 @@ func (m *Manager) ProcessTextMessage(ctx context.Context, message models.TextMes
 		return nil
 	}
 
-	startedAt := m.reporter.RegisterReport(ctx, chatContextID, chatContextName, message.Timestamp)
+	report := m.reporter.RegisterReport(ctx, chatContextID, chatContextName, message.Timestamp)
+	startedAt := report.StartedAt
+
+	settings := m.getSettings(ctx, chatContextID)
+
+	m.linkMessageToReport(ctx, messageID, report.ID)
 
 	go func() {
 		chatIDs, err := m.repositories.ChatsRepo.GetChats(ctx, chatContextID)
@@ -122,7 +184,7 @@ func (m *Manager) ProcessTextMessage(ctx context.Context, message models.TextMes
 		number := max(1, totalNumberOfMessages+totalNumberOfVerbiage)
 
 		// big latency here
-		err = m.clients.Googledrive.SaveMessage(ctx, models.GetDocxName(message.Name, number, message.Timestamp, chatContextName), message.Text)
+		err = m.clients.Googledrive.SaveMessage(ctx, models.GetDocxName(message.Name, number, message.Timestamp, settings.Location(), chatContextName), message.Text)
 		if err != nil {
 			log.Println("failed to save message to drive: %w", err)
 		}
@@ -130,21 +192,21 @@ func (m *Manager) ProcessTextMessage(ctx context.Context, message models.TextMes
 
 	// start processing
 
-	table, err := m.clients.Apollo.PredictTableFromText(ctx, message.Text)
+	table, err := m.clients.Apollo.PredictTableFromText(ctx, message.Text, settings.RecognitionHints())
 	if err != nil {
 		return fmt.Errorf("failed to predict text message: %w", err)
 	}
 
-	table = m.fillTable(ctx, table)
+	table = m.fillTable(ctx, settings, messageID, message.Timestamp, table)
 
-	err = m.repositories.ReportsRepo.AddTable(ctx, messageID, time.Now(), table)
+	err = m.repositories.ReportsRepo.AddTable(ctx, report.ID, messageID, time.Now(), table)
 	if err != nil {
 		return fmt.Errorf("failed to add table: %w", err)
 	}
 
 	// big latency here and sync operation with mutex
 	// no need to go in the end of function
-	err = m.clients.Googledrive.SaveTable(ctx, models.GetTableName(startedAt, chatContextName), table)
+	err = m.clients.Googledrive.SaveTable(ctx, models.GetTableName(startedAt, report.Location(), chatContextName), table)
 	if err != nil {
 		return fmt.Errorf("failed to save table to drive: %w", err)
 	}
@@ -196,41 +258,74 @@ func (m *Manager) GetWorkerID(ctx context.Context, message models.TextMessage) (
 	return workerID, nil
 }
 
-func (m *Manager) fillTable(ctx context.Context, table models.Table) models.Table {
+func (m *Manager) linkMessageToReport(ctx context.Context, messageID int, reportID int) {
+	if reportID == 0 {
+		return
+	}
+
+	err := m.repositories.MessagesRepo.SetReport(ctx, messageID, reportID)
+	if err != nil {
+		log.Printf("failed to link message to report: %v", err)
+	}
+}
+
+// getSettings returns the settings of the chat context, zero settings when they could not be read.
+func (m *Manager) getSettings(ctx context.Context, chatContextID int) models.ChatContextSettings {
+	settings, err := m.repositories.ChatsRepo.GetSettings(ctx, chatContextID)
+	if err != nil {
+		log.Printf("failed to get chat context settings: %v", err)
+		return models.ChatContextSettings{ChatContextID: chatContextID}
+	}
+
+	return settings
+}
+
+// fillTable resolves, normalizes and validates the recognized lines. Dates
+// are resolved in the time zone of the chat context.
+func (m *Manager) fillTable(ctx context.Context, settings models.ChatContextSettings, messageID int, at time.Time, table models.Table) models.Table {
+	at = at.In(settings.Location())
+
 	for i, row := range table {
-		if row.Date == "" {
-			table[i].Date = time.Now().Format("02.01.2006")
-		}
+		table[i].DateRaw = row.Date.Raw()
 
-		exists, err := m.repositories.InformationRepo.CheckCulture(ctx, row.Culture)
+		date, err := row.Date.Resolve(at)
 		if err != nil {
-			log.Printf("failed to check culture: %v", err)
+			date = models.NewDate(at)
+			table[i].Flags = append(table[i].Flags, models.Flag{
+				Code:    models.FlagDateInvalid,
+				Field:   "date",
+				Message: fmt.Sprintf("Не удалось разобрать дату «%s», указана дата сообщения", row.Date.Raw()),
+			})
 		}
 
-		if !exists {
-			table[i].CultureYellow = true
-		}
+		table[i].Date = date
 
-		exists, err = m.repositories.InformationRepo.CheckOperation(ctx, row.Operation)
-		if err != nil {
-			log.Printf("failed to check operation: %v", err)
-		}
+		fillDefaults(&table[i], settings)
+	}
 
-		if !exists {
-			table[i].OperationYellow = true
-		}
+	table = m.normalizer.NormalizeTable(ctx, settings.ChatContextID, at, table)
 
-		exists, err = m.repositories.InformationRepo.CheckDivision(ctx, row.Division)
-		if err != nil {
-			log.Printf("failed to check division: %v", err)
-		}
+	table = m.measure.ConvertTable(ctx, at, table)
 
-		if !exists {
-			table[i].DivisionYellow = true
-		}
+	table = m.progress.Track(ctx, messageID, at, table)
+
+	return m.validator.ValidateTable(ctx, at, table)
+}
+
+// fillDefaults fills the values missing in the line with the defaults of the
+// chat context. The operation is filled only when a single one is expected.
+func fillDefaults(line *models.Line, settings models.ChatContextSettings) {
+	if strings.TrimSpace(line.Division) == "" {
+		line.Division = settings.DefaultDivision
+	}
+
+	if strings.TrimSpace(line.Culture) == "" {
+		line.Culture = settings.DefaultCulture
 	}
 
-	return table
+	if strings.TrimSpace(line.Operation) == "" && len(settings.ExpectedOperations) == 1 {
+		line.Operation = settings.ExpectedOperations[0]
+	}
 }
 
 func (m *Manager) AsyncProcessImageMessage(message models.ImageMessage) {
@@ -243,6 +338,12 @@ func (m *Manager) AsyncProcessImageMessage(message models.ImageMessage) {
 func (m *Manager) ProcessImageMessage(ctx context.Context, message models.ImageMessage) error {
 	log.Println("pre-processing image message")
 
+	// the uploads read the image in the background after the return
+	var uploads sync.WaitGroup
+	defer func() {
+		go removeMedia(&uploads, message.Image)
+	}()
+
 	workerID, err := m.GetWorkerID(ctx, message.TextMessage)
 	if err != nil {
 		return fmt.Errorf("failed to get worker ID: %w", err)
@@ -261,14 +362,43 @@ func (m *Manager) ProcessImageMessage(ctx context.Context, message models.ImageM
 		}
 	}
 
+	image, err := m.preprocessor.NormalizeImage(message.Image)
+	if errors.Is(err, preprocessor.ErrNotImage) || errors.Is(err, preprocessor.ErrUnsupportedImage) {
+		replyErr := m.replyToChat(ctx, chatID, imageRejectedReply)
+		if replyErr != nil {
+			log.Printf("failed to reply to chat: %v", replyErr)
+		}
+
+		return fmt.Errorf("image rejected: %w", err)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to normalize image: %w", err)
+	}
+	if image.Path != message.Image.Path {
+		defer func() {
+			err := image.Remove()
+			if err != nil {
+				log.Printf("failed to remove normalized image: %v", err)
+			}
+		}()
+	}
+
 	messageID, err := m.repositories.MessagesRepo.AddMessage(ctx, workerID, chatID, message.Timestamp, message.Text, "user")
 	if err != nil {
 		return fmt.Errorf("failed to add message: %w", err)
 	}
 
-	startedAt := m.reporter.RegisterReport(ctx, chatContextID, chatContextName, message.Timestamp)
+	report := m.reporter.RegisterReport(ctx, chatContextID, chatContextName, message.Timestamp)
+	startedAt := report.StartedAt
+
+	settings := m.getSettings(ctx, chatContextID)
 
+	m.linkMessageToReport(ctx, messageID, report.ID)
+
+	uploads.Add(1)
 	go func() {
+		defer uploads.Done()
+
 		chatIDs, err := m.repositories.ChatsRepo.GetChats(ctx, chatContextID)
 		if err != nil {
 			log.Println("failed to get chats: %w", err)
@@ -286,10 +416,9 @@ func (m *Manager) ProcessImageMessage(ctx context.Context, message models.ImageM
 
 		number := max(1, totalNumberOfMessages+totalNumberOfVerbiage)
 
-		mime := mimetype.Detect(message.Image)
-		postfix := mime.Extension()
+		postfix := message.Image.Extension
 
-		url, err := m.clients.Minio.UploadFile(ctx, models.GetFileName(message.Name, number, message.Timestamp, chatContextName, postfix), message.Image)
+		url, err := m.uploadMedia(ctx, models.GetFileName(message.Name, number, message.Timestamp, settings.Location(), chatContextName, postfix), message.Image)
 		if err != nil {
 			log.Println("failed to upload image to minio: %w", err)
 		}
@@ -302,7 +431,7 @@ func (m *Manager) ProcessImageMessage(ctx context.Context, message models.ImageM
 		}
 
 		// big latency here
-		err = m.clients.Googledrive.SaveMedia(ctx, models.GetFileName(message.Name, number, message.Timestamp, chatContextName, postfix), message.Image)
+		err = m.saveMedia(ctx, models.GetFileName(message.Name, number, message.Timestamp, settings.Location(), chatContextName, postfix), message.Image)
 		if err != nil {
 			log.Println("failed to save image to drive: %w", err)
 		}
@@ -310,21 +439,27 @@ func (m *Manager) ProcessImageMessage(ctx context.Context, message models.ImageM
 
 	log.Println("predicting image message")
 
-	table, err := m.clients.Apollo.PredictTableFromImage(ctx, message.Image)
+	imageFile, err := image.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open image: %w", err)
+	}
+	defer imageFile.Close()
+
+	table, err := m.clients.Apollo.PredictTableFromImage(ctx, imageFile, settings.RecognitionHints())
 	if err != nil {
 		return fmt.Errorf("failed to predict image message: %w", err)
 	}
 
-	table = m.fillTable(ctx, table)
+	table = m.fillTable(ctx, settings, messageID, message.Timestamp, table)
 
-	err = m.repositories.ReportsRepo.AddTable(ctx, messageID, time.Now(), table)
+	err = m.repositories.ReportsRepo.AddTable(ctx, report.ID, messageID, time.Now(), table)
 	if err != nil {
 		return fmt.Errorf("failed to add table: %w", err)
 	}
 
 	// big latency here and sync operation with mutex
 	// no need to go in the end of function
-	err = m.clients.Googledrive.SaveTable(ctx, models.GetTableName(startedAt, chatContextName), table)
+	err = m.clients.Googledrive.SaveTable(ctx, models.GetTableName(startedAt, report.Location(), chatContextName), table)
 	if err != nil {
 		return fmt.Errorf("failed to save table to drive: %w", err)
 	}
@@ -332,6 +467,132 @@ func (m *Manager) ProcessImageMessage(ctx context.Context, message models.ImageM
 	return nil
 }
 
+// parseFixCommand parses "/fix <raw value> = <dictionary value>".
+func parseFixCommand(text string) (string, string, bool) {
+	text, ok := strings.CutPrefix(strings.TrimSpace(text), fixCommand)
+	if !ok {
+		return "", "", false
+	}
+
+	raw, value, ok := strings.Cut(text, "=")
+	raw, value = strings.TrimSpace(raw), strings.TrimSpace(value)
+	if !ok || raw == "" || value == "" {
+		return "", "", false
+	}
+
+	return raw, value, true
+}
+
+func (m *Manager) processFixCommand(ctx context.Context, message models.TextMessage, raw string, value string) error {
+	chatID, chatContextID, err := m.repositories.ChatsRepo.GetChatInfo(ctx, message.ChatName)
+	if err != nil {
+		return fmt.Errorf("failed to get chat ID: %w", err)
+	}
+
+	reply := ""
+
+	alias, err := m.normalizer.LearnCorrection(ctx, raw, value, chatContextID, models.AliasSourceBot)
+	switch {
+	case errors.Is(err, information.ErrUnknownValue):
+		reply = fmt.Sprintf("Значение «%s» не найдено в справочниках.", value)
+	case err != nil:
+		return fmt.Errorf("failed to learn correction: %w", err)
+	default:
+		reply = fmt.Sprintf("Запомнил: «%s» → «%s» для этого чата.", alias.Alias, alias.Value)
+	}
+
+	return m.replyToChat(ctx, chatID, reply)
+}
+
+func (m *Manager) processReviewCommand(ctx context.Context, message models.TextMessage, command review.Command) error {
+	workerID, err := m.GetWorkerID(ctx, message)
+	if err != nil {
+		return fmt.Errorf("failed to get worker ID: %w", err)
+	}
+
+	chatID, chatContextID, err := m.repositories.ChatsRepo.GetChatInfo(ctx, message.ChatName)
+	if err != nil {
+		return fmt.Errorf("failed to get chat ID: %w", err)
+	}
+
+	reply, err := m.review.Handle(ctx, workerID, chatContextID, command)
+	if err != nil {
+		return fmt.Errorf("failed to handle review command: %w", err)
+	}
+
+	return m.replyToChat(ctx, chatID, reply)
+}
+
+func (m *Manager) replyToChat(ctx context.Context, chatID int, text string) error {
+	chatType, chatName, err := m.repositories.ChatsRepo.GetChatType(ctx, chatID)
+	if err != nil {
+		return fmt.Errorf("failed to get chat type: %w", err)
+	}
+
+	switch chatType {
+	case "whatsapp":
+		return m.clients.Whatsapp.SendText(ctx, chatName, text)
+	case "telegram":
+		return m.clients.Telegram.SendText(ctx, chatName, text)
+	}
+
+	return fmt.Errorf("unknown chat type: %s", chatType)
+}
+
+func (m *Manager) audioRejectedReply(info preprocessor.AudioInfo, err error) string {
+	switch {
+	case errors.Is(err, preprocessor.ErrAudioTooLong):
+		return fmt.Sprintf(
+			"Голосовое сообщение слишком длинное (%s). Максимальная длительность — %s, разделите отчёт на несколько сообщений.",
+			formatDuration(info.Duration), formatDuration(m.preprocessor.AudioMaxDuration()),
+		)
+	case errors.Is(err, preprocessor.ErrAudioTooLarge):
+		return fmt.Sprintf(
+			"Аудиофайл слишком большой (%.1f МБ). Максимальный размер — %.1f МБ.",
+			float64(info.Size)/(1<<20), float64(m.preprocessor.AudioMaxSize())/(1<<20),
+		)
+	}
+
+	return "Не удалось распознать формат аудио: поддерживаются MP3, WAV и OGG."
+}
+
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	return fmt.Sprintf("%d:%02d", int(d.Minutes()), int(d.Seconds())%60)
+}
+
+// uploadMedia stores the media in Minio and returns its URL.
+func (m *Manager) uploadMedia(ctx context.Context, fileName string, media models.Media) (string, error) {
+	file, err := media.Open()
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	return m.clients.Minio.UploadFile(ctx, fileName, file)
+}
+
+// saveMedia stores the media in the Drive folder.
+func (m *Manager) saveMedia(ctx context.Context, fileName string, media models.Media) error {
+	file, err := media.Open()
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return m.clients.Googledrive.SaveMedia(ctx, fileName, media.MIME, file)
+}
+
+// removeMedia removes the spooled media once the uploads have read it.
+func removeMedia(uploads *sync.WaitGroup, media models.Media) {
+	uploads.Wait()
+
+	err := media.Remove()
+	if err != nil {
+		log.Printf("failed to remove media: %v", err)
+	}
+}
+
 func (m *Manager) AsyncProcessAudioMessage(message models.AudioMessage) {
 	err := m.ProcessAudioMessage(m.shutdownCtx, message)
 	if err != nil {
@@ -342,6 +603,12 @@ func (m *Manager) AsyncProcessAudioMessage(message models.AudioMessage) {
 func (m *Manager) ProcessAudioMessage(ctx context.Context, message models.AudioMessage) error {
 	log.Println("pre-processing audio message")
 
+	// the uploads read the audio in the background after the return
+	var uploads sync.WaitGroup
+	defer func() {
+		go removeMedia(&uploads, message.Audio)
+	}()
+
 	workerID, err := m.GetWorkerID(ctx, message.TextMessage)
 	if err != nil {
 		return fmt.Errorf("failed to get worker ID: %w", err)
@@ -360,14 +627,32 @@ func (m *Manager) ProcessAudioMessage(ctx context.Context, message models.AudioM
 		}
 	}
 
+	audioInfo, err := m.preprocessor.InspectAudio(message.Audio)
+	if err != nil {
+		replyErr := m.replyToChat(ctx, chatID, m.audioRejectedReply(audioInfo, err))
+		if replyErr != nil {
+			log.Printf("failed to reply to chat: %v", replyErr)
+		}
+
+		return fmt.Errorf("audio rejected: %w", err)
+	}
+
 	messageID, err := m.repositories.MessagesRepo.AddMessage(ctx, workerID, chatID, message.Timestamp, message.Text, "user")
 	if err != nil {
 		return fmt.Errorf("failed to add message: %w", err)
 	}
 
-	startedAt := m.reporter.RegisterReport(ctx, chatContextID, chatContextName, message.Timestamp)
+	report := m.reporter.RegisterReport(ctx, chatContextID, chatContextName, message.Timestamp)
+	startedAt := report.StartedAt
+
+	settings := m.getSettings(ctx, chatContextID)
 
+	m.linkMessageToReport(ctx, messageID, report.ID)
+
+	uploads.Add(1)
 	go func() {
+		defer uploads.Done()
+
 		chatIDs, err := m.repositories.ChatsRepo.GetChats(ctx, chatContextID)
 		if err != nil {
 			log.Println("failed to get chats: %w", err)
@@ -385,10 +670,9 @@ func (m *Manager) ProcessAudioMessage(ctx context.Context, message models.AudioM
 
 		number := max(1, totalNumberOfMessages+totalNumberOfVerbiage)
 
-		mime := mimetype.Detect(message.Audio)
-		postfix := mime.Extension()
+		postfix := message.Audio.Extension
 
-		url, err := m.clients.Minio.UploadFile(ctx, models.GetFileName(message.Name, number, message.Timestamp, chatContextName, postfix), message.Audio)
+		url, err := m.uploadMedia(ctx, models.GetFileName(message.Name, number, message.Timestamp, settings.Location(), chatContextName, postfix), message.Audio)
 		if err != nil {
 			log.Println("failed to upload audio to minio: %w", err)
 		}
@@ -401,7 +685,7 @@ func (m *Manager) ProcessAudioMessage(ctx context.Context, message models.AudioM
 		}
 
 		// big latency here
-		err = m.clients.Googledrive.SaveMedia(ctx, models.GetFileName(message.Name, number, message.Timestamp, chatContextName, postfix), message.Audio)
+		err = m.saveMedia(ctx, models.GetFileName(message.Name, number, message.Timestamp, settings.Location(), chatContextName, postfix), message.Audio)
 		if err != nil {
 			log.Println("failed to save audio to drive: %w", err)
 		}
@@ -409,7 +693,13 @@ func (m *Manager) ProcessAudioMessage(ctx context.Context, message models.AudioM
 
 	log.Println("predicting audio message")
 
-	text, err := m.clients.Apollo.PredictTextFromAudio(ctx, message.Audio)
+	audioFile, err := message.Audio.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open audio: %w", err)
+	}
+	defer audioFile.Close()
+
+	text, err := m.clients.Apollo.PredictTextFromAudio(ctx, audioFile)
 	if err != nil {
 		return fmt.Errorf("failed to predict audio message: %w", err)
 	}
@@ -419,6 +709,12 @@ func (m *Manager) ProcessAudioMessage(ctx context.Context, message models.AudioM
 		log.Println("failed to update message: %w", err)
 	}
 
+	cost := audioInfo.Duration.Minutes() * m.transcriptionCostPerMinute
+	err = m.repositories.MessagesRepo.SetTranscription(ctx, messageID, audioInfo.Duration, audioInfo.Codec, cost)
+	if err != nil {
+		log.Printf("failed to set transcription: %v", err)
+	}
+
 	if m.filterVerbiage {
 		isVerbiage, err := m.clients.Apollo.CheckVerbiage(ctx, text)
 		if err != nil {
@@ -429,25 +725,25 @@ func (m *Manager) ProcessAudioMessage(ctx context.Context, message models.AudioM
 		}
 	}
 
-	table, err := m.clients.Apollo.PredictTableFromText(ctx, text)
+	table, err := m.clients.Apollo.PredictTableFromText(ctx, text, settings.RecognitionHints())
 	if err != nil {
 		return fmt.Errorf("failed to predict table from text: %w", err)
 	}
 
-	table = m.fillTable(ctx, table)
+	table = m.fillTable(ctx, settings, messageID, message.Timestamp, table)
 
 	fmt.Println("table", table)
 	fmt.Println("len", len(table))
 	fmt.Println("text", text)
 
-	err = m.repositories.ReportsRepo.AddTable(ctx, messageID, time.Now(), table)
+	err = m.repositories.ReportsRepo.AddTable(ctx, report.ID, messageID, time.Now(), table)
 	if err != nil {
 		log.Println("failed to add table: %w", err)
 	}
 
 	// big latency here and sync operation with mutex
 	// no need to go in the end of function
-	err = m.clients.Googledrive.SaveTable(ctx, models.GetTableName(startedAt, chatContextName), table)
+	err = m.clients.Googledrive.SaveTable(ctx, models.GetTableName(startedAt, report.Location(), chatContextName), table)
 	if err != nil {
 		return fmt.Errorf("failed to save table to drive: %w", err)
 	}
@@ -0,0 +1,324 @@
+package reminder
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/clients"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/managers/reporter"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/repositories"
+)
+
+type Config struct {
+	// due reminders are checked every Interval seconds
+	Interval int `json:"REMINDER_INTERVAL_SECONDS" cfgDefault:"60"`
+}
+
+func NewManager(cfg Config, clients *clients.Clients, repositories *repositories.Repositories, reporter *reporter.Manager) *Manager {
+	return &Manager{
+		clients:      clients,
+		repositories: repositories,
+		reporter:     reporter,
+		interval:     time.Duration(cfg.Interval) * time.Second,
+	}
+}
+
+// Manager reminds the expected reporters of the chat contexts who have not
+// reported before the cutoff and tells the listeners of the chats about them
+// at the second deadline after it. A reminder of a cutoff is sent once among
+// the instances.
+type Manager struct {
+	clients      *clients.Clients
+	repositories *repositories.Repositories
+
+	reporter *reporter.Manager
+
+	interval time.Duration
+}
+
+// missingWorker is a worker who has not reported with the divisions expected from them.
+type missingWorker struct {
+	contact   models.Contact
+	divisions []string
+}
+
+// Start sends the due reminders, then sends them in the background until the
+// context is done.
+func (m *Manager) Start(ctx context.Context) {
+	m.sendDueReminders(ctx)
+
+	if m.interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.sendDueReminders(ctx)
+			}
+		}
+	}()
+}
+
+func (m *Manager) sendDueReminders(ctx context.Context) {
+	chatContexts, err := m.repositories.ChatsRepo.ListChatContexts(ctx)
+	if err != nil {
+		log.Printf("failed to list chat contexts: %v", err)
+		return
+	}
+
+	now := time.Now()
+
+	for _, chatContext := range chatContexts {
+		settings := chatContext.Settings
+		if settings.RemindBefore == nil && settings.EscalateAfter == nil {
+			continue
+		}
+
+		schedule, loc := m.reporter.Schedule(ctx, chatContext.ID)
+
+		from, to, ok := schedule.Window(now.In(loc))
+		if !ok {
+			continue
+		}
+
+		if settings.RemindBefore != nil && !now.Before(to.Add(-time.Duration(*settings.RemindBefore)*time.Minute)) {
+			err = m.send(ctx, chatContext, models.ReminderRemind, from, to, now)
+			if err != nil {
+				log.Printf("failed to remind reporters of chat context %d: %v", chatContext.ID, err)
+			}
+		}
+
+		if settings.EscalateAfter != nil {
+			err = m.sendEscalation(ctx, chatContext, schedule, from, time.Duration(*settings.EscalateAfter)*time.Minute, now)
+			if err != nil {
+				log.Printf("failed to escalate missing reports of chat context %d: %v", chatContext.ID, err)
+			}
+		}
+	}
+}
+
+// sendEscalation escalates the last cutoff whose second deadline, after the
+// cutoff, has passed. The reports sent after the cutoff but before the
+// deadline are counted.
+func (m *Manager) sendEscalation(ctx context.Context, chatContext models.ChatContext, schedule models.Schedule, cutoff time.Time, after time.Duration, now time.Time) error {
+	from, _, ok := schedule.Window(cutoff.Add(-time.Nanosecond))
+	for ok && now.Before(cutoff.Add(after)) {
+		cutoff = from
+		from, _, ok = schedule.Window(cutoff.Add(-time.Nanosecond))
+	}
+	if !ok {
+		return nil
+	}
+
+	return m.send(ctx, chatContext, models.ReminderEscalate, from, cutoff, now)
+}
+
+// send sends the reminder of the cutoff when some expected reporters have
+// not reported since the previous one.
+func (m *Manager) send(ctx context.Context, chatContext models.ChatContext, kind models.ReminderKind, from, cutoff time.Time, now time.Time) error {
+	reporters, err := m.repositories.RemindersRepo.GetMissingReporters(ctx, chatContext.ID, from)
+	if err != nil {
+		return err
+	}
+	if len(reporters) == 0 {
+		return nil
+	}
+
+	missing := groupByWorker(reporters)
+
+	workerIDs := make([]int, 0, len(missing))
+	for _, worker := range missing {
+		workerIDs = append(workerIDs, worker.contact.WorkerID)
+	}
+
+	claimed, err := m.repositories.RemindersRepo.ClaimReminder(ctx, chatContext.ID, cutoff, kind, workerIDs)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return nil
+	}
+
+	log.Printf("sending %s reminder of chat context %d: %d workers have not reported", kind, chatContext.ID, len(missing))
+
+	if kind == models.ReminderEscalate {
+		return m.escalate(ctx, chatContext, cutoff, missing, now)
+	}
+
+	return m.remind(ctx, chatContext, cutoff, missing, now)
+}
+
+// remind writes to the workers directly, the workers who can not be written
+// to are mentioned in the chats of the context.
+func (m *Manager) remind(ctx context.Context, chatContext models.ChatContext, cutoff time.Time, missing []missingWorker, now time.Time) error {
+	var unreached []missingWorker
+
+	for _, worker := range missing {
+		text := fmt.Sprintf("Напоминание: пришлите отчёт в «%s» до %s.", chatContext.Name, formatCutoff(cutoff, now))
+		if len(worker.divisions) > 0 {
+			text = fmt.Sprintf("Напоминание: пришлите отчёт по %s в «%s» до %s.", strings.Join(worker.divisions, ", "), chatContext.Name, formatCutoff(cutoff, now))
+		}
+
+		if !m.sendDirect(ctx, worker.contact, text) {
+			unreached = append(unreached, worker)
+		}
+	}
+
+	if len(unreached) == 0 {
+		return nil
+	}
+
+	return m.mentionInChats(ctx, chatContext.ID, fmt.Sprintf("Ждём отчёты до %s:", formatCutoff(cutoff, now)), unreached)
+}
+
+// escalate tells the listeners of the chats who has not reported, the workers
+// are mentioned in the chats when no listener can be written to.
+func (m *Manager) escalate(ctx context.Context, chatContext models.ChatContext, cutoff time.Time, missing []missingWorker, now time.Time) error {
+	listeners, err := m.repositories.WorkersRepo.GetListeners(ctx, chatContext.ID)
+	if err != nil {
+		return err
+	}
+
+	lines := make([]string, 0, len(missing))
+	for _, worker := range missing {
+		lines = append(lines, "— "+worker.contact.WorkerName+divisionsSuffix(worker.divisions))
+	}
+
+	text := fmt.Sprintf("К %s нет отчётов в «%s»:\n%s", formatCutoff(cutoff, now), chatContext.Name, strings.Join(lines, "\n"))
+
+	reached := false
+	for _, listener := range listeners {
+		if m.sendDirect(ctx, listener, text) {
+			reached = true
+		}
+	}
+
+	if reached {
+		return nil
+	}
+
+	return m.mentionInChats(ctx, chatContext.ID, fmt.Sprintf("К %s нет отчётов:", formatCutoff(cutoff, now)), missing)
+}
+
+// sendDirect writes to the first account of the worker that accepts the
+// message, it is false when none does.
+func (m *Manager) sendDirect(ctx context.Context, contact models.Contact, text string) bool {
+	for _, whatsappID := range contact.WhatsappIDs {
+		err := m.clients.Whatsapp.SendText(ctx, whatsappID, text)
+		if err == nil {
+			return true
+		}
+		log.Printf("failed to write to worker %d in WhatsApp: %v", contact.WorkerID, err)
+	}
+
+	for _, telegramID := range contact.TelegramIDs {
+		// the bot can only write to the users who started it
+		err := m.clients.Telegram.SendText(ctx, telegramID, text)
+		if err == nil {
+			return true
+		}
+		log.Printf("failed to write to worker %d in Telegram: %v", contact.WorkerID, err)
+	}
+
+	return false
+}
+
+// mentionInChats sends the title and the mentions of the workers to the chats of the context.
+func (m *Manager) mentionInChats(ctx context.Context, chatContextID int, title string, workers []missingWorker) error {
+	chats, err := m.repositories.ChatsRepo.GetChats(ctx, chatContextID)
+	if err != nil {
+		return fmt.Errorf("failed to get chats: %w", err)
+	}
+
+	for _, chatID := range chats {
+		chatType, chatName, err := m.repositories.ChatsRepo.GetChatType(ctx, chatID)
+		if err != nil {
+			return fmt.Errorf("failed to get chat type: %w", err)
+		}
+
+		switch chatType {
+		case "whatsapp":
+			lines := []string{title}
+			mentioned := make([]string, 0, len(workers))
+			for _, worker := range workers {
+				name := worker.contact.WorkerName
+				if len(worker.contact.WhatsappIDs) > 0 {
+					tag, jid := models.WhatsappMention(worker.contact.WhatsappIDs[0])
+					name = tag
+					mentioned = append(mentioned, jid)
+				}
+				lines = append(lines, name+divisionsSuffix(worker.divisions))
+			}
+
+			err = m.clients.Whatsapp.SendMentions(ctx, chatName, strings.Join(lines, "\n"), mentioned)
+		case "telegram":
+			lines := []string{html.EscapeString(title)}
+			for _, worker := range workers {
+				name := html.EscapeString(worker.contact.WorkerName)
+				if len(worker.contact.TelegramIDs) > 0 {
+					name = models.TelegramMention(worker.contact.TelegramIDs[0], name)
+				}
+				lines = append(lines, name+html.EscapeString(divisionsSuffix(worker.divisions)))
+			}
+
+			err = m.clients.Telegram.SendReport(ctx, chatName, strings.Join(lines, "\n"))
+		default:
+			err = fmt.Errorf("unknown chat type %q", chatType)
+		}
+		if err != nil {
+			log.Printf("failed to send reminder to chat %d: %v", chatID, err)
+		}
+	}
+
+	return nil
+}
+
+// groupByWorker joins the expected reporters of a worker, keeping the order.
+func groupByWorker(reporters []models.ExpectedReporter) []missingWorker {
+	missing := make([]missingWorker, 0, len(reporters))
+	index := make(map[int]int)
+
+	for _, reporter := range reporters {
+		i, ok := index[reporter.WorkerID]
+		if !ok {
+			i = len(missing)
+			index[reporter.WorkerID] = i
+			missing = append(missing, missingWorker{contact: reporter.Contact})
+		}
+
+		if reporter.Division != "" {
+			missing[i].divisions = append(missing[i].divisions, reporter.Division)
+		}
+	}
+
+	return missing
+}
+
+func divisionsSuffix(divisions []string) string {
+	if len(divisions) == 0 {
+		return ""
+	}
+
+	return " (" + strings.Join(divisions, ", ") + ")"
+}
+
+// formatCutoff formats the cutoff with the date when it is not today.
+func formatCutoff(cutoff time.Time, now time.Time) string {
+	if cutoff.Format("02.01.2006") == now.In(cutoff.Location()).Format("02.01.2006") {
+		return cutoff.Format("15:04")
+	}
+
+	return cutoff.Format("02.01 15:04")
+}
@@ -2,38 +2,87 @@ package reporter
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"log"
+	"os"
 	"sync"
 	"time"
 
 	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/clients"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/managers/completeness"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/managers/export"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/managers/normalizer"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/managers/summary"
 	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
 	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/repositories"
+	"github.com/lild1tz/llm_coding_challenge/backend/libs/go/loctime"
 )
 
 type Config struct {
 	ResponseTimeout int `json:"RESPONSE_TIMEOUT" cfgDefault:"15"`
 
+	// reports of chat contexts without a schedule are closed every day at FinishHour
 	FinishHour int `json:"FINISH_HOUR" cfgDefault:"9"`
+
+	// due reports are closed by the scheduler every SchedulerInterval seconds
+	SchedulerInterval int `json:"REPORT_SCHEDULER_INTERVAL_SECONDS" cfgDefault:"30"`
+
+	// the same flag as the recognizer's, the table name of the report depends on it
+	AddChatContextName bool `json:"ADD_CHAT_CONTEXT_NAME" cfgDefault:"true"`
+
+	// InstanceID identifies the instance in report leases, hostname and pid by default
+	InstanceID string `json:"INSTANCE_ID"`
+	// an instance that stops renewing its leases loses them after LeaseSeconds
+	LeaseSeconds int `json:"REPORT_LEASE_SECONDS" cfgDefault:"60"`
 }
 
-func NewManager(shutdownCtx context.Context, cfg Config, clients *clients.Clients, repositories *repositories.Repositories) *Manager {
+func NewManager(
+	shutdownCtx context.Context,
+	cfg Config,
+	clients *clients.Clients,
+	repositories *repositories.Repositories,
+	summary *summary.Manager,
+	export *export.Manager,
+	completeness *completeness.Manager,
+	normalizer *normalizer.Manager,
+) *Manager {
+	instanceID := cfg.InstanceID
+	if instanceID == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			hostname = "hermes"
+		}
+		instanceID = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+
 	return &Manager{
 		shutdownCtx:  shutdownCtx,
 		clients:      clients,
 		repositories: repositories,
+		summary:      summary,
+		export:       export,
+		completeness: completeness,
+		normalizer:   normalizer,
 		chatsMux:     sync.Mutex{},
 		chats:        make(map[int]ReportChannel),
 		timeout:      cfg.ResponseTimeout,
-		finishHour:   cfg.FinishHour,
+		schedule:     models.DailySchedule(cfg.FinishHour),
+
+		schedulerInterval:  time.Duration(cfg.SchedulerInterval) * time.Second,
+		addChatContextName: cfg.AddChatContextName,
+
+		instanceID: instanceID,
+		leaseTTL:   time.Duration(cfg.LeaseSeconds) * time.Second,
 	}
 }
 
 // Manager is a manager for the reporter.
 // It is responsible for reporting the chats to the clients.
-// To scale create a new microservice that will be responsible for reporting the chats to the clients.
+// Several instances can run at once: a chat context has one open report, the
+// instance holding the report lease of the chat context notifies the chats
+// and reports are finished only once.
 type Manager struct {
 	shutdownCtx context.Context
 
@@ -41,11 +90,23 @@ type Manager struct {
 
 	repositories *repositories.Repositories
 
+	summary      *summary.Manager
+	export       *export.Manager
+	completeness *completeness.Manager
+	normalizer   *normalizer.Manager
+
 	chatsMux sync.Mutex
 	chats    map[int]ReportChannel
 
-	timeout    int
-	finishHour int
+	timeout int
+	// schedule is used for chat contexts without one
+	schedule models.Schedule
+
+	schedulerInterval  time.Duration
+	addChatContextName bool
+
+	instanceID string
+	leaseTTL   time.Duration
 }
 
 type ReportChannel struct {
@@ -55,21 +116,49 @@ type ReportChannel struct {
 	chatContextName string
 }
 
-func (m *Manager) RegisterReport(ctx context.Context, chatContextID int, chatContextName string, sendedAt time.Time) time.Time {
+// RegisterReport adds the message to the open report of the chat context,
+// the report is started when there is none. The returned report has no ID
+// when it could not be saved.
+func (m *Manager) RegisterReport(ctx context.Context, chatContextID int, chatContextName string, sendedAt time.Time) models.Report {
 	if ctx.Err() != nil {
-		return time.Now()
+		return models.Report{ChatContextID: chatContextID, StartedAt: time.Now()}
 	}
 
+	// the reports found due are closed outside the lock, the chats are
+	// notified of them over the network
+	var due []models.Report
+	defer func() {
+		if len(due) > 0 {
+			go m.closeReports(m.shutdownCtx, due)
+		}
+	}()
+
 	m.chatsMux.Lock()
 	chatContext, ok := m.chats[chatContextID]
 	if !ok {
-		report, ok, err := m.tryToGetReport(ctx, chatContextID)
+		var report models.Report
+		var err error
+		report, due, err = m.getOrCreateReport(ctx, chatContextID, sendedAt)
 		if err != nil {
-			log.Printf("failed to get report: %v", err)
+			m.chatsMux.Unlock()
+			log.Printf("failed to register report: %v", err)
+			return report
 		}
 
-		if !ok || err != nil {
-			report = models.Report{ChatContextID: chatContextID, StartedAt: sendedAt, LastUpdatedAt: sendedAt}
+		owned, err := m.repositories.ReportsRepo.AcquireLease(ctx, chatContextID, m.instanceID, m.leaseTTL)
+		if err != nil {
+			log.Printf("failed to acquire report lease: %v", err)
+		}
+		if !owned {
+			m.chatsMux.Unlock()
+
+			// another instance processes the chat context
+			err = m.repositories.ReportsRepo.UpdateReport(ctx, report.ID, sendedAt)
+			if err != nil {
+				log.Printf("failed to update report: %v", err)
+			}
+
+			return report
 		}
 
 		chatContext = ReportChannel{
@@ -91,41 +180,82 @@ func (m *Manager) RegisterReport(ctx context.Context, chatContextID int, chatCon
 	select {
 	case chatContext.messageEvent <- sendedAt:
 	case <-m.shutdownCtx.Done():
-		return time.Now()
+		return models.Report{ChatContextID: chatContextID, StartedAt: time.Now()}
 	case <-ctx.Done():
-		return time.Now()
+		return models.Report{ChatContextID: chatContextID, StartedAt: time.Now()}
 	}
 
-	return chatContext.report.StartedAt
+	return chatContext.report
 }
 
-func (m *Manager) processChatReport(ctx context.Context, chatContext ReportChannel) error {
-	if chatContext.report.ID == 0 {
-		log.Printf("report not found, creating new report")
-		reportID, err := m.repositories.ReportsRepo.CreateReport(ctx, chatContext.report)
-		if err != nil {
-			return fmt.Errorf("failed to create report: %w", err)
-		}
+// getOrCreateReport returns the open report of the chat context, a new one
+// when there is none, and the reports that are due to be closed.
+func (m *Manager) getOrCreateReport(ctx context.Context, chatContextID int, sendedAt time.Time) (models.Report, []models.Report, error) {
+	report, ok, due, err := m.tryToGetReport(ctx, chatContextID)
+	if err != nil {
+		log.Printf("failed to get report: %v", err)
+	}
 
-		chatContext.report.ID = reportID
+	if ok && err == nil {
+		return report, due, nil
 	}
 
-	needToFinish := m.processMessages(ctx, chatContext)
+	log.Printf("report not found, creating new report")
+
+	schedule, loc := m.getSchedule(ctx, chatContextID)
+
+	report = models.Report{ChatContextID: chatContextID, StartedAt: sendedAt, LastUpdatedAt: sendedAt, TimeZone: loc.String()}
+	report.ClosesAt = closesAt(schedule, loc, sendedAt)
+
+	created, err := m.repositories.ReportsRepo.CreateReport(ctx, report)
+	if err != nil {
+		return report, due, fmt.Errorf("failed to create report: %w", err)
+	}
 
-	if needToFinish {
-		err := m.repositories.ReportsRepo.FinishReport(context.Background(), chatContext.report.ID, time.Now())
+	return created, due, nil
+}
+
+func (m *Manager) processChatReport(ctx context.Context, chatContext ReportChannel) error {
+	needToFinish, owned := m.processMessages(ctx, chatContext)
+
+	// the chats are notified only of the report finished here, an open report
+	// is left for the next messages and the scheduler of some instance may
+	// have closed the report and notified the chats already
+	notify := false
+	if owned && needToFinish {
+		chatContext.report.Status = m.finishStatus(context.Background(), chatContext.report.ChatContextID)
+
+		finished, err := m.repositories.ReportsRepo.FinishReport(context.Background(), chatContext.report.ID, time.Now(), chatContext.report.Status)
 		if err != nil {
 			log.Printf("failed to finish report: %v", err)
 		}
+
+		notify = err == nil && finished
+		if notify {
+			m.checkCompleteness(context.Background(), chatContext.report.ID)
+		}
 	}
 
 	m.chatsMux.Lock()
 	delete(m.chats, chatContext.report.ChatContextID)
 	m.chatsMux.Unlock()
 
+	if owned {
+		err := m.repositories.ReportsRepo.ReleaseLease(context.Background(), chatContext.report.ChatContextID, m.instanceID)
+		if err != nil {
+			log.Printf("failed to release report lease: %v", err)
+		}
+	}
+
 	m.moveMessagesToNewReport(ctx, chatContext)
 
-	err := m.notifyChats(context.Background(), chatContext)
+	if !notify {
+		return nil
+	}
+
+	m.learnSheetEdits(context.Background(), chatContext.report, chatContext.chatContextName)
+
+	err := m.notifyChats(context.Background(), chatContext.report, chatContext.chatContextName)
 	if err != nil {
 		return fmt.Errorf("failed to notify chats: %w", err)
 	}
@@ -133,20 +263,21 @@ func (m *Manager) processChatReport(ctx context.Context, chatContext ReportChann
 	return nil
 }
 
-func (m *Manager) tryToGetReport(ctx context.Context, chatContextID int) (models.Report, bool, error) {
+// tryToGetReport returns the open report of the chat context and the ones
+// past their cutoff, which the caller closes.
+func (m *Manager) tryToGetReport(ctx context.Context, chatContextID int) (models.Report, bool, []models.Report, error) {
 	reports, err := m.repositories.ReportsRepo.GetNotFinishedReports(ctx, chatContextID)
 	if err != nil {
-		return models.Report{}, false, fmt.Errorf("failed to get reports: %w", err)
+		return models.Report{}, false, nil, fmt.Errorf("failed to get reports: %w", err)
 	}
 
-	var notFinishedReports []models.Report
+	var notFinishedReports, due []models.Report
 
 	for _, report := range reports {
-		if report.IsNeedToFinish(m.finishHour) {
-			err = m.repositories.ReportsRepo.FinishReport(ctx, report.ID, time.Now())
-			if err != nil {
-				log.Printf("failed to finish report: %v", err)
-			}
+		m.fillClosesAt(ctx, &report)
+
+		if report.IsNeedToFinish(time.Now()) {
+			due = append(due, report)
 			continue
 		}
 
@@ -154,40 +285,130 @@ func (m *Manager) tryToGetReport(ctx context.Context, chatContextID int) (models
 	}
 
 	if len(notFinishedReports) == 0 {
-		return models.Report{}, false, nil
+		return models.Report{}, false, due, nil
 	}
 
 	if len(notFinishedReports) > 1 {
 		for i := 1; i < len(notFinishedReports); i++ {
 			log.Printf("finishing report: %d", notFinishedReports[i].ID)
-			err = m.repositories.ReportsRepo.FinishReport(ctx, notFinishedReports[i].ID, time.Now())
+			finished, err := m.repositories.ReportsRepo.FinishReport(ctx, notFinishedReports[i].ID, time.Now(), m.finishStatus(ctx, chatContextID))
 			if err != nil {
 				log.Printf("failed to finish report: %v", err)
 			}
+			if finished {
+				m.checkCompleteness(ctx, notFinishedReports[i].ID)
+			}
 		}
 	}
 
 	log.Printf("report found, returning report ID: %d", notFinishedReports[0].ID)
-	return notFinishedReports[0], true, nil
+	return notFinishedReports[0], true, due, nil
 }
 
-func (m *Manager) processMessages(ctx context.Context, chatContext ReportChannel) bool {
+// processMessages waits for the messages of the report until the chat is
+// silent for the timeout or the cutoff of the report passes, the lease of the
+// chat context is renewed meanwhile. It returns whether the report is to be
+// finished and whether the lease is still held.
+func (m *Manager) processMessages(ctx context.Context, chatContext ReportChannel) (bool, bool) {
+	var cutoff <-chan time.Time
+	if chatContext.report.ClosesAt != nil {
+		cutoff = time.After(time.Until(*chatContext.report.ClosesAt))
+	}
+
+	renew := time.NewTicker(max(m.leaseTTL/3, time.Second))
+	defer renew.Stop()
+
+	// the silence is counted from the last message, the renewals do not restart it
+	timeout := time.Duration(m.timeout) * time.Second
+	silence := time.NewTimer(timeout)
+	defer silence.Stop()
+
 	for {
 		select {
 		case messageTime := <-chatContext.messageEvent:
+			silence.Reset(timeout)
+
 			err := m.repositories.ReportsRepo.UpdateReport(ctx, chatContext.report.ID, messageTime)
 			if err != nil {
 				log.Printf("failed to update report: %v", err)
 			}
-		case <-time.After(time.Duration(m.timeout) * time.Second):
+		case <-silence.C:
 			log.Println("chat report timeout")
-			return chatContext.report.IsNeedToFinish(m.finishHour)
+			return chatContext.report.IsNeedToFinish(time.Now()), true
+		case <-cutoff:
+			log.Printf("report %d reached its cutoff", chatContext.report.ID)
+			return true, true
+		case <-renew.C:
+			owned, err := m.repositories.ReportsRepo.AcquireLease(ctx, chatContext.report.ChatContextID, m.instanceID, m.leaseTTL)
+			if err != nil {
+				log.Printf("failed to renew report lease: %v", err)
+				continue
+			}
+			if !owned {
+				log.Printf("report lease of chat context %d is taken over", chatContext.report.ChatContextID)
+				return false, false
+			}
 		case <-ctx.Done():
-			return false
+			return false, true
 		}
 	}
 }
 
+// Schedule returns the schedule and the time zone of the chat context the
+// reports are closed by.
+func (m *Manager) Schedule(ctx context.Context, chatContextID int) (models.Schedule, *time.Location) {
+	return m.getSchedule(ctx, chatContextID)
+}
+
+// getSchedule returns the schedule and the time zone of the chat context,
+// the defaults when the context has none or they are invalid.
+func (m *Manager) getSchedule(ctx context.Context, chatContextID int) (models.Schedule, *time.Location) {
+	settings, err := m.repositories.ChatsRepo.GetSettings(ctx, chatContextID)
+	if err != nil {
+		log.Printf("failed to get chat context settings: %v", err)
+		return m.schedule, loctime.Default()
+	}
+
+	schedule, err := models.ParseSchedule(settings.ReportSchedule)
+	if errors.Is(err, models.ErrScheduleEmpty) {
+		return m.schedule, settings.Location()
+	}
+	if err != nil {
+		log.Printf("failed to parse schedule of chat context %d: %v", chatContextID, err)
+		return m.schedule, settings.Location()
+	}
+
+	return schedule, settings.Location()
+}
+
+// finishStatus returns the status of the closed report: it waits for the
+// review when the chat context requires one.
+func (m *Manager) finishStatus(ctx context.Context, chatContextID int) models.ReportStatus {
+	settings, err := m.repositories.ChatsRepo.GetSettings(ctx, chatContextID)
+	if err != nil {
+		log.Printf("failed to get chat context settings: %v", err)
+		return models.StatusApproved
+	}
+
+	if settings.ReviewRequired {
+		return models.StatusPendingReview
+	}
+
+	return models.StatusApproved
+}
+
+// closesAt returns the first cutoff of the schedule after the moment, the
+// cutoffs are in the time zone.
+func closesAt(schedule models.Schedule, loc *time.Location, at time.Time) *time.Time {
+	closesAt, ok := schedule.Next(at.In(loc))
+	if !ok {
+		return nil
+	}
+
+	closesAt = closesAt.UTC()
+	return &closesAt
+}
+
 func (m *Manager) moveMessagesToNewReport(ctx context.Context, chatContext ReportChannel) {
 	for {
 		select {
@@ -199,16 +420,114 @@ func (m *Manager) moveMessagesToNewReport(ctx context.Context, chatContext Repor
 	}
 }
 
-func (m *Manager) notifyChats(ctx context.Context, chatContext ReportChannel) error {
-	url, err := m.clients.Googledrive.GetTableURL(
-		context.Background(),
-		models.GetTableName(chatContext.report.StartedAt, chatContext.chatContextName),
-	)
+// NotifyApproved sends the approved report to the chats of its context.
+func (m *Manager) NotifyApproved(ctx context.Context, reportID int) error {
+	details, err := m.repositories.ReportsRepo.GetReport(ctx, reportID)
 	if err != nil {
-		return fmt.Errorf("failed to get table URL: %w", err)
+		return fmt.Errorf("failed to get report: %w", err)
+	}
+
+	chatContextName := ""
+	if m.addChatContextName {
+		chatContextName = details.ChatContextName
 	}
 
-	chats, err := m.repositories.ChatsRepo.GetChats(ctx, chatContext.report.ChatContextID)
+	// the lines may have been edited in the review
+	m.checkCompleteness(ctx, reportID)
+	m.learnSheetEdits(ctx, details.Report, chatContextName)
+
+	return m.notifyChats(ctx, details.Report, chatContextName)
+}
+
+// checkCompleteness saves the completeness of the finished report, apart from
+// the delivery to the chats.
+func (m *Manager) checkCompleteness(ctx context.Context, reportID int) {
+	_, err := m.completeness.Check(ctx, reportID)
+	if err != nil {
+		log.Printf("failed to check completeness of report %d: %v", reportID, err)
+	}
+}
+
+// TableName returns the name of the table of the report on Google Drive.
+func (m *Manager) TableName(details models.ReportDetails) string {
+	chatContextName := ""
+	if m.addChatContextName {
+		chatContextName = details.ChatContextName
+	}
+
+	return models.GetTableName(details.StartedAt, details.Location(), chatContextName)
+}
+
+// notifyChats sends the summary of the report to the chats of the context,
+// with the link to the table, the XLSX file of the report or both as set by
+// the delivery of the context. The link is sent when the file can not be made.
+// The expected divisions and operations missing in the report, as checked
+// when it was finished, are listed.
+// A report waiting for the review is held: only the review request with its
+// numbered lines and the review commands is sent, without the file and the
+// link, they are delivered when the report is approved.
+func (m *Manager) notifyChats(ctx context.Context, report models.Report, chatContextName string) error {
+	pending := report.Status == models.StatusPendingReview
+
+	delivery := models.DeliveryLink
+	settings, err := m.repositories.ChatsRepo.GetSettings(ctx, report.ChatContextID)
+	if err != nil {
+		log.Printf("failed to get chat context settings: %v", err)
+	} else {
+		delivery = settings.ReportDelivery
+	}
+
+	var file *export.File
+	if !pending && delivery.HasFile() {
+		xlsx, err := m.export.ExportXLSX(ctx, report.ID, chatContextName)
+		if err != nil {
+			log.Printf("failed to export report %d: %v", report.ID, err)
+		} else {
+			file = &xlsx
+		}
+	}
+
+	url := ""
+	if !pending && (delivery.HasLink() || file == nil) {
+		url, err = m.clients.Googledrive.GetTableURL(
+			context.Background(),
+			models.GetTableName(report.StartedAt, report.Location(), chatContextName),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to get table URL: %w", err)
+		}
+	}
+
+	reportSummary, err := m.summary.Summarize(ctx, report.ID)
+	if err != nil {
+		log.Printf("failed to summarize report: %v", err)
+		reportSummary = models.ReportSummary{ReportID: report.ID, ChatContextName: chatContextName}
+	}
+
+	reportCompleteness, err := m.repositories.ReportsRepo.GetCompleteness(ctx, report.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		log.Printf("failed to get completeness of report %d: %v", report.ID, err)
+	} else if err == nil {
+		reportSummary.Expected = reportCompleteness.Expected
+		reportSummary.Missing = reportCompleteness.Missing
+	}
+
+	formatTelegram, formatWhatsApp := summary.FormatTelegram, summary.FormatWhatsApp
+	if pending {
+		lines, err := m.repositories.ReportsRepo.GetReportLines(ctx, report.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get report lines: %w", err)
+		}
+
+		formatTelegram = func(reportSummary models.ReportSummary, url string) string {
+			return summary.FormatReviewTelegram(reportSummary, lines, url)
+		}
+		formatWhatsApp = func(reportSummary models.ReportSummary, url string) string {
+			return summary.FormatReviewWhatsApp(reportSummary, lines, url)
+		}
+	}
+
+	chats, err := m.repositories.ChatsRepo.GetChats(ctx, report.ChatContextID)
 	if err != nil {
 		return fmt.Errorf("failed to get chats: %w", err)
 	}
@@ -229,17 +548,33 @@ func (m *Manager) notifyChats(ctx context.Context, chatContext ReportChannel) er
 				continue
 			}
 
-			err = m.clients.Whatsapp.SendReport(ctx, chatName, listenerID, url)
+			err = m.clients.Whatsapp.SendReport(ctx, chatName, listenerID, formatWhatsApp(reportSummary, url))
 			if err != nil {
 				errs = append(errs, fmt.Errorf("failed to send report: %w", err))
 				continue
 			}
+
+			if file != nil {
+				err = m.clients.Whatsapp.SendDocument(ctx, chatName, file.Name, file.MimeType, file.Data, "")
+				if err != nil {
+					errs = append(errs, fmt.Errorf("failed to send report file: %w", err))
+					continue
+				}
+			}
 		} else if chatType == "telegram" {
-			err = m.clients.Telegram.SendReport(ctx, chatName, url)
+			err = m.clients.Telegram.SendReport(ctx, chatName, formatTelegram(reportSummary, url))
 			if err != nil {
 				errs = append(errs, fmt.Errorf("failed to send report: %w", err))
 				continue
 			}
+
+			if file != nil {
+				err = m.clients.Telegram.SendDocument(ctx, chatName, file.Name, file.Data, "")
+				if err != nil {
+					errs = append(errs, fmt.Errorf("failed to send report file: %w", err))
+					continue
+				}
+			}
 		}
 	}
 
@@ -0,0 +1,131 @@
+package reporter
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
+)
+
+// StartScheduler closes the reports open since before the start that are
+// already due, then closes due reports in the background until the context
+// is done. Reports of chats that are being processed are closed by their
+// own cutoff timer.
+func (m *Manager) StartScheduler(ctx context.Context) {
+	m.closeDueReports(ctx)
+
+	if m.schedulerInterval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(m.schedulerInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.closeDueReports(ctx)
+			}
+		}
+	}()
+}
+
+func (m *Manager) closeDueReports(ctx context.Context) {
+	reports, err := m.repositories.ReportsRepo.GetOpenReports(ctx)
+	if err != nil {
+		log.Printf("failed to get open reports: %v", err)
+		return
+	}
+
+	for _, report := range reports {
+		m.fillClosesAt(ctx, &report)
+
+		if !report.IsNeedToFinish(time.Now()) || m.isProcessing(report) {
+			continue
+		}
+
+		err = m.closeReport(ctx, report)
+		if err != nil {
+			log.Printf("failed to close report %d: %v", report.ID, err)
+		}
+	}
+}
+
+// isProcessing reports whether the messages of the report are being processed.
+func (m *Manager) isProcessing(report models.Report) bool {
+	m.chatsMux.Lock()
+	defer m.chatsMux.Unlock()
+
+	chatContext, ok := m.chats[report.ChatContextID]
+	return ok && chatContext.report.ID == report.ID
+}
+
+// closeReports closes the reports one by one.
+func (m *Manager) closeReports(ctx context.Context, reports []models.Report) {
+	for _, report := range reports {
+		err := m.closeReport(ctx, report)
+		if err != nil {
+			log.Printf("failed to close report %d: %v", report.ID, err)
+		}
+	}
+}
+
+// closeReport finishes the report and notifies the chats, the chats are not
+// notified when the report has been finished already.
+func (m *Manager) closeReport(ctx context.Context, report models.Report) error {
+	report.Status = m.finishStatus(ctx, report.ChatContextID)
+
+	finished, err := m.repositories.ReportsRepo.FinishReport(ctx, report.ID, time.Now(), report.Status)
+	if err != nil {
+		return fmt.Errorf("failed to finish report: %w", err)
+	}
+
+	if !finished {
+		return nil
+	}
+
+	log.Printf("report %d closed", report.ID)
+
+	m.checkCompleteness(ctx, report.ID)
+
+	chatContextName := ""
+	if m.addChatContextName {
+		chatContextName, err = m.repositories.ChatsRepo.GetChatContextName(ctx, report.ChatContextID)
+		if err != nil {
+			return fmt.Errorf("failed to get chat context name: %w", err)
+		}
+	}
+
+	m.learnSheetEdits(ctx, report, chatContextName)
+
+	err = m.notifyChats(ctx, report, chatContextName)
+	if err != nil {
+		return fmt.Errorf("failed to notify chats: %w", err)
+	}
+
+	return nil
+}
+
+// fillClosesAt sets the cutoff of reports opened before schedules.
+func (m *Manager) fillClosesAt(ctx context.Context, report *models.Report) {
+	if report.ClosesAt != nil {
+		return
+	}
+
+	schedule, _ := m.getSchedule(ctx, report.ChatContextID)
+
+	report.ClosesAt = closesAt(schedule, report.Location(), report.StartedAt)
+	if report.ClosesAt == nil {
+		return
+	}
+
+	err := m.repositories.ReportsRepo.SetClosesAt(ctx, report.ID, *report.ClosesAt)
+	if err != nil {
+		log.Printf("failed to set report cutoff: %v", err)
+	}
+}
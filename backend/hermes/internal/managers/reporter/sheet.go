@@ -0,0 +1,127 @@
+package reporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/repositories/information"
+)
+
+// columns of the report sheet, as written by the googledrive client
+const (
+	columnDate = iota
+	columnDivision
+	columnPU
+	columnDepartment
+	columnOperation
+	columnCulture
+	columnPerDay
+	columnPerOperation
+	columnValDay
+	columnValBeginning
+)
+
+// learnSheetEdits compares the report sheet with the stored lines and learns
+// the yellow values corrected in the sheet as aliases of the chat context.
+// A sheet row is matched with the line by the date, the numbers and the
+// values that were not yellow, the rows added or edited beyond that are
+// left alone.
+func (m *Manager) learnSheetEdits(ctx context.Context, report models.Report, chatContextName string) {
+	err := m.compareSheet(ctx, report, chatContextName)
+	if err != nil {
+		log.Printf("failed to learn sheet edits of report %d: %v", report.ID, err)
+	}
+}
+
+func (m *Manager) compareSheet(ctx context.Context, report models.Report, chatContextName string) error {
+	lines, err := m.repositories.ReportsRepo.GetReportLines(ctx, report.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get report lines: %w", err)
+	}
+
+	yellow := make([]models.ReportLine, 0, len(lines))
+	for _, line := range lines {
+		if line.DivisionYellow || line.OperationYellow || line.CultureYellow {
+			yellow = append(yellow, line)
+		}
+	}
+
+	if len(yellow) == 0 {
+		return nil
+	}
+
+	rows, err := m.clients.Googledrive.GetTableRows(ctx, models.GetTableName(report.StartedAt, report.Location(), chatContextName))
+	if err != nil {
+		return fmt.Errorf("failed to get table rows: %w", err)
+	}
+
+	used := make([]bool, len(rows))
+	for _, line := range yellow {
+		for i, row := range rows {
+			if used[i] || !sameRow(row, line.Line) {
+				continue
+			}
+
+			used[i] = true
+			m.learnCorrected(ctx, report.ChatContextID, line.DivisionYellow, line.Division, row[columnDivision])
+			m.learnCorrected(ctx, report.ChatContextID, line.OperationYellow, line.Operation, row[columnOperation])
+			m.learnCorrected(ctx, report.ChatContextID, line.CultureYellow, line.Culture, row[columnCulture])
+			break
+		}
+	}
+
+	return nil
+}
+
+// learnCorrected stores the alias when the yellow value was replaced with a
+// dictionary entry, other values typed in the sheet are ignored.
+func (m *Manager) learnCorrected(ctx context.Context, chatContextID int, yellow bool, raw string, value string) {
+	if !yellow || value == "" || value == raw {
+		return
+	}
+
+	alias, err := m.normalizer.LearnCorrection(ctx, raw, value, chatContextID, models.AliasSourceSheet)
+	switch {
+	case errors.Is(err, information.ErrUnknownValue):
+		return
+	case err != nil:
+		log.Printf("failed to learn sheet correction %q: %v", raw, err)
+	default:
+		log.Printf("learned %s alias %q → %q from the sheet", alias.Dictionary, alias.Alias, alias.Value)
+	}
+}
+
+// sameRow reports whether the sheet row holds the line, the yellow values
+// may differ.
+func sameRow(row []string, line models.Line) bool {
+	cells := map[int]string{
+		columnPerDay:       fmt.Sprint(line.PerDay),
+		columnPerOperation: fmt.Sprint(line.PerOperation),
+		columnValDay:       fmt.Sprint(line.ValDay),
+		columnValBeginning: fmt.Sprint(line.ValBeginning),
+	}
+
+	if !line.Date.IsZero() {
+		cells[columnDate] = line.Date.String()
+	}
+	if !line.DivisionYellow {
+		cells[columnDivision] = line.Division
+	}
+	if !line.OperationYellow {
+		cells[columnOperation] = line.Operation
+	}
+	if !line.CultureYellow {
+		cells[columnCulture] = line.Culture
+	}
+
+	for column, value := range cells {
+		if row[column] != value {
+			return false
+		}
+	}
+
+	return true
+}
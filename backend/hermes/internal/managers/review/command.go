@@ -0,0 +1,118 @@
+package review
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
+)
+
+const (
+	approveCommand = "/approve"
+	rejectCommand  = "/reject"
+	editCommand    = "/edit"
+)
+
+// Command is a review command sent to a chat, a zero ReportID is the last
+// report of the chat context waiting for the review.
+type Command struct {
+	Action   models.ReviewAction
+	ReportID int
+	// Line is the number of the line to edit in the order of the review request
+	Line    int
+	Changes []Change
+	Comment string
+}
+
+// Change sets the field of the line, the field is as typed by the reviewer.
+type Change struct {
+	Field string
+	Value string
+}
+
+// ParseCommand parses "/approve [report]", "/reject [report] [comment]" and
+// "/edit [report] <line> <field>=<value>[; <field>=<value>]".
+func ParseCommand(text string) (Command, bool) {
+	text = strings.TrimSpace(text)
+
+	if rest, ok := cutCommand(text, approveCommand); ok {
+		numbers, rest := leadingNumbers(rest, 1)
+		if rest != "" {
+			return Command{}, false
+		}
+
+		return Command{Action: models.ReviewApprove, ReportID: first(numbers)}, true
+	}
+
+	if rest, ok := cutCommand(text, rejectCommand); ok {
+		numbers, rest := leadingNumbers(rest, 1)
+
+		return Command{Action: models.ReviewReject, ReportID: first(numbers), Comment: rest}, true
+	}
+
+	if rest, ok := cutCommand(text, editCommand); ok {
+		numbers, rest := leadingNumbers(rest, 2)
+
+		command := Command{Action: models.ReviewEdit}
+		switch len(numbers) {
+		case 1:
+			command.Line = numbers[0]
+		case 2:
+			command.ReportID, command.Line = numbers[0], numbers[1]
+		default:
+			return Command{}, false
+		}
+
+		for _, change := range strings.Split(rest, ";") {
+			field, value, ok := strings.Cut(change, "=")
+			field, value = strings.TrimSpace(field), strings.TrimSpace(value)
+			if !ok || field == "" || value == "" {
+				return Command{}, false
+			}
+
+			command.Changes = append(command.Changes, Change{Field: field, Value: value})
+		}
+
+		return command, true
+	}
+
+	return Command{}, false
+}
+
+// cutCommand cuts the command off the text, the command must be followed by
+// a space or end the text.
+func cutCommand(text string, command string) (string, bool) {
+	rest, ok := strings.CutPrefix(text, command)
+	if !ok || (rest != "" && rest[0] != ' ' && rest[0] != '\n') {
+		return "", false
+	}
+
+	return strings.TrimSpace(rest), true
+}
+
+// leadingNumbers cuts at most n positive numbers off the text.
+func leadingNumbers(text string, n int) ([]int, string) {
+	var numbers []int
+
+	for len(numbers) < n {
+		token, tail, _ := strings.Cut(text, " ")
+
+		number, err := strconv.Atoi(token)
+		if err != nil || number <= 0 {
+			break
+		}
+
+		numbers = append(numbers, number)
+		text = strings.TrimSpace(tail)
+	}
+
+	return numbers, text
+}
+
+func first(numbers []int) int {
+	if len(numbers) == 0 {
+		return 0
+	}
+
+	return numbers[0]
+}
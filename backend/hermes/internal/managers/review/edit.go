@@ -0,0 +1,161 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
+)
+
+// editFields are the fields of a line a reviewer can change, by the column
+// names of the table and the json names of the fields
+var editFields = map[string]string{
+	"дата":          "date",
+	"подразделение": "division",
+	"операция":      "operation",
+	"культура":      "culture",
+	"за день":       "per_day",
+	"с начала":      "per_operation",
+	"вал за день":   "val_day",
+	"вал с начала":  "val_beginning",
+}
+
+var fieldNames = map[string]string{
+	"date":          "дата",
+	"division":      "подразделение",
+	"operation":     "операция",
+	"culture":       "культура",
+	"per_day":       "за день",
+	"per_operation": "с начала",
+	"val_day":       "вал за день",
+	"val_beginning": "вал с начала",
+}
+
+// applyChange sets the field of the line and drops the flags of the field.
+// It returns the changed field, the reply is set when the change is invalid.
+func (m *Manager) applyChange(ctx context.Context, line *models.Line, change Change) (string, string, error) {
+	key := strings.ToLower(change.Field)
+	field, ok := editFields[key]
+	if !ok {
+		if _, ok := fieldNames[key]; !ok {
+			return "", fmt.Sprintf("Поле «%s» нельзя исправить, можно: дата, подразделение, операция, культура, за день, с начала, вал за день, вал с начала.", change.Field), nil
+		}
+		field = key
+	}
+
+	switch field {
+	case "date":
+		date, err := models.ParseDate(change.Value, line.Date.To)
+		if err != nil {
+			return "", fmt.Sprintf("Не удалось разобрать дату «%s».", change.Value), nil
+		}
+
+		line.Date = date
+		line.DateRaw = change.Value
+	case "division", "operation", "culture":
+		value, reply, err := m.dictionaryValue(ctx, field, change.Value, line.Date.To)
+		if err != nil || reply != "" {
+			return "", reply, err
+		}
+
+		switch field {
+		case "division":
+			// the unit belongs to the previous division
+			line.Division, line.DivisionSuggestion, line.DivisionYellow = value, "", false
+			line.UnitID, line.PU, line.Department = 0, "", ""
+		case "operation":
+			line.Operation, line.OperationSuggestion, line.OperationYellow = value, "", false
+		case "culture":
+			line.Culture, line.CultureSuggestion, line.CultureYellow = value, "", false
+		}
+	default:
+		value, err := strconv.ParseFloat(strings.ReplaceAll(strings.ReplaceAll(change.Value, " ", ""), ",", "."), 64)
+		if err != nil || value < 0 {
+			return "", fmt.Sprintf("«%s» не число.", change.Value), nil
+		}
+
+		switch field {
+		case "per_day":
+			line.PerDay = value
+		case "per_operation":
+			line.PerOperation = value
+		case "val_day":
+			line.ValDay = value
+		case "val_beginning":
+			line.ValBeginning = value
+		}
+
+		// the value is in the unit of the field, the reported one is outdated
+		delete(line.Measures, field)
+	}
+
+	flags := make([]models.Flag, 0, len(line.Flags))
+	for _, flag := range line.Flags {
+		if flag.Field != field {
+			flags = append(flags, flag)
+		}
+	}
+	line.Flags = flags
+
+	return field, "", nil
+}
+
+// underivedFlags are the flags set before the lines are tracked and validated.
+var underivedFlags = map[models.FlagCode]bool{
+	models.FlagDateInvalid:  true,
+	models.FlagUnitMismatch: true,
+}
+
+// underive drops the flags of the progress ledger and the validation and the
+// cumulative values filled from the ledger, they are set again when the lines
+// of the message are tracked and validated after the edit.
+func underive(line models.Line) models.Line {
+	flags := make([]models.Flag, 0, len(line.Flags))
+	for _, flag := range line.Flags {
+		if underivedFlags[flag.Code] {
+			flags = append(flags, flag)
+			continue
+		}
+
+		if flag.Code == models.FlagCumulativeFilled {
+			switch flag.Field {
+			case "per_operation":
+				line.PerOperation = 0
+			case "val_beginning":
+				line.ValBeginning = 0
+			}
+		}
+	}
+	line.Flags = flags
+
+	return line
+}
+
+// dictionaryValue returns the dictionary entry in force at the moment named
+// as the value regardless of the case.
+func (m *Manager) dictionaryValue(ctx context.Context, field string, value string, at time.Time) (string, string, error) {
+	var names []string
+	var err error
+	switch field {
+	case "division":
+		names, err = m.repositories.InformationRepo.GetDivisions(ctx, at)
+	case "operation":
+		names, err = m.repositories.InformationRepo.GetOperations(ctx, at)
+	case "culture":
+		names, err = m.repositories.InformationRepo.GetCultures(ctx, at)
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get dictionary: %w", err)
+	}
+
+	for _, name := range names {
+		if strings.EqualFold(name, value) {
+			return name, "", nil
+		}
+	}
+
+	return "", fmt.Sprintf("Значение «%s» не найдено в справочнике (%s).", value, fieldNames[field]), nil
+}
@@ -0,0 +1,245 @@
+package review
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/clients"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/managers/progress"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/managers/reporter"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/managers/validator"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/repositories"
+)
+
+func NewManager(
+	clients *clients.Clients,
+	repositories *repositories.Repositories,
+	reporter *reporter.Manager,
+	progress *progress.Manager,
+	validator *validator.Manager,
+) *Manager {
+	return &Manager{
+		clients:      clients,
+		repositories: repositories,
+		reporter:     reporter,
+		progress:     progress,
+		validator:    validator,
+	}
+}
+
+// Manager runs the review commands of the listeners of the chats: a closed
+// report waiting for the review is approved and sent to the chats, rejected
+// or corrected line by line.
+type Manager struct {
+	clients      *clients.Clients
+	repositories *repositories.Repositories
+
+	reporter  *reporter.Manager
+	progress  *progress.Manager
+	validator *validator.Manager
+}
+
+var statusNames = map[models.ReportStatus]string{
+	models.StatusCollecting:    "ещё собирается",
+	models.StatusPendingReview: "ждёт проверки",
+	models.StatusApproved:      "уже утверждён",
+	models.StatusRejected:      "возвращён на доработку",
+}
+
+// Handle runs the command sent by the worker to a chat of the chat context
+// and returns the reply to the chat.
+func (m *Manager) Handle(ctx context.Context, workerID int, chatContextID int, command Command) (string, error) {
+	isListener, err := m.repositories.ChatsRepo.IsListener(ctx, workerID, chatContextID)
+	if err != nil {
+		return "", err
+	}
+	if !isListener {
+		return "Проверять отчёты могут только ответственные за чат.", nil
+	}
+
+	report, reply, err := m.getReport(ctx, chatContextID, command.ReportID)
+	if err != nil || reply != "" {
+		return reply, err
+	}
+
+	if !report.Status.IsReviewable() {
+		return fmt.Sprintf("Отчёт №%d %s.", report.ID, statusNames[report.Status]), nil
+	}
+
+	switch command.Action {
+	case models.ReviewApprove:
+		return m.approve(ctx, workerID, report)
+	case models.ReviewReject:
+		return m.reject(ctx, workerID, report, command.Comment)
+	case models.ReviewEdit:
+		return m.edit(ctx, workerID, report, command.Line, command.Changes)
+	}
+
+	return "", fmt.Errorf("unknown review action %q", command.Action)
+}
+
+// getReport returns the report of the command, the reply is set when there
+// is no such report in the chat context.
+func (m *Manager) getReport(ctx context.Context, chatContextID int, reportID int) (models.ReportDetails, string, error) {
+	if reportID == 0 {
+		report, err := m.repositories.ReportsRepo.GetReviewableReport(ctx, chatContextID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ReportDetails{}, "Нет отчётов, ожидающих проверки.", nil
+		}
+		if err != nil {
+			return models.ReportDetails{}, "", err
+		}
+
+		reportID = report.ID
+	}
+
+	details, err := m.repositories.ReportsRepo.GetReport(ctx, reportID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && details.ChatContextID != chatContextID) {
+		return models.ReportDetails{}, fmt.Sprintf("Отчёт №%d не найден в этом чате.", reportID), nil
+	}
+	if err != nil {
+		return models.ReportDetails{}, "", err
+	}
+
+	return details, "", nil
+}
+
+func (m *Manager) approve(ctx context.Context, workerID int, report models.ReportDetails) (string, error) {
+	changed, err := m.repositories.ReportsRepo.SetStatus(ctx, report.ID, models.StatusApproved, models.StatusPendingReview, models.StatusRejected)
+	if err != nil {
+		return "", err
+	}
+	if !changed {
+		return fmt.Sprintf("Отчёт №%d уже утверждён.", report.ID), nil
+	}
+
+	m.addReview(ctx, models.ReportReview{ReportID: report.ID, WorkerID: workerID, Action: models.ReviewApprove})
+
+	log.Printf("report %d approved by worker %d", report.ID, workerID)
+
+	err = m.reporter.NotifyApproved(ctx, report.ID)
+	if err != nil {
+		log.Printf("failed to notify chats of approved report %d: %v", report.ID, err)
+		return fmt.Sprintf("Отчёт №%d утверждён, но его не удалось отправить в чаты.", report.ID), nil
+	}
+
+	return fmt.Sprintf("Отчёт №%d утверждён.", report.ID), nil
+}
+
+func (m *Manager) reject(ctx context.Context, workerID int, report models.ReportDetails, comment string) (string, error) {
+	changed, err := m.repositories.ReportsRepo.SetStatus(ctx, report.ID, models.StatusRejected, models.StatusPendingReview)
+	if err != nil {
+		return "", err
+	}
+	if !changed {
+		return fmt.Sprintf("Отчёт №%d уже возвращён на доработку.", report.ID), nil
+	}
+
+	m.addReview(ctx, models.ReportReview{ReportID: report.ID, WorkerID: workerID, Action: models.ReviewReject, Comment: comment})
+
+	log.Printf("report %d rejected by worker %d", report.ID, workerID)
+
+	reply := fmt.Sprintf("Отчёт №%d возвращён на доработку", report.ID)
+	if comment != "" {
+		reply += ": " + comment
+	}
+
+	return reply + fmt.Sprintf(".\nИсправьте строки командой /edit %d и утвердите отчёт командой /approve %d.", report.ID, report.ID), nil
+}
+
+// edit saves the changed line as a new version of the lines of its message.
+// The lines of the message are tracked and validated again, and the table of
+// the report on Google Drive is rewritten.
+func (m *Manager) edit(ctx context.Context, workerID int, report models.ReportDetails, number int, changes []Change) (string, error) {
+	if number < 1 || number > len(report.Lines) {
+		return fmt.Sprintf("В отчёте №%d нет строки %d, строк в нём: %d.", report.ID, number, len(report.Lines)), nil
+	}
+
+	edited := report.Lines[number-1]
+
+	line := edited.Line
+	applied := make([]string, 0, len(changes))
+	for _, change := range changes {
+		field, reply, err := m.applyChange(ctx, &line, change)
+		if err != nil || reply != "" {
+			return reply, err
+		}
+
+		applied = append(applied, fieldNames[field]+" = "+change.Value)
+	}
+
+	table := make(models.Table, 0)
+	for _, reportLine := range report.Lines {
+		if reportLine.MessageID != edited.MessageID {
+			continue
+		}
+
+		if reportLine.ID == edited.ID {
+			table = append(table, underive(line))
+		} else {
+			table = append(table, underive(reportLine.Line))
+		}
+	}
+
+	at := messageTime(report, edited.MessageID)
+
+	table = m.progress.Track(ctx, edited.MessageID, at, table)
+	table = m.validator.ValidateTable(ctx, at, table)
+
+	err := m.repositories.ReportsRepo.AddTable(ctx, report.ID, edited.MessageID, time.Now(), table)
+	if err != nil {
+		return "", fmt.Errorf("failed to save edited line: %w", err)
+	}
+
+	m.replaceTable(ctx, report)
+
+	comment := fmt.Sprintf("строка %d: %s", number, strings.Join(applied, "; "))
+	m.addReview(ctx, models.ReportReview{ReportID: report.ID, WorkerID: workerID, Action: models.ReviewEdit, Comment: comment})
+
+	return fmt.Sprintf("Отчёт №%d, %s.", report.ID, comment), nil
+}
+
+// replaceTable rewrites the table of the report on Google Drive with the
+// current lines of the report.
+func (m *Manager) replaceTable(ctx context.Context, report models.ReportDetails) {
+	lines, err := m.repositories.ReportsRepo.GetReportLines(ctx, report.ID)
+	if err != nil {
+		log.Printf("failed to get report lines: %v", err)
+		return
+	}
+
+	table := make(models.Table, 0, len(lines))
+	for _, line := range lines {
+		table = append(table, line.Line)
+	}
+
+	err = m.clients.Googledrive.ReplaceTable(ctx, m.reporter.TableName(report), table)
+	if err != nil {
+		log.Printf("failed to replace table of report %d: %v", report.ID, err)
+	}
+}
+
+// messageTime returns the time the message was sent in the time zone of the
+// report, now when the message is not in the report.
+func messageTime(report models.ReportDetails, messageID int) time.Time {
+	for _, message := range report.Messages {
+		if message.ID == messageID {
+			return message.CreatedAt.In(report.Location())
+		}
+	}
+
+	return time.Now().In(report.Location())
+}
+
+func (m *Manager) addReview(ctx context.Context, review models.ReportReview) {
+	err := m.repositories.ReportsRepo.AddReview(ctx, review)
+	if err != nil {
+		log.Printf("failed to add report review: %v", err)
+	}
+}
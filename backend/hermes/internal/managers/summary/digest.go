@@ -0,0 +1,241 @@
+package summary
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
+)
+
+type digestKey struct {
+	division  string
+	operation string
+	culture   string
+}
+
+// Digest totals the reports closed in the period by division, operation and
+// culture. Within a division the daily values of the reports are summed and
+// the cumulative ones are the largest reported. The deltas are taken from the
+// reports of the previous period, rows missing in the period are kept with
+// zero values to show what was not reported again.
+func Digest(from, to time.Time, reports []models.ReportDetails, previous []models.ReportDetails) models.Digest {
+	digest := models.Digest{
+		From:         from,
+		To:           to,
+		Reports:      len(reports),
+		ChatContexts: make([]string, 0),
+	}
+
+	seen := make(map[string]bool)
+	for _, report := range reports {
+		if !seen[report.ChatContextName] {
+			seen[report.ChatContextName] = true
+			digest.ChatContexts = append(digest.ChatContexts, report.ChatContextName)
+		}
+
+		for _, line := range report.Lines {
+			digest.Lines++
+			if line.OperationID == nil || line.CultureID == nil {
+				digest.Unrecognized++
+			}
+			if len(line.Flags) > 0 {
+				digest.Flagged++
+			}
+		}
+	}
+
+	current := digestRows(reports)
+	before := digestRows(previous)
+
+	for key, row := range current {
+		prev := before[key]
+		row.PerDayDelta = row.PerDay - prev.PerDay
+		row.ValDayDelta = row.ValDay - prev.ValDay
+		digest.Rows = append(digest.Rows, row)
+	}
+
+	for key, prev := range before {
+		if _, ok := current[key]; ok {
+			continue
+		}
+
+		digest.Rows = append(digest.Rows, models.DigestRow{
+			Division:    key.division,
+			Operation:   key.operation,
+			Culture:     key.culture,
+			ValUnit:     prev.ValUnit,
+			PerDayDelta: -prev.PerDay,
+			ValDayDelta: -prev.ValDay,
+		})
+	}
+
+	slices.SortFunc(digest.Rows, func(a, b models.DigestRow) int {
+		return cmp.Or(
+			cmp.Compare(a.Operation, b.Operation),
+			cmp.Compare(a.Culture, b.Culture),
+			cmp.Compare(a.Division, b.Division),
+		)
+	})
+
+	if digest.Rows == nil {
+		digest.Rows = make([]models.DigestRow, 0)
+	}
+
+	return digest
+}
+
+func digestRows(reports []models.ReportDetails) map[digestKey]models.DigestRow {
+	rows := make(map[digestKey]models.DigestRow)
+
+	for _, report := range reports {
+		for _, line := range report.Lines {
+			key := digestKey{division: line.Division, operation: line.Operation, culture: line.Culture}
+
+			row, ok := rows[key]
+			if !ok {
+				row = models.DigestRow{Division: line.Division, Operation: line.Operation, Culture: line.Culture}
+			}
+
+			row.PerDay += line.PerDay
+			row.ValDay += line.ValDay
+			row.PerOperation = max(row.PerOperation, line.PerOperation)
+			row.ValBeginning = max(row.ValBeginning, line.ValBeginning)
+			if row.ValUnit == "" && (line.ValDay != 0 || line.ValBeginning != 0) {
+				row.ValUnit = unitOf(line.ValDayUnit, line.ValBeginningUnit)
+			}
+
+			rows[key] = row
+		}
+	}
+
+	return rows
+}
+
+// DigestGroups sums the rows of the digest over divisions, the groups keep
+// the order of the rows.
+func DigestGroups(digest models.Digest) []models.DigestRow {
+	type groupKey struct {
+		operation string
+		culture   string
+	}
+
+	groups := make([]models.DigestRow, 0)
+	index := make(map[groupKey]int)
+
+	for _, row := range digest.Rows {
+		key := groupKey{operation: row.Operation, culture: row.Culture}
+
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, models.DigestRow{Operation: row.Operation, Culture: row.Culture})
+		}
+
+		group := &groups[i]
+		group.PerDay += row.PerDay
+		group.PerOperation += row.PerOperation
+		group.ValDay += row.ValDay
+		group.ValBeginning += row.ValBeginning
+		group.PerDayDelta += row.PerDayDelta
+		group.ValDayDelta += row.ValDayDelta
+		if group.ValUnit == "" {
+			group.ValUnit = row.ValUnit
+		}
+	}
+
+	return groups
+}
+
+var textStyle = style{
+	bold: func(text string) string { return text },
+	text: func(text string) string { return text },
+	link: func(title, url string) string { return title + ": " + url },
+}
+
+// FormatDigestTelegram renders the digest as Telegram HTML.
+func FormatDigestTelegram(digest models.Digest) string {
+	return formatDigest(digest, telegramStyle)
+}
+
+// FormatDigestWhatsApp renders the digest as WhatsApp text with *bold* markup.
+func FormatDigestWhatsApp(digest models.Digest) string {
+	return formatDigest(digest, whatsappStyle)
+}
+
+// FormatDigestText renders the digest as plain text, e.g. for mail.
+func FormatDigestText(digest models.Digest) string {
+	return formatDigest(digest, textStyle)
+}
+
+// DigestTitle is the title of the digest, e.g. the subject of the mail.
+func DigestTitle(digest models.Digest) string {
+	return "Сводка по отчётам на " + digest.To.Format(periodLayout)
+}
+
+func formatDigest(digest models.Digest, s style) string {
+	var b strings.Builder
+
+	b.WriteString(s.bold(DigestTitle(digest)) + "\n")
+	b.WriteString(s.text(digest.From.Format(periodLayout)+" — "+digest.To.Format(periodLayout)) + "\n")
+
+	if digest.Reports == 0 {
+		b.WriteString("\n" + s.text("За период не закрыто ни одного отчёта.") + "\n")
+	} else {
+		b.WriteString(s.text(fmt.Sprintf("Отчётов: %d (%s)", digest.Reports, strings.Join(digest.ChatContexts, ", "))) + "\n")
+	}
+
+	if digest.Unreviewed > 0 {
+		b.WriteString(s.text(fmt.Sprintf("Не утверждено отчётов: %d, в сводку они не вошли", digest.Unreviewed)) + "\n")
+	}
+
+	groups := DigestGroups(digest)
+	for i, group := range groups {
+		if i == MaxGroups {
+			b.WriteString("\n" + s.text(fmt.Sprintf("…и ещё %d — в таблице", len(groups)-MaxGroups)) + "\n")
+			break
+		}
+
+		b.WriteString("\n" + s.bold(groupTitle(models.SummaryGroup{Operation: group.Operation, Culture: group.Culture})) + "\n")
+		b.WriteString(s.text(fmt.Sprintf("за день %s га (%s), с начала %s га",
+			formatNumber(group.PerDay), formatDelta(group.PerDayDelta), formatNumber(group.PerOperation))) + "\n")
+
+		if group.ValDay != 0 || group.ValBeginning != 0 || group.ValDayDelta != 0 {
+			b.WriteString(s.text(fmt.Sprintf("вал за день %s %s (%s), с начала %s %s",
+				formatNumber(group.ValDay), group.ValUnit, formatDelta(group.ValDayDelta),
+				formatNumber(group.ValBeginning), group.ValUnit)) + "\n")
+		}
+	}
+
+	if digest.Unrecognized > 0 || digest.Flagged > 0 {
+		b.WriteString("\n")
+		if digest.Unrecognized > 0 {
+			b.WriteString(s.text(fmt.Sprintf("Не распознано строк: %d из %d", digest.Unrecognized, digest.Lines)) + "\n")
+		}
+		if digest.Flagged > 0 {
+			b.WriteString(s.text(fmt.Sprintf("Строк с замечаниями: %d из %d", digest.Flagged, digest.Lines)) + "\n")
+		}
+	}
+
+	if len(digest.Rows) > 0 {
+		b.WriteString("\n" + s.text("В скобках — изменение к прошлому периоду, по подразделениям — в таблице.") + "\n")
+	}
+
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// formatDelta formats the change with its sign.
+func formatDelta(value float64) string {
+	text := formatNumber(value)
+	if text == "0" || text == "-0" {
+		return "±0"
+	}
+	if !strings.HasPrefix(text, "-") {
+		return "+" + text
+	}
+
+	return "−" + text[1:]
+}
@@ -0,0 +1,172 @@
+package summary
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
+)
+
+// MaxGroups is the number of operation and culture totals listed in a message,
+// the rest are left for the table.
+const MaxGroups = 25
+
+const periodLayout = "02.01.2006 15:04"
+
+// style is the markup of a messenger.
+type style struct {
+	bold func(text string) string
+	text func(text string) string
+	link func(title, url string) string
+
+	// maxLength is the longest message the messenger accepts, in characters
+	maxLength int
+}
+
+var telegramStyle = style{
+	maxLength: 4096,
+
+	bold: func(text string) string { return "<b>" + html.EscapeString(text) + "</b>" },
+	text: html.EscapeString,
+	link: func(title, url string) string {
+		return fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(url), html.EscapeString(title))
+	},
+}
+
+var whatsappStyle = style{
+	maxLength: 65536,
+
+	bold: func(text string) string { return "*" + strings.ReplaceAll(text, "*", "") + "*" },
+	text: func(text string) string { return text },
+	link: func(title, url string) string { return title + ": " + url },
+}
+
+// FormatTelegram renders the summary as Telegram HTML.
+func FormatTelegram(summary models.ReportSummary, url string) string {
+	return format(summary, url, telegramStyle)
+}
+
+// FormatWhatsApp renders the summary as WhatsApp text with *bold* markup.
+func FormatWhatsApp(summary models.ReportSummary, url string) string {
+	return format(summary, url, whatsappStyle)
+}
+
+func format(summary models.ReportSummary, url string, s style) string {
+	return formatWithin(summary, url, s, s.maxLength)
+}
+
+// formatWithin lists fewer totals while the text is longer than limit.
+func formatWithin(summary models.ReportSummary, url string, s style, limit int) string {
+	text := formatGroups(summary, url, s, MaxGroups)
+	for maxGroups := MaxGroups - 1; maxGroups >= 0 && utf8.RuneCountInString(text) > limit; maxGroups-- {
+		text = formatGroups(summary, url, s, maxGroups)
+	}
+
+	return text
+}
+
+// formatGroups renders the summary with at most maxGroups totals and missing items.
+func formatGroups(summary models.ReportSummary, url string, s style, maxGroups int) string {
+	var b strings.Builder
+
+	title := "Отчёт"
+	if summary.ChatContextName != "" {
+		title += " «" + summary.ChatContextName + "»"
+	}
+	b.WriteString(s.bold(title) + "\n")
+
+	if !summary.From.IsZero() {
+		b.WriteString(s.text(summary.From.Format(periodLayout)+" — "+summary.To.Format(periodLayout)) + "\n")
+	}
+
+	if len(summary.Groups) == 0 {
+		b.WriteString("\n" + s.text("Строк в отчёте нет.") + "\n")
+	}
+
+	for i, group := range summary.Groups {
+		if i == maxGroups {
+			b.WriteString("\n" + s.text(fmt.Sprintf("…и ещё %d — в таблице", len(summary.Groups)-maxGroups)) + "\n")
+			break
+		}
+
+		b.WriteString("\n" + s.bold(groupTitle(group)) + "\n")
+		b.WriteString(s.text(fmt.Sprintf("за день %s га, с начала %s га",
+			formatNumber(group.PerDay), formatNumber(group.PerOperation))) + "\n")
+
+		if group.ValDay != 0 || group.ValBeginning != 0 {
+			b.WriteString(s.text(fmt.Sprintf("вал за день %s %s, с начала %s %s",
+				formatNumber(group.ValDay), group.ValUnit, formatNumber(group.ValBeginning), group.ValUnit)) + "\n")
+		}
+	}
+
+	if summary.Unrecognized > 0 || summary.Flagged > 0 {
+		b.WriteString("\n")
+		if summary.Unrecognized > 0 {
+			b.WriteString(s.text(fmt.Sprintf("Не распознано строк: %d из %d", summary.Unrecognized, summary.Lines)) + "\n")
+		}
+		if summary.Flagged > 0 {
+			b.WriteString(s.text(fmt.Sprintf("Строк с замечаниями: %d из %d", summary.Flagged, summary.Lines)) + "\n")
+		}
+	}
+
+	if len(summary.Missing) > 0 {
+		b.WriteString("\n" + s.bold(fmt.Sprintf("Нет в отчёте: %d из %d", len(summary.Missing), summary.Expected)) + "\n")
+
+		for i, item := range summary.Missing {
+			if i == maxGroups {
+				b.WriteString(s.text(fmt.Sprintf("…и ещё %d", len(summary.Missing)-maxGroups)) + "\n")
+				break
+			}
+
+			b.WriteString(s.text("— "+item.Division+": "+item.Operation) + "\n")
+		}
+	}
+
+	if len(summary.Workers) > 0 {
+		b.WriteString("\n" + s.text("Отчитались: "+strings.Join(summary.Workers, ", ")) + "\n")
+	}
+
+	if url != "" {
+		b.WriteString("\n" + s.link("Таблица отчёта", url))
+	}
+
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func groupTitle(group models.SummaryGroup) string {
+	operation, culture := group.Operation, group.Culture
+	if operation == "" {
+		operation = "Операция не указана"
+	}
+	if culture == "" {
+		return operation
+	}
+
+	return operation + " — " + culture
+}
+
+// formatNumber rounds the value to hundredths and groups the thousands with spaces.
+func formatNumber(value float64) string {
+	text := strconv.FormatFloat(math.Round(value*100)/100, 'f', -1, 64)
+
+	integer, fraction, hasFraction := strings.Cut(text, ".")
+
+	sign := ""
+	if strings.HasPrefix(integer, "-") {
+		sign, integer = "-", integer[1:]
+	}
+
+	for i := len(integer) - 3; i > 0; i -= 3 {
+		integer = integer[:i] + " " + integer[i:]
+	}
+
+	if hasFraction {
+		return sign + integer + "," + fraction
+	}
+
+	return sign + integer
+}
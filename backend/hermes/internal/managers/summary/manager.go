@@ -0,0 +1,131 @@
+package summary
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/repositories"
+)
+
+func NewManager(repositories *repositories.Repositories) *Manager {
+	return &Manager{
+		repositories: repositories,
+	}
+}
+
+// Manager sums up reports for the chats.
+type Manager struct {
+	repositories *repositories.Repositories
+}
+
+func (m *Manager) Summarize(ctx context.Context, reportID int) (models.ReportSummary, error) {
+	details, err := m.repositories.ReportsRepo.GetReport(ctx, reportID)
+	if err != nil {
+		return models.ReportSummary{}, fmt.Errorf("failed to get report: %w", err)
+	}
+
+	return Summarize(details), nil
+}
+
+// Summarize totals the lines of the report by operation and culture. The
+// daily values are summed, the cumulative ones are summed over divisions
+// taking the largest value reported for a division.
+func Summarize(details models.ReportDetails) models.ReportSummary {
+	loc := details.Location()
+
+	summary := models.ReportSummary{
+		ReportID:        details.ID,
+		ChatContextName: details.ChatContextName,
+		From:            details.StartedAt.In(loc),
+		To:              reportEnd(details.Report).In(loc),
+		Groups:          make([]models.SummaryGroup, 0),
+		Lines:           len(details.Lines),
+		Workers:         make([]string, 0),
+	}
+
+	type groupKey struct {
+		operation string
+		culture   string
+	}
+
+	type divisionKey struct {
+		groupKey
+		division string
+	}
+
+	groups := make(map[groupKey]int)
+	perOperation := make(map[divisionKey]float64)
+	valBeginning := make(map[divisionKey]float64)
+
+	for _, line := range details.Lines {
+		if line.OperationID == nil || line.CultureID == nil {
+			summary.Unrecognized++
+		}
+		if len(line.Flags) > 0 {
+			summary.Flagged++
+		}
+
+		key := groupKey{operation: line.Operation, culture: line.Culture}
+
+		i, ok := groups[key]
+		if !ok {
+			i = len(summary.Groups)
+			groups[key] = i
+			summary.Groups = append(summary.Groups, models.SummaryGroup{Operation: line.Operation, Culture: line.Culture})
+		}
+
+		group := &summary.Groups[i]
+		group.PerDay += line.PerDay
+		group.ValDay += line.ValDay
+		if group.ValUnit == "" && (line.ValDay != 0 || line.ValBeginning != 0) {
+			group.ValUnit = unitOf(line.ValDayUnit, line.ValBeginningUnit)
+		}
+
+		division := divisionKey{groupKey: key, division: line.Division}
+		perOperation[division] = max(perOperation[division], line.PerOperation)
+		valBeginning[division] = max(valBeginning[division], line.ValBeginning)
+	}
+
+	for division, value := range perOperation {
+		summary.Groups[groups[division.groupKey]].PerOperation += value
+	}
+	for division, value := range valBeginning {
+		summary.Groups[groups[division.groupKey]].ValBeginning += value
+	}
+
+	seen := make(map[int]bool)
+	for _, message := range details.Messages {
+		if message.Role != "user" || seen[message.WorkerID] {
+			continue
+		}
+
+		seen[message.WorkerID] = true
+		summary.Workers = append(summary.Workers, message.WorkerName)
+	}
+
+	return summary
+}
+
+// reportEnd is the moment the report was finished or is to be closed.
+func reportEnd(report models.Report) time.Time {
+	switch {
+	case report.FinishedAt != nil:
+		return *report.FinishedAt
+	case report.ClosesAt != nil:
+		return *report.ClosesAt
+	}
+
+	return time.Now()
+}
+
+func unitOf(units ...string) string {
+	for _, unit := range units {
+		if unit != "" {
+			return unit
+		}
+	}
+
+	return models.MassUnit
+}
@@ -0,0 +1,86 @@
+package summary
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
+)
+
+// MaxReviewLines is the number of lines listed in a review request, the rest
+// are left for the table. Fewer are listed when the request would not fit
+// the message limit of the messenger.
+const MaxReviewLines = 50
+
+// reviewMoreLength is kept in the review request for the line saying how
+// many lines are left for the table.
+const reviewMoreLength = 40
+
+// FormatReviewTelegram renders the summary of the report waiting for the
+// review as Telegram HTML, with the numbered lines and the review commands.
+func FormatReviewTelegram(summary models.ReportSummary, lines []models.ReportLine, url string) string {
+	return formatReview(summary, lines, url, telegramStyle)
+}
+
+// FormatReviewWhatsApp renders the summary of the report waiting for the
+// review as WhatsApp text, with the numbered lines and the review commands.
+func FormatReviewWhatsApp(summary models.ReportSummary, lines []models.ReportLine, url string) string {
+	return formatReview(summary, lines, url, whatsappStyle)
+}
+
+func formatReview(summary models.ReportSummary, lines []models.ReportLine, url string, s style) string {
+	var b strings.Builder
+
+	footer := "\n" + s.text(fmt.Sprintf("/approve %d — утвердить", summary.ReportID)) + "\n" +
+		s.text(fmt.Sprintf("/reject %d причина — вернуть на доработку", summary.ReportID)) + "\n" +
+		s.text(fmt.Sprintf("/edit %d 3 за день=45 — исправить строку 3", summary.ReportID))
+
+	// the summary takes at most half of the message, the lines the rest
+	b.WriteString(formatWithin(summary, url, s, s.maxLength/2) + "\n\n")
+	b.WriteString(s.bold(fmt.Sprintf("Отчёт №%d ждёт проверки", summary.ReportID)) + "\n")
+
+	left := s.maxLength - utf8.RuneCountInString(b.String()) - utf8.RuneCountInString(footer) - reviewMoreLength
+
+	for i, line := range lines {
+		row := s.text(fmt.Sprintf("%d. %s", i+1, reviewLine(line))) + "\n"
+
+		left -= utf8.RuneCountInString(row)
+		if i == MaxReviewLines || left < 0 {
+			b.WriteString(s.text(fmt.Sprintf("…и ещё %d — в таблице", len(lines)-i)) + "\n")
+			break
+		}
+
+		b.WriteString(row)
+	}
+
+	b.WriteString(footer)
+
+	return b.String()
+}
+
+// reviewLine renders the line in one row, the lines with unmatched values or
+// flags are marked with "⚠".
+func reviewLine(line models.ReportLine) string {
+	parts := []string{line.Date.String()}
+	for _, value := range []string{line.Division, line.Operation, line.Culture} {
+		if value == "" {
+			value = "—"
+		}
+		parts = append(parts, value)
+	}
+
+	text := strings.Join(parts, ", ") + fmt.Sprintf(": за день %s га, с начала %s га",
+		formatNumber(line.PerDay), formatNumber(line.PerOperation))
+
+	if line.ValDay != 0 || line.ValBeginning != 0 {
+		unit := unitOf(line.ValDayUnit, line.ValBeginningUnit)
+		text += fmt.Sprintf(", вал %s %s, с начала %s %s", formatNumber(line.ValDay), unit, formatNumber(line.ValBeginning), unit)
+	}
+
+	if line.DivisionYellow || line.OperationYellow || line.CultureYellow || len(line.Flags) > 0 {
+		text += " ⚠"
+	}
+
+	return text
+}
@@ -0,0 +1,171 @@
+package validator
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/repositories"
+)
+
+type Config struct {
+	// defaults used when validation_limits has no row for the operation and culture
+	MaxPerDayHa float64 `json:"VALIDATION_MAX_PER_DAY_HA" cfgDefault:"3000"`
+	MinYield    float64 `json:"VALIDATION_MIN_YIELD" cfgDefault:"1"`
+	MaxYield    float64 `json:"VALIDATION_MAX_YIELD" cfgDefault:"1000"`
+}
+
+func NewManager(cfg Config, repositories *repositories.Repositories) *Manager {
+	return &Manager{
+		repositories: repositories,
+		defaults: Limit{
+			MaxPerDay: cfg.MaxPerDayHa,
+			MinYield:  cfg.MinYield,
+			MaxYield:  cfg.MaxYield,
+		},
+		rules: []Rule{
+			RuleFunc(checkCumulative),
+			RuleFunc(checkAreaRange),
+			RuleFunc(checkYield),
+			RuleFunc(checkUnitArea),
+		},
+	}
+}
+
+// Manager flags report lines with inconsistent or implausible numbers.
+type Manager struct {
+	repositories *repositories.Repositories
+
+	defaults Limit
+	rules    []Rule
+}
+
+// Rule checks a line of the report and returns the issues found.
+type Rule interface {
+	Check(line models.Line, reference *Reference) []models.Flag
+}
+
+// RuleFunc adapts a function to the Rule interface.
+type RuleFunc func(line models.Line, reference *Reference) []models.Flag
+
+func (f RuleFunc) Check(line models.Line, reference *Reference) []models.Flag {
+	return f(line, reference)
+}
+
+// AddRule registers the rule to run after the default ones.
+func (m *Manager) AddRule(rule Rule) {
+	m.rules = append(m.rules, rule)
+}
+
+// ValidateTable adds the flags found by the rules to every line.
+func (m *Manager) ValidateTable(ctx context.Context, at time.Time, table models.Table) models.Table {
+	reference := &Reference{defaults: m.defaults}
+
+	var err error
+	reference.limits, err = m.repositories.InformationRepo.GetValidationLimits(ctx)
+	if err != nil {
+		log.Printf("failed to get validation limits: %v", err)
+	}
+
+	reference.units, err = m.repositories.InformationRepo.GetUnits(ctx, at)
+	if err != nil {
+		log.Printf("failed to get units: %v", err)
+	}
+
+	for i, line := range table {
+		for _, rule := range m.rules {
+			table[i].Flags = append(table[i].Flags, rule.Check(line, reference)...)
+		}
+	}
+
+	return table
+}
+
+// Limit is the sanity range applied to a line, zero bounds are not checked.
+type Limit struct {
+	MaxPerDay float64
+	MinYield  float64
+	MaxYield  float64
+}
+
+// Reference is the data rules check lines against.
+type Reference struct {
+	defaults Limit
+	limits   []models.ValidationLimit
+	units    []models.DictionaryEntry
+}
+
+// Limit merges the configured defaults with the limits of the culture, the
+// operation and the pair of them, the more specific one wins.
+func (r *Reference) Limit(operation, culture string) Limit {
+	limit := r.defaults
+
+	for specificity := 0; specificity <= 3; specificity++ {
+		for _, row := range r.limits {
+			if limitSpecificity(row, operation, culture) != specificity {
+				continue
+			}
+
+			if row.MaxPerDay != nil {
+				limit.MaxPerDay = *row.MaxPerDay
+			}
+			if row.MinYield != nil {
+				limit.MinYield = *row.MinYield
+			}
+			if row.MaxYield != nil {
+				limit.MaxYield = *row.MaxYield
+			}
+		}
+	}
+
+	return limit
+}
+
+// limitSpecificity is 0 for the default row, 1 for culture, 2 for operation,
+// 3 for both and -1 when the row does not match.
+func limitSpecificity(row models.ValidationLimit, operation, culture string) int {
+	specificity := 0
+
+	if row.Culture != nil {
+		if *row.Culture != culture {
+			return -1
+		}
+		specificity++
+	}
+
+	if row.Operation != nil {
+		if *row.Operation != operation {
+			return -1
+		}
+		specificity += 2
+	}
+
+	return specificity
+}
+
+// Area returns the area of the line's unit, or of the whole division when
+// the unit is unknown. It is false if any of the units has no area.
+func (r *Reference) Area(line models.Line) (float64, bool) {
+	var area float64
+	found := false
+
+	for _, unit := range r.units {
+		if line.UnitID != 0 && unit.ID != line.UnitID {
+			continue
+		}
+
+		if line.UnitID == 0 && (line.Division == "" || unit.Name != line.Division) {
+			continue
+		}
+
+		if unit.Area == nil {
+			return 0, false
+		}
+
+		area += *unit.Area
+		found = true
+	}
+
+	return area, found
+}
@@ -0,0 +1,113 @@
+package validator
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
+)
+
+func checkCumulative(line models.Line, _ *Reference) []models.Flag {
+	var flags []models.Flag
+
+	if line.PerOperation > 0 && line.PerOperation < line.PerDay {
+		flags = append(flags, models.Flag{
+			Code:    models.FlagCumulativeBelowDaily,
+			Field:   "per_operation",
+			Message: fmt.Sprintf("С начала операции %s га меньше, чем за день %s га", formatNumber(line.PerOperation), formatNumber(line.PerDay)),
+		})
+	}
+
+	if line.ValBeginning > 0 && line.ValBeginning < line.ValDay {
+		flags = append(flags, models.Flag{
+			Code:  models.FlagCumulativeBelowDaily,
+			Field: "val_beginning",
+			Message: fmt.Sprintf("Вал с начала %s %s меньше, чем за день %s %s",
+				formatNumber(line.ValBeginning), unitOf(line.ValBeginningUnit, models.MassUnit),
+				formatNumber(line.ValDay), unitOf(line.ValDayUnit, models.MassUnit)),
+		})
+	}
+
+	return flags
+}
+
+func checkAreaRange(line models.Line, reference *Reference) []models.Flag {
+	if line.PerDay < 0 {
+		return []models.Flag{{
+			Code:    models.FlagAreaOutOfRange,
+			Field:   "per_day",
+			Message: "Отрицательная площадь за день",
+		}}
+	}
+
+	limit := reference.Limit(line.Operation, line.Culture)
+	if limit.MaxPerDay > 0 && line.PerDay > limit.MaxPerDay {
+		return []models.Flag{{
+			Code:    models.FlagAreaOutOfRange,
+			Field:   "per_day",
+			Message: fmt.Sprintf("За день %s га — больше допустимых %s га", formatNumber(line.PerDay), formatNumber(limit.MaxPerDay)),
+		}}
+	}
+
+	return nil
+}
+
+func checkYield(line models.Line, reference *Reference) []models.Flag {
+	if line.ValDay <= 0 {
+		return nil
+	}
+
+	// yield is in c/ha, gross values of other units are not checked
+	if unitOf(line.ValDayUnit, models.MassUnit) != models.MassUnit || unitOf(line.PerDayUnit, models.AreaUnit) != models.AreaUnit {
+		return nil
+	}
+
+	if line.PerDay <= 0 {
+		return []models.Flag{{
+			Code:    models.FlagYieldOutOfRange,
+			Field:   "val_day",
+			Message: "Вал за день указан без площади",
+		}}
+	}
+
+	limit := reference.Limit(line.Operation, line.Culture)
+	yield := line.ValDay / line.PerDay
+
+	if (limit.MinYield > 0 && yield < limit.MinYield) || (limit.MaxYield > 0 && yield > limit.MaxYield) {
+		return []models.Flag{{
+			Code:  models.FlagYieldOutOfRange,
+			Field: "val_day",
+			Message: fmt.Sprintf("Урожайность %.1f ц/га вне диапазона %s–%s ц/га",
+				yield, formatNumber(limit.MinYield), formatNumber(limit.MaxYield)),
+		}}
+	}
+
+	return nil
+}
+
+func checkUnitArea(line models.Line, reference *Reference) []models.Flag {
+	area, ok := reference.Area(line)
+	if !ok || line.PerOperation <= area {
+		return nil
+	}
+
+	return []models.Flag{{
+		Code:    models.FlagUnitAreaExceeded,
+		Field:   "per_operation",
+		Message: fmt.Sprintf("С начала операции %s га больше площади подразделения %s га", formatNumber(line.PerOperation), formatNumber(area)),
+	}}
+}
+
+func formatNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// unitOf returns the unit of the value, lines saved before units were
+// tracked have none and are in the default unit.
+func unitOf(unit string, defaultUnit string) string {
+	if unit == "" {
+		return defaultUnit
+	}
+
+	return unit
+}
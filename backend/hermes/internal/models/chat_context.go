@@ -0,0 +1,101 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lild1tz/llm_coding_challenge/backend/libs/go/loctime"
+)
+
+type ChatContext struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+
+	Settings ChatContextSettings `json:"settings"`
+}
+
+// ChatContextSettings are the recognition defaults of the chat context.
+// Contexts without saved settings have zero settings.
+type ChatContextSettings struct {
+	ChatContextID int `json:"chat_context_id"`
+
+	// DefaultDivision and DefaultCulture fill lines recognized without them
+	DefaultDivision string `json:"default_division"`
+	DefaultCulture  string `json:"default_culture"`
+	// ExpectedOperations are the operations usually reported in the context
+	ExpectedOperations []string `json:"expected_operations"`
+	// PromptHints is free text passed to Apollo with every message
+	PromptHints string `json:"prompt_hints"`
+	// ReportSchedule are the cutoffs closing the reports, see Schedule,
+	// the reporter's default cutoff is used when it is empty
+	ReportSchedule []string `json:"report_schedule"`
+	// TimeZone is the IANA name of the time zone, empty for the default one
+	TimeZone string `json:"time_zone"`
+	// ReportDelivery is how the closed report is sent to the chats
+	ReportDelivery ReportDelivery `json:"report_delivery"`
+	// ReviewRequired holds the closed reports for the review of the listeners
+	// of the chats, the report is delivered when it is approved
+	ReviewRequired bool `json:"review_required"`
+	// RemindBefore is the minutes before the cutoff when the expected
+	// reporters who have not reported are reminded, EscalateAfter is the
+	// minutes after it when the listeners are told about the ones still
+	// missing, nil for no reminder
+	RemindBefore  *int `json:"remind_before_minutes"`
+	EscalateAfter *int `json:"escalate_after_minutes"`
+
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Location returns the time zone of the chat context, the default one when it is not set or unknown.
+func (s ChatContextSettings) Location() *time.Location {
+	return loctime.LoadOrDefault(s.TimeZone)
+}
+
+// ReportDelivery is how the closed report is sent to the chats: the summary
+// with the link to the table, with the XLSX file of the report or with both.
+type ReportDelivery string
+
+const (
+	DeliveryLink        ReportDelivery = "link"
+	DeliveryFile        ReportDelivery = "file"
+	DeliveryLinkAndFile ReportDelivery = "link_and_file"
+)
+
+func (d ReportDelivery) IsValid() bool {
+	switch d {
+	case DeliveryLink, DeliveryFile, DeliveryLinkAndFile:
+		return true
+	}
+
+	return false
+}
+
+// HasLink and HasFile tell what the delivery sends, an unknown delivery sends the link.
+func (d ReportDelivery) HasLink() bool {
+	return d != DeliveryFile
+}
+
+func (d ReportDelivery) HasFile() bool {
+	return d == DeliveryFile || d == DeliveryLinkAndFile
+}
+
+// RecognitionHints are the settings passed to Apollo with a message.
+type RecognitionHints struct {
+	Division   string   `json:"division,omitempty"`
+	Culture    string   `json:"culture,omitempty"`
+	Operations []string `json:"operations,omitempty"`
+	Hints      string   `json:"hints,omitempty"`
+}
+
+func (s ChatContextSettings) RecognitionHints() RecognitionHints {
+	return RecognitionHints{
+		Division:   s.DefaultDivision,
+		Culture:    s.DefaultCulture,
+		Operations: s.ExpectedOperations,
+		Hints:      s.PromptHints,
+	}
+}
+
+func (h RecognitionHints) IsZero() bool {
+	return h.Division == "" && h.Culture == "" && len(h.Operations) == 0 && h.Hints == ""
+}
@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// ReportExpectation is a division and an operation expected in every report
+// of the chat context, in the season when it is set.
+type ReportExpectation struct {
+	ID            int    `json:"id"`
+	ChatContextID int    `json:"chat_context_id"`
+	Division      string `json:"division"`
+	Operation     string `json:"operation"`
+	// SeasonFrom and SeasonTo are the first and the last days of the season
+	SeasonFrom *time.Time `json:"season_from,omitempty"`
+	SeasonTo   *time.Time `json:"season_to,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// IsActive reports whether the day is in the season of the expectation.
+func (e ReportExpectation) IsActive(day time.Time) bool {
+	day = truncateDay(day)
+
+	if e.SeasonFrom != nil && day.Before(truncateDay(*e.SeasonFrom)) {
+		return false
+	}
+	if e.SeasonTo != nil && day.After(truncateDay(*e.SeasonTo)) {
+		return false
+	}
+
+	return true
+}
+
+// Completeness is the coverage of the expectations of the chat context by the
+// lines of the report.
+type Completeness struct {
+	ReportID int `json:"report_id"`
+	Expected int `json:"expected"`
+	Covered  int `json:"covered"`
+
+	Missing []ExpectedItem `json:"missing"`
+}
+
+type ExpectedItem struct {
+	Division  string `json:"division"`
+	Operation string `json:"operation"`
+}
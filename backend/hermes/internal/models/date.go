@@ -0,0 +1,300 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LineDateLayout is the format of a single day of Line.Date.
+const LineDateLayout = "02.01.2006"
+
+// dateRangeSeparator separates the days of a range in String.
+const dateRangeSeparator = "-"
+
+// futureTolerance allows dates slightly ahead of the message, e.g. a report
+// sent just before midnight about the next day.
+const futureTolerance = 24 * time.Hour
+
+var ErrInvalidDate = errors.New("invalid date")
+
+// Date is the day, or the range of days, a report line is about. Days are
+// kept as midnight UTC. A Date unmarshalled from text that needs the message
+// time to be resolved, e.g. "15.05" or "вчера", is unresolved until Resolve.
+type Date struct {
+	From time.Time
+	To   time.Time
+
+	raw string
+}
+
+// NewDate returns the day of the moment in the time zone of t.
+func NewDate(t time.Time) Date {
+	day := truncateDay(t)
+	return Date{From: day, To: day}
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func (d Date) IsZero() bool {
+	return d.To.IsZero()
+}
+
+func (d Date) IsRange() bool {
+	return !d.From.Equal(d.To)
+}
+
+// Raw returns the text the date was unmarshalled from.
+func (d Date) Raw() string {
+	return d.raw
+}
+
+func (d Date) String() string {
+	if d.IsZero() {
+		return d.raw
+	}
+
+	if d.IsRange() {
+		return d.From.Format(LineDateLayout) + dateRangeSeparator + d.To.Format(LineDateLayout)
+	}
+
+	return d.To.Format(LineDateLayout)
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON accepts any string, dates that can not be parsed without the
+// message time are left unresolved.
+func (d *Date) UnmarshalJSON(data []byte) error {
+	var text string
+	err := json.Unmarshal(data, &text)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidDate, data)
+	}
+
+	parsed, err := ParseDate(text, time.Time{})
+	if err != nil {
+		*d = Date{raw: text}
+		return nil
+	}
+
+	parsed.raw = text
+	*d = parsed
+
+	return nil
+}
+
+// Resolve parses the unresolved date relative to the message time. Empty
+// dates become the day of the message in the time zone of the reference.
+func (d Date) Resolve(reference time.Time) (Date, error) {
+	if !d.IsZero() {
+		return d, nil
+	}
+
+	if strings.TrimSpace(d.raw) == "" {
+		date := NewDate(reference)
+		return date, nil
+	}
+
+	date, err := ParseDate(d.raw, reference)
+	if err != nil {
+		return d, err
+	}
+
+	date.raw = d.raw
+
+	return date, nil
+}
+
+var (
+	isoDatePattern   = regexp.MustCompile(`^(\d{4})-(\d{1,2})-(\d{1,2})$`)
+	dayPattern       = regexp.MustCompile(`^(\d{1,2})(?:[./](\d{1,2})(?:[./](\d{2}|\d{4}))?)?$`)
+	rangePattern     = regexp.MustCompile(`^(.+?)\s*[-–—]\s*(.+)$`)
+	monthNamePattern = regexp.MustCompile(`(\d{1,2})\s+([а-яё]+)\.?(?:\s+(\d{4}))?(?:\s*г(?:ода?)?\.?)?`)
+
+	relativeDays = map[string]int{
+		"сегодня":   0,
+		"вчера":     -1,
+		"позавчера": -2,
+	}
+
+	// month name stems in genitive, "15 мая"
+	monthStems = []struct {
+		stem  string
+		month time.Month
+	}{
+		{"янв", time.January}, {"фев", time.February}, {"мар", time.March},
+		{"апр", time.April}, {"мая", time.May}, {"май", time.May}, {"июн", time.June},
+		{"июл", time.July}, {"авг", time.August}, {"сен", time.September},
+		{"окт", time.October}, {"ноя", time.November}, {"дек", time.December},
+	}
+)
+
+// ParseDate parses the day or range of days of a report line: "2006-01-02",
+// "02.01.2006", "02.01.06", "02.01", "02", "2 января", "вчера", "15-16",
+// "15-16.05", "30.04-02.05". Missing month and year are taken from the
+// reference in its time zone so that the date is not in the future, a zero reference
+// allows only complete dates.
+func ParseDate(text string, reference time.Time) (Date, error) {
+	s := strings.ToLower(strings.TrimSpace(text))
+	for _, prefix := range []string{"за ", "на ", "от "} {
+		s = strings.TrimSpace(strings.TrimPrefix(s, prefix))
+	}
+	s = replaceMonthNames(s)
+
+	if s == "" {
+		return Date{}, fmt.Errorf("%w: empty", ErrInvalidDate)
+	}
+
+	var today time.Time
+	if !reference.IsZero() {
+		today = truncateDay(reference)
+	}
+
+	if offset, ok := relativeDays[s]; ok {
+		if today.IsZero() {
+			return Date{}, fmt.Errorf("%w: %q needs the message time", ErrInvalidDate, text)
+		}
+
+		day := today.AddDate(0, 0, offset)
+		return Date{From: day, To: day}, nil
+	}
+
+	if match := isoDatePattern.FindStringSubmatch(s); match != nil {
+		day, err := newDay(atoi(match[3]), atoi(match[2]), atoi(match[1]))
+		if err != nil {
+			return Date{}, fmt.Errorf("%w: %q", err, text)
+		}
+
+		return Date{From: day, To: day}, nil
+	}
+
+	fromText, toText := "", s
+	if match := rangePattern.FindStringSubmatch(s); match != nil {
+		fromText, toText = match[1], match[2]
+	}
+
+	to, err := parseDay(toText, 0, 0, today)
+	if err != nil {
+		return Date{}, fmt.Errorf("%w: %q", err, text)
+	}
+
+	if fromText == "" {
+		return Date{From: to, To: to}, nil
+	}
+
+	from, err := parseDay(fromText, to.Month(), to.Year(), time.Time{})
+	if err != nil {
+		return Date{}, fmt.Errorf("%w: %q", err, text)
+	}
+
+	// "30-02.05" starts in the previous month, "30.12-02.01" in the previous year
+	if from.After(to) {
+		switch strings.Count(fromText, ".") + strings.Count(fromText, "/") {
+		case 0:
+			previous := to.AddDate(0, 0, -to.Day())
+			from, err = newDay(from.Day(), int(previous.Month()), previous.Year())
+		case 1:
+			from, err = newDay(from.Day(), int(from.Month()), from.Year()-1)
+		}
+		if err != nil {
+			return Date{}, fmt.Errorf("%w: %q", err, text)
+		}
+	}
+
+	if from.After(to) {
+		return Date{}, fmt.Errorf("%w: %q starts after it ends", ErrInvalidDate, text)
+	}
+
+	return Date{From: from, To: to}, nil
+}
+
+// parseDay parses "DD", "DD.MM", "DD.MM.YY" or "DD.MM.YYYY". Missing parts
+// are taken from month and year if set, otherwise inferred from today.
+func parseDay(s string, month time.Month, year int, today time.Time) (time.Time, error) {
+	match := dayPattern.FindStringSubmatch(strings.TrimSpace(s))
+	if match == nil {
+		return time.Time{}, ErrInvalidDate
+	}
+
+	d := atoi(match[1])
+	m := time.Month(atoi(match[2]))
+	y := atoi(match[3])
+	if y > 0 && y < 100 {
+		y += 2000
+	}
+
+	if m == 0 {
+		m = month
+	}
+	if y == 0 {
+		y = year
+	}
+
+	if m != 0 && y != 0 {
+		return newDay(d, int(m), y)
+	}
+
+	if today.IsZero() {
+		return time.Time{}, fmt.Errorf("%w: the year is unknown", ErrInvalidDate)
+	}
+
+	if m != 0 {
+		// "31.12" in a message of January 1st is about the last year
+		day, err := newDay(d, int(m), today.Year())
+		if err == nil && day.Sub(today) > futureTolerance {
+			day, err = newDay(d, int(m), today.Year()-1)
+		}
+
+		return day, err
+	}
+
+	// "30" in a message of May 1st is about April
+	day, err := newDay(d, int(today.Month()), today.Year())
+	if err != nil || day.Sub(today) > futureTolerance {
+		previous := today.AddDate(0, 0, -today.Day())
+		day, err = newDay(d, int(previous.Month()), previous.Year())
+	}
+
+	return day, err
+}
+
+func newDay(d, m, y int) (time.Time, error) {
+	day := time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.UTC)
+	if day.Day() != d || int(day.Month()) != m || day.Year() != y {
+		return time.Time{}, fmt.Errorf("%w: %02d.%02d.%d does not exist", ErrInvalidDate, d, m, y)
+	}
+
+	return day, nil
+}
+
+func replaceMonthNames(s string) string {
+	return monthNamePattern.ReplaceAllStringFunc(s, func(match string) string {
+		parts := monthNamePattern.FindStringSubmatch(match)
+		for _, month := range monthStems {
+			if strings.HasPrefix(parts[2], month.stem) {
+				result := fmt.Sprintf("%s.%02d", parts[1], int(month.month))
+				if parts[3] != "" {
+					result += "." + parts[3]
+				}
+
+				return result
+			}
+		}
+
+		return match
+	})
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"time"
+)
+
+type Dictionary string
+
+const (
+	DictionaryCultures   Dictionary = "cultures"
+	DictionaryOperations Dictionary = "operations"
+	DictionaryUnits      Dictionary = "units"
+)
+
+var Dictionaries = []Dictionary{DictionaryCultures, DictionaryOperations, DictionaryUnits}
+
+func (d Dictionary) IsValid() bool {
+	for _, dictionary := range Dictionaries {
+		if d == dictionary {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Alias is an alternative spelling of a dictionary entry. Aliases learned
+// in a chat context apply only to it until they are approved as global.
+type Alias struct {
+	ID         int        `json:"id"`
+	Dictionary Dictionary `json:"dictionary"`
+	Alias      string     `json:"alias"`
+	// Value is the dictionary entry: culture or operation name, division for units
+	Value         string     `json:"value"`
+	ChatContextID *int       `json:"chat_context_id"`
+	Global        bool       `json:"global"`
+	Source        string     `json:"source"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ApprovedAt    *time.Time `json:"approved_at"`
+}
+
+const (
+	AliasSourceAdmin = "admin"
+	AliasSourceBot   = "bot"
+	AliasSourceSheet = "sheet"
+)
+
+// DictionaryEntry is a version of a culture, operation or unit. An update
+// closes the current version and opens a new one, so reports are checked
+// against the entries in force on their date.
+type DictionaryEntry struct {
+	ID         int        `json:"id"`
+	Dictionary Dictionary `json:"dictionary"`
+	// Name is the culture or operation name, division for units
+	Name string `json:"name"`
+	// Note and ExpectedUnit, the measure unit code of the gross values,
+	// are set for operations only
+	Note         string `json:"note,omitempty"`
+	ExpectedUnit string `json:"expected_unit,omitempty"`
+	// PU, Department and Area (hectares) are set for units only
+	PU         string   `json:"pu,omitempty"`
+	Department string   `json:"department,omitempty"`
+	Area       *float64 `json:"area,omitempty"`
+
+	EffectiveFrom time.Time  `json:"effective_from"`
+	DeletedAt     *time.Time `json:"deleted_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// IsActiveAt reports whether the version is in force on the date.
+func (e DictionaryEntry) IsActiveAt(at time.Time) bool {
+	return !e.EffectiveFrom.After(at) && (e.DeletedAt == nil || e.DeletedAt.After(at))
+}
+
+type DictionaryAction string
+
+const (
+	DictionaryActionCreate DictionaryAction = "create"
+	DictionaryActionUpdate DictionaryAction = "update"
+	DictionaryActionDelete DictionaryAction = "delete"
+)
+
+// DictionaryChange is a record of the dictionary history.
+type DictionaryChange struct {
+	ID         int              `json:"id"`
+	Dictionary Dictionary       `json:"dictionary"`
+	EntryID    int              `json:"entry_id"`
+	Action     DictionaryAction `json:"action"`
+	Entry      DictionaryEntry  `json:"entry"`
+	ChangedBy  string           `json:"changed_by"`
+	ChangedAt  time.Time        `json:"changed_at"`
+}
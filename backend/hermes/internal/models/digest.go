@@ -0,0 +1,59 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Digest totals the reports of all chat contexts closed in the period.
+type Digest struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+
+	// Reports and ChatContexts are the approved reports and their chat contexts
+	Reports      int      `json:"reports"`
+	ChatContexts []string `json:"chat_contexts"`
+	// Unreviewed are the closed reports left out as not approved
+	Unreviewed int `json:"unreviewed"`
+
+	Rows []DigestRow `json:"rows"`
+
+	Lines        int `json:"lines"`
+	Unrecognized int `json:"unrecognized"`
+	Flagged      int `json:"flagged"`
+}
+
+// DigestRow is the total of a division, an operation and a culture. The
+// deltas are the differences from the same totals of the previous period.
+type DigestRow struct {
+	Division  string `json:"division"`
+	Operation string `json:"operation"`
+	Culture   string `json:"culture"`
+
+	PerDay       float64 `json:"per_day"`
+	PerOperation float64 `json:"per_operation"`
+	ValDay       float64 `json:"val_day"`
+	ValBeginning float64 `json:"val_beginning"`
+	ValUnit      string  `json:"val_unit"`
+
+	PerDayDelta float64 `json:"per_day_delta"`
+	ValDayDelta float64 `json:"val_day_delta"`
+}
+
+// DigestRecipient receives the digest in a chat or by email.
+type DigestRecipient struct {
+	ID        int       `json:"id"`
+	ChatID    *int      `json:"chat_id,omitempty"`
+	Email     string    `json:"email,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+var ErrRecipientInvalid = errors.New("recipient must have either a chat or an email")
+
+func (r DigestRecipient) Validate() error {
+	if (r.ChatID == nil) == (r.Email == "") {
+		return ErrRecipientInvalid
+	}
+
+	return nil
+}
@@ -0,0 +1,13 @@
+package models
+
+// ValidationLimit is a sanity range for report lines of the operation and
+// culture; nil Operation or Culture matches any. Nil bounds are not set.
+type ValidationLimit struct {
+	Operation *string
+	Culture   *string
+
+	MaxPerDay *float64
+	// MinYield and MaxYield are in c/ha
+	MinYield *float64
+	MaxYield *float64
+}
@@ -0,0 +1,49 @@
+package models
+
+import "strings"
+
+type MeasureDimension string
+
+const (
+	MeasureArea   MeasureDimension = "area"
+	MeasureMass   MeasureDimension = "mass"
+	MeasureVolume MeasureDimension = "volume"
+	MeasureCount  MeasureDimension = "count"
+)
+
+// Units the line values are kept in unless the operation expects another one.
+const (
+	AreaUnit = "га"
+	MassUnit = "ц"
+)
+
+// MeasureUnit is a unit of measure of reported quantities. Factor converts
+// a value to the base unit of the dimension: га, ц, л or шт.
+type MeasureUnit struct {
+	Code      string           `json:"code"`
+	Dimension MeasureDimension `json:"dimension"`
+	Factor    float64          `json:"factor"`
+	Aliases   []string         `json:"aliases"`
+}
+
+// Matches reports whether the text is the code or one of the aliases.
+func (u MeasureUnit) Matches(text string) bool {
+	text = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(text)), ".")
+	if text == strings.ToLower(u.Code) {
+		return true
+	}
+
+	for _, alias := range u.Aliases {
+		if text == alias {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Measure is a value as reported, before it was converted.
+type Measure struct {
+	Value float64 `json:"value"`
+	Unit  string  `json:"unit"`
+}
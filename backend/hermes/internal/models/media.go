@@ -0,0 +1,79 @@
+package models
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+// Media is a photo or an audio of a message spooled to a temporary file.
+// Preprocessing, Apollo and the uploads read it from the disk one after
+// another, so the file is never held in memory as a whole.
+type Media struct {
+	Path string
+	Size int64
+
+	// MIME and Extension are detected from the beginning of the file,
+	// the extension is empty or starts with a dot.
+	MIME      string
+	Extension string
+}
+
+// DownloadMedia creates a temporary file and lets download write the media
+// into it. The file is removed when the download fails.
+func DownloadMedia(download func(file *os.File) error) (Media, error) {
+	file, err := os.CreateTemp("", "hermes-media-*")
+	if err != nil {
+		return Media{}, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer file.Close()
+
+	media := Media{Path: file.Name()}
+
+	err = download(file)
+	if err != nil {
+		media.Remove()
+		return Media{}, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		media.Remove()
+		return Media{}, fmt.Errorf("failed to stat media: %w", err)
+	}
+	media.Size = info.Size()
+
+	_, err = file.Seek(0, io.SeekStart)
+	if err != nil {
+		media.Remove()
+		return Media{}, fmt.Errorf("failed to rewind media: %w", err)
+	}
+
+	mime, err := mimetype.DetectReader(file)
+	if err != nil {
+		media.Remove()
+		return Media{}, fmt.Errorf("failed to detect media type: %w", err)
+	}
+	media.MIME = mime.String()
+	media.Extension = mime.Extension()
+
+	return media, nil
+}
+
+// SpoolMedia copies the media read from r into a temporary file.
+func SpoolMedia(r io.Reader) (Media, error) {
+	return DownloadMedia(func(file *os.File) error {
+		_, err := io.Copy(file, r)
+		return err
+	})
+}
+
+func (m Media) Open() (*os.File, error) {
+	return os.Open(m.Path)
+}
+
+func (m Media) Remove() error {
+	return os.Remove(m.Path)
+}
@@ -18,11 +18,11 @@ type TextMessage struct {
 type ImageMessage struct {
 	TextMessage
 
-	Image []byte
+	Image Media
 }
 
 type AudioMessage struct {
 	TextMessage
 
-	Audio []byte
+	Audio Media
 }
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// ProgressKey identifies a running total of report lines.
+type ProgressKey struct {
+	Season    int
+	Division  string
+	Operation string
+	Culture   string
+}
+
+// ProgressEntry is a report line recorded in the progress ledger. Reported
+// values are nil when the line had no cumulative value.
+type ProgressEntry struct {
+	ID int
+
+	ProgressKey
+
+	Date      time.Time
+	MessageID int
+
+	PerDay               float64
+	PerOperationReported *float64
+	PerOperationComputed float64
+
+	ValDay               float64
+	ValBeginningReported *float64
+	ValBeginningComputed float64
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Contact is a worker with the messenger accounts to write to directly.
+type Contact struct {
+	WorkerID    int      `json:"worker_id"`
+	WorkerName  string   `json:"worker_name"`
+	WhatsappIDs []string `json:"whatsapp_ids"`
+	TelegramIDs []string `json:"telegram_ids"`
+}
+
+// ExpectedReporter is a worker expected to report in the chat context before
+// every cutoff, the division is what the worker reports on.
+type ExpectedReporter struct {
+	ID            int    `json:"id"`
+	ChatContextID int    `json:"chat_context_id"`
+	Division      string `json:"division"`
+	Contact
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ReminderKind is who is reminded: the expected reporters who have not
+// reported or, at the second deadline, the listeners of the chats.
+type ReminderKind string
+
+const (
+	ReminderRemind   ReminderKind = "remind"
+	ReminderEscalate ReminderKind = "escalate"
+)
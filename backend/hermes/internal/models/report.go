@@ -7,20 +7,106 @@ import (
 )
 
 type Report struct {
-	ID            int
-	ChatContextID int
-	StartedAt     time.Time
-	LastUpdatedAt time.Time
-	FinishedAt    *time.Time
+	ID            int        `json:"id"`
+	ChatContextID int        `json:"chat_context_id"`
+	StartedAt     time.Time  `json:"started_at"`
+	LastUpdatedAt time.Time  `json:"last_updated_at"`
+	FinishedAt    *time.Time `json:"finished_at,omitempty"`
+	// ClosesAt is the cutoff of the report's schedule window
+	ClosesAt *time.Time `json:"closes_at,omitempty"`
+	// TimeZone is the time zone of the chat context when the report was
+	// opened, the report's files are named in it
+	TimeZone string `json:"time_zone"`
+	// Status is the review state of the report
+	Status ReportStatus `json:"status"`
+}
+
+// ReportStatus is the review state of a report: it is collecting while open,
+// a closed report is approved at once or waits for the review when the chat
+// context requires it. A rejected report is sent back to be corrected and
+// can be approved later.
+type ReportStatus string
+
+const (
+	StatusCollecting    ReportStatus = "collecting"
+	StatusPendingReview ReportStatus = "pending_review"
+	StatusApproved      ReportStatus = "approved"
+	StatusRejected      ReportStatus = "rejected"
+)
+
+// IsReviewable reports whether the report can be approved, rejected or edited.
+func (s ReportStatus) IsReviewable() bool {
+	return s == StatusPendingReview || s == StatusRejected
+}
+
+// Location returns the time zone of the report.
+func (r *Report) Location() *time.Location {
+	return loctime.LoadOrDefault(r.TimeZone)
 }
 
 func (r *Report) IsFinished() bool {
 	return r.FinishedAt != nil
 }
 
-func (r *Report) IsNeedToFinish(finishHour int) bool {
-	now := loctime.Transfer(time.Now())
-	now = now.Truncate(time.Hour * 24)
+// IsNeedToFinish reports whether the cutoff of the report has passed.
+func (r *Report) IsNeedToFinish(now time.Time) bool {
+	return r.ClosesAt != nil && !now.Before(*r.ClosesAt)
+}
+
+// ReportDetails is the report with everything registered in it.
+type ReportDetails struct {
+	Report
+	ChatContextName string `json:"chat_context_name"`
+
+	Messages []ReportMessage `json:"messages"`
+	// Lines are the last version of the lines of every message
+	Lines []ReportLine `json:"lines"`
+	// Reviews are the review actions on the report, oldest first
+	Reviews []ReportReview `json:"reviews"`
+}
+
+type ReviewAction string
+
+const (
+	ReviewApprove ReviewAction = "approve"
+	ReviewReject  ReviewAction = "reject"
+	ReviewEdit    ReviewAction = "edit"
+)
+
+// ReportReview is an action of a reviewer on the report, the comment of an
+// edit describes the changed line.
+type ReportReview struct {
+	ID         int          `json:"id"`
+	ReportID   int          `json:"report_id"`
+	WorkerID   int          `json:"worker_id"`
+	WorkerName string       `json:"worker_name"`
+	Action     ReviewAction `json:"action"`
+	Comment    string       `json:"comment"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+type ReportMessage struct {
+	ID         int       `json:"id"`
+	WorkerID   int       `json:"worker_id"`
+	WorkerName string    `json:"worker_name"`
+	ChatID     int       `json:"chat_id"`
+	ChatType   string    `json:"chat_type"`
+	ChatName   string    `json:"chat_name"`
+	CreatedAt  time.Time `json:"created_at"`
+	Content    string    `json:"content"`
+	Role       string    `json:"role"`
+
+	Images []string `json:"images"`
+	Audios []string `json:"audios"`
+}
+
+type ReportLine struct {
+	ID          int  `json:"id"`
+	MessageID   int  `json:"message_id"`
+	Number      int  `json:"line_number"`
+	Version     int  `json:"version"`
+	OperationID *int `json:"operation_id,omitempty"`
+	CultureID   *int `json:"culture_id,omitempty"`
 
-	return now.Hour() >= finishHour
+	Line
 }
@@ -0,0 +1,397 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is the list of cutoffs closing the reports of a chat context.
+// A cutoff is a cron expression "minute hour day month weekday", e.g.
+// "0 9,18 * * *", or a shorthand: "09:00" every day, "пн 09:00" every week.
+type Schedule struct {
+	cutoffs []cutoff
+}
+
+// searchDays bounds the search of a cutoff, it covers cutoffs on February 29.
+const searchDays = 366*4 + 1
+
+var ErrScheduleEmpty = errors.New("schedule has no cutoffs")
+
+// ParseSchedule parses the cutoffs, the schedule must fire at least once in four years.
+func ParseSchedule(expressions []string) (Schedule, error) {
+	var schedule Schedule
+
+	for _, expression := range expressions {
+		expression = strings.TrimSpace(expression)
+		if expression == "" {
+			continue
+		}
+
+		c, err := parseCutoff(expression)
+		if err != nil {
+			return Schedule{}, fmt.Errorf("invalid cutoff %q: %w", expression, err)
+		}
+
+		if _, ok := c.next(time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)); !ok {
+			return Schedule{}, fmt.Errorf("invalid cutoff %q: never happens", expression)
+		}
+
+		schedule.cutoffs = append(schedule.cutoffs, c)
+	}
+
+	if len(schedule.cutoffs) == 0 {
+		return Schedule{}, ErrScheduleEmpty
+	}
+
+	return schedule, nil
+}
+
+// DailySchedule closes reports every day at the hour.
+func DailySchedule(hour int) Schedule {
+	return Schedule{cutoffs: []cutoff{{
+		minutes:    bits(0),
+		hours:      bits(hour),
+		days:       allBits(1, 31),
+		months:     allBits(1, 12),
+		weekdays:   allBits(0, 6),
+		anyDay:     true,
+		anyWeekday: true,
+	}}}
+}
+
+func (s Schedule) IsZero() bool {
+	return len(s.cutoffs) == 0
+}
+
+// Next returns the first cutoff after t in the location of t.
+func (s Schedule) Next(t time.Time) (time.Time, bool) {
+	var next time.Time
+	found := false
+
+	for _, c := range s.cutoffs {
+		at, ok := c.next(t)
+		if ok && (!found || at.Before(next)) {
+			next, found = at, true
+		}
+	}
+
+	return next, found
+}
+
+// Prev returns the last cutoff at or before t in the location of t.
+func (s Schedule) Prev(t time.Time) (time.Time, bool) {
+	var prev time.Time
+	found := false
+
+	for _, c := range s.cutoffs {
+		at, ok := c.prev(t)
+		if ok && (!found || at.After(prev)) {
+			prev, found = at, true
+		}
+	}
+
+	return prev, found
+}
+
+// Window returns the cutoffs around t: the report with a message sent at t
+// is opened by the first one and closed by the second one.
+func (s Schedule) Window(t time.Time) (time.Time, time.Time, bool) {
+	to, ok := s.Next(t)
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+
+	from, ok := s.Prev(t)
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+
+	return from, to, true
+}
+
+// cutoff is a parsed cron expression, every field is a bit set of the allowed values.
+type cutoff struct {
+	minutes  uint64
+	hours    uint64
+	days     uint64
+	months   uint64
+	weekdays uint64
+
+	// anyDay and anyWeekday are set for "*", cron matches either day or
+	// weekday when both of them are restricted
+	anyDay     bool
+	anyWeekday bool
+}
+
+var weekdayNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	"вс": 0, "пн": 1, "вт": 2, "ср": 3, "чт": 4, "пт": 5, "сб": 6,
+}
+
+var monthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+func parseCutoff(expression string) (cutoff, error) {
+	fields := strings.Fields(strings.ToLower(expression))
+
+	switch len(fields) {
+	case 1, 2:
+		return parseShorthand(fields)
+	case 5:
+	default:
+		return cutoff{}, fmt.Errorf("expected 5 fields, got %d", len(fields))
+	}
+
+	var c cutoff
+	var err error
+
+	c.minutes, err = parseField(fields[0], 0, 59, nil)
+	if err != nil {
+		return cutoff{}, fmt.Errorf("minute: %w", err)
+	}
+
+	c.hours, err = parseField(fields[1], 0, 23, nil)
+	if err != nil {
+		return cutoff{}, fmt.Errorf("hour: %w", err)
+	}
+
+	c.days, err = parseField(fields[2], 1, 31, nil)
+	if err != nil {
+		return cutoff{}, fmt.Errorf("day: %w", err)
+	}
+
+	c.months, err = parseField(fields[3], 1, 12, monthNames)
+	if err != nil {
+		return cutoff{}, fmt.Errorf("month: %w", err)
+	}
+
+	// 7 is Sunday as well as 0
+	c.weekdays, err = parseField(fields[4], 0, 7, weekdayNames)
+	if err != nil {
+		return cutoff{}, fmt.Errorf("weekday: %w", err)
+	}
+	if c.weekdays&bits(7) != 0 {
+		c.weekdays = c.weekdays&^bits(7) | bits(0)
+	}
+
+	c.anyDay = fields[2] == "*"
+	c.anyWeekday = fields[4] == "*"
+
+	return c, nil
+}
+
+// parseShorthand parses "HH:MM" and "<weekday> HH:MM".
+func parseShorthand(fields []string) (cutoff, error) {
+	c := cutoff{
+		days:       allBits(1, 31),
+		months:     allBits(1, 12),
+		weekdays:   allBits(0, 6),
+		anyDay:     true,
+		anyWeekday: true,
+	}
+
+	clock := fields[len(fields)-1]
+	if len(fields) == 2 {
+		weekday, ok := weekdayNames[fields[0]]
+		if !ok {
+			return cutoff{}, fmt.Errorf("unknown weekday %q", fields[0])
+		}
+
+		c.weekdays = bits(weekday)
+		c.anyWeekday = false
+	}
+
+	hourText, minuteText, ok := strings.Cut(clock, ":")
+	if !ok {
+		return cutoff{}, fmt.Errorf("expected HH:MM, got %q", clock)
+	}
+
+	hour, err := strconv.Atoi(hourText)
+	if err != nil || hour < 0 || hour > 23 {
+		return cutoff{}, fmt.Errorf("invalid hour %q", hourText)
+	}
+
+	minute, err := strconv.Atoi(minuteText)
+	if err != nil || minute < 0 || minute > 59 {
+		return cutoff{}, fmt.Errorf("invalid minute %q", minuteText)
+	}
+
+	c.hours = bits(hour)
+	c.minutes = bits(minute)
+
+	return c, nil
+}
+
+// parseField parses a comma separated list of "*", "a", "a-b", each with an optional "/step".
+func parseField(field string, min, max int, names map[string]int) (uint64, error) {
+	var set uint64
+
+	for _, part := range strings.Split(field, ",") {
+		rangeText, stepText, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepText)
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepText)
+			}
+		}
+
+		from, to := min, max
+		if rangeText != "*" {
+			fromText, toText, isRange := strings.Cut(rangeText, "-")
+
+			var err error
+			from, err = parseValue(fromText, min, max, names)
+			if err != nil {
+				return 0, err
+			}
+
+			to = from
+			if isRange {
+				to, err = parseValue(toText, min, max, names)
+				if err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				to = max
+			}
+
+			if from > to {
+				return 0, fmt.Errorf("invalid range %q", rangeText)
+			}
+		}
+
+		for value := from; value <= to; value += step {
+			set |= bits(value)
+		}
+	}
+
+	return set, nil
+}
+
+func parseValue(text string, min, max int, names map[string]int) (int, error) {
+	if value, ok := names[text]; ok {
+		return value, nil
+	}
+
+	value, err := strconv.Atoi(text)
+	if err != nil || value < min || value > max {
+		return 0, fmt.Errorf("invalid value %q", text)
+	}
+
+	return value, nil
+}
+
+func bits(value int) uint64 {
+	return 1 << uint(value)
+}
+
+func allBits(from, to int) uint64 {
+	var set uint64
+	for value := from; value <= to; value++ {
+		set |= bits(value)
+	}
+
+	return set
+}
+
+func (c cutoff) matchesDay(day time.Time) bool {
+	if c.months&bits(int(day.Month())) == 0 {
+		return false
+	}
+
+	dayMatches := c.days&bits(day.Day()) != 0
+	weekdayMatches := c.weekdays&bits(int(day.Weekday())) != 0
+
+	switch {
+	case c.anyDay && c.anyWeekday:
+		return true
+	case c.anyDay:
+		return weekdayMatches
+	case c.anyWeekday:
+		return dayMatches
+	}
+
+	return dayMatches || weekdayMatches
+}
+
+// times returns the cutoffs of the day in the location in ascending order,
+// the date of the day is taken in UTC.
+func (c cutoff) times(day time.Time, loc *time.Location) []time.Time {
+	if !c.matchesDay(day) {
+		return nil
+	}
+
+	var times []time.Time
+	for hour := 0; hour < 24; hour++ {
+		if c.hours&bits(hour) == 0 {
+			continue
+		}
+
+		for minute := 0; minute < 60; minute++ {
+			if c.minutes&bits(minute) == 0 {
+				continue
+			}
+
+			at := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, loc)
+
+			// the clock time missing on the day of the DST change is moved
+			// forward by the gap, e.g. 02:30 is 03:30 when 02:00 becomes 03:00
+			if behind := wallClock(day, hour, minute).Sub(wallClock(at, at.Hour(), at.Minute())); behind > 0 {
+				at = at.Add(behind)
+			}
+
+			times = append(times, at)
+		}
+	}
+
+	slices.SortFunc(times, func(a, b time.Time) int { return a.Compare(b) })
+
+	return slices.CompactFunc(times, time.Time.Equal)
+}
+
+// wallClock returns the clock time of the day as if it were in UTC.
+func wallClock(day time.Time, hour, minute int) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, time.UTC)
+}
+
+func (c cutoff) next(t time.Time) (time.Time, bool) {
+	// the days are counted in UTC, the midnight of the day may be missing in
+	// the location of t
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i <= searchDays; i++ {
+		for _, at := range c.times(day.AddDate(0, 0, i), t.Location()) {
+			if at.After(t) {
+				return at, true
+			}
+		}
+	}
+
+	return time.Time{}, false
+}
+
+func (c cutoff) prev(t time.Time) (time.Time, bool) {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i <= searchDays; i++ {
+		times := c.times(day.AddDate(0, 0, -i), t.Location())
+		slices.Reverse(times)
+
+		for _, at := range times {
+			if !at.After(t) {
+				return at, true
+			}
+		}
+	}
+
+	return time.Time{}, false
+}
@@ -0,0 +1,245 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseScheduleErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		expressions []string
+	}{
+		{name: "empty", expressions: nil},
+		{name: "blank", expressions: []string{" ", ""}},
+		{name: "fields count", expressions: []string{"0 9 * *"}},
+		{name: "minute out of range", expressions: []string{"60 9 * * *"}},
+		{name: "hour out of range", expressions: []string{"0 24 * * *"}},
+		{name: "day out of range", expressions: []string{"0 9 0 * *"}},
+		{name: "month out of range", expressions: []string{"0 9 * 13 *"}},
+		{name: "weekday out of range", expressions: []string{"0 9 * * 8"}},
+		{name: "reversed range", expressions: []string{"0 18-9 * * *"}},
+		{name: "zero step", expressions: []string{"*/0 9 * * *"}},
+		{name: "invalid step", expressions: []string{"*/x 9 * * *"}},
+		{name: "unknown name", expressions: []string{"0 9 * abc *"}},
+		{name: "never happens", expressions: []string{"0 9 31 feb *"}},
+		{name: "shorthand without minutes", expressions: []string{"09"}},
+		{name: "shorthand hour", expressions: []string{"25:00"}},
+		{name: "shorthand minute", expressions: []string{"09:60"}},
+		{name: "shorthand weekday", expressions: []string{"xx 09:00"}},
+		{name: "one invalid of several", expressions: []string{"09:00", "0 9 * *"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSchedule(tt.expressions)
+			if err == nil {
+				t.Fatalf("ParseSchedule(%q) error = nil, want error", tt.expressions)
+			}
+		})
+	}
+}
+
+func TestScheduleWindow(t *testing.T) {
+	utc := time.UTC
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone database is not available: %v", err)
+	}
+
+	santiago, err := time.LoadLocation("America/Santiago")
+	if err != nil {
+		t.Skipf("time zone database is not available: %v", err)
+	}
+
+	date := func(loc *time.Location, year int, month time.Month, day, hour, minute int) time.Time {
+		return time.Date(year, month, day, hour, minute, 0, 0, loc)
+	}
+
+	tests := []struct {
+		name        string
+		expressions []string
+		at          time.Time
+		from        time.Time
+		to          time.Time
+	}{
+		{
+			name:        "daily",
+			expressions: []string{"09:00"},
+			at:          date(utc, 2026, time.May, 10, 12, 0),
+			from:        date(utc, 2026, time.May, 10, 9, 0),
+			to:          date(utc, 2026, time.May, 11, 9, 0),
+		},
+		{
+			name:        "daily at the cutoff opens the next window",
+			expressions: []string{"09:00"},
+			at:          date(utc, 2026, time.May, 10, 9, 0),
+			from:        date(utc, 2026, time.May, 10, 9, 0),
+			to:          date(utc, 2026, time.May, 11, 9, 0),
+		},
+		{
+			name:        "twice a day, morning",
+			expressions: []string{"0 9,18 * * *"},
+			at:          date(utc, 2026, time.May, 10, 7, 30),
+			from:        date(utc, 2026, time.May, 9, 18, 0),
+			to:          date(utc, 2026, time.May, 10, 9, 0),
+		},
+		{
+			name:        "twice a day, afternoon",
+			expressions: []string{"12:00", "20:00"},
+			at:          date(utc, 2026, time.May, 10, 15, 0),
+			from:        date(utc, 2026, time.May, 10, 12, 0),
+			to:          date(utc, 2026, time.May, 10, 20, 0),
+		},
+		{
+			name:        "weekly shorthand",
+			expressions: []string{"пн 09:00"},
+			// Wednesday
+			at:   date(utc, 2026, time.May, 13, 10, 0),
+			from: date(utc, 2026, time.May, 11, 9, 0),
+			to:   date(utc, 2026, time.May, 18, 9, 0),
+		},
+		{
+			name:        "weekday range",
+			expressions: []string{"0 9 * * mon-fri"},
+			// Saturday
+			at:   date(utc, 2026, time.May, 16, 10, 0),
+			from: date(utc, 2026, time.May, 15, 9, 0),
+			to:   date(utc, 2026, time.May, 18, 9, 0),
+		},
+		{
+			name:        "sunday as 7",
+			expressions: []string{"0 9 * * 7"},
+			at:          date(utc, 2026, time.May, 13, 10, 0),
+			from:        date(utc, 2026, time.May, 10, 9, 0),
+			to:          date(utc, 2026, time.May, 17, 9, 0),
+		},
+		{
+			name:        "hour step",
+			expressions: []string{"0 */6 * * *"},
+			at:          date(utc, 2026, time.May, 10, 13, 0),
+			from:        date(utc, 2026, time.May, 10, 12, 0),
+			to:          date(utc, 2026, time.May, 10, 18, 0),
+		},
+		{
+			name:        "stepped range",
+			expressions: []string{"30 8-20/4 * * *"},
+			at:          date(utc, 2026, time.May, 10, 21, 0),
+			from:        date(utc, 2026, time.May, 10, 20, 30),
+			to:          date(utc, 2026, time.May, 11, 8, 30),
+		},
+		{
+			name:        "value with step runs to the maximum",
+			expressions: []string{"0 20/2 * * *"},
+			at:          date(utc, 2026, time.May, 10, 23, 0),
+			from:        date(utc, 2026, time.May, 10, 22, 0),
+			to:          date(utc, 2026, time.May, 11, 20, 0),
+		},
+		{
+			name:        "day or weekday when both are restricted",
+			expressions: []string{"0 9 1 * mon"},
+			// Friday, May 1 and Monday, May 4
+			at:   date(utc, 2026, time.May, 2, 10, 0),
+			from: date(utc, 2026, time.May, 1, 9, 0),
+			to:   date(utc, 2026, time.May, 4, 9, 0),
+		},
+		{
+			name:        "day only when weekday is any",
+			expressions: []string{"0 9 1 * *"},
+			at:          date(utc, 2026, time.May, 2, 10, 0),
+			from:        date(utc, 2026, time.May, 1, 9, 0),
+			to:          date(utc, 2026, time.June, 1, 9, 0),
+		},
+		{
+			name:        "month names",
+			expressions: []string{"0 9 15 apr,oct *"},
+			at:          date(utc, 2026, time.May, 10, 10, 0),
+			from:        date(utc, 2026, time.April, 15, 9, 0),
+			to:          date(utc, 2026, time.October, 15, 9, 0),
+		},
+		{
+			name:        "february 29 looks four years ahead",
+			expressions: []string{"0 9 29 2 *"},
+			at:          date(utc, 2024, time.March, 1, 0, 0),
+			from:        date(utc, 2024, time.February, 29, 9, 0),
+			to:          date(utc, 2028, time.February, 29, 9, 0),
+		},
+		{
+			name:        "february 29 looks four years back",
+			expressions: []string{"0 9 29 2 *"},
+			at:          date(utc, 2027, time.June, 1, 0, 0),
+			from:        date(utc, 2024, time.February, 29, 9, 0),
+			to:          date(utc, 2028, time.February, 29, 9, 0),
+		},
+		{
+			name:        "time zone of the moment",
+			expressions: []string{"09:00"},
+			at:          date(newYork, 2026, time.May, 10, 8, 0),
+			from:        date(newYork, 2026, time.May, 9, 9, 0),
+			to:          date(newYork, 2026, time.May, 10, 9, 0),
+		},
+		{
+			name:        "DST gap moves the cutoff forward",
+			expressions: []string{"30 2 * * *"},
+			at:          date(newYork, 2026, time.March, 8, 1, 0),
+			from:        date(newYork, 2026, time.March, 7, 2, 30),
+			// 02:30 does not exist on March 8, clocks jump from 02:00 to 03:00
+			to: time.Date(2026, time.March, 8, 7, 30, 0, 0, utc).In(newYork),
+		},
+		{
+			name:        "DST gap cutoff merges with the next hour",
+			expressions: []string{"30 2,3 * * *"},
+			at:          date(newYork, 2026, time.March, 8, 3, 45),
+			from:        time.Date(2026, time.March, 8, 7, 30, 0, 0, utc).In(newYork),
+			to:          date(newYork, 2026, time.March, 9, 2, 30),
+		},
+		{
+			name:        "DST gap at midnight keeps the day",
+			expressions: []string{"00:15"},
+			at:          date(santiago, 2026, time.September, 5, 12, 0),
+			from:        date(santiago, 2026, time.September, 5, 0, 15),
+			// September 6 starts at 01:00
+			to: date(santiago, 2026, time.September, 6, 1, 15),
+		},
+		{
+			name:        "DST overlap fires once",
+			expressions: []string{"30 1 * * *"},
+			at:          date(newYork, 2026, time.November, 1, 1, 45),
+			from:        time.Date(2026, time.November, 1, 5, 30, 0, 0, utc).In(newYork),
+			to:          date(newYork, 2026, time.November, 2, 1, 30),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := ParseSchedule(tt.expressions)
+			if err != nil {
+				t.Fatalf("ParseSchedule(%q) error = %v", tt.expressions, err)
+			}
+
+			from, to, ok := schedule.Window(tt.at)
+			if !ok {
+				t.Fatalf("Window(%v) found no window", tt.at)
+			}
+			if !from.Equal(tt.from) || !to.Equal(tt.to) {
+				t.Errorf("Window(%v) = %v — %v, want %v — %v", tt.at, from, to, tt.from, tt.to)
+			}
+		})
+	}
+}
+
+func TestDailySchedule(t *testing.T) {
+	schedule := DailySchedule(9)
+
+	at := time.Date(2026, time.May, 10, 9, 0, 0, 0, time.UTC)
+
+	next, ok := schedule.Next(at)
+	if !ok || !next.Equal(at.AddDate(0, 0, 1)) {
+		t.Errorf("Next(%v) = %v, %v, want %v", at, next, ok, at.AddDate(0, 0, 1))
+	}
+
+	prev, ok := schedule.Prev(at)
+	if !ok || !prev.Equal(at) {
+		t.Errorf("Prev(%v) = %v, %v, want %v", at, prev, ok, at)
+	}
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// ReportSummary is the digest of a report sent to the chats.
+type ReportSummary struct {
+	ReportID        int       `json:"report_id"`
+	ChatContextName string    `json:"chat_context_name"`
+	From            time.Time `json:"from"`
+	To              time.Time `json:"to"`
+
+	Groups []SummaryGroup `json:"groups"`
+
+	Lines int `json:"lines"`
+	// Unrecognized lines have an operation or a culture missing in the dictionaries
+	Unrecognized int `json:"unrecognized"`
+	// Flagged lines have validation flags
+	Flagged int `json:"flagged"`
+
+	// Expected is the number of the divisions and the operations expected in
+	// the report, Missing are the expected ones it lacks
+	Expected int            `json:"expected"`
+	Missing  []ExpectedItem `json:"missing"`
+
+	// Workers are the names of the workers who reported, in the order of their first message
+	Workers []string `json:"workers"`
+}
+
+// SummaryGroup is the total of the report lines of an operation and a culture.
+type SummaryGroup struct {
+	Operation string `json:"operation"`
+	Culture   string `json:"culture"`
+
+	PerDay       float64 `json:"per_day"`
+	PerOperation float64 `json:"per_operation"`
+	ValDay       float64 `json:"val_day"`
+	ValBeginning float64 `json:"val_beginning"`
+	ValUnit      string  `json:"val_unit"`
+}
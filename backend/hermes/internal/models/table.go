@@ -4,32 +4,127 @@ import (
 	"fmt"
 	"strings"
 	"time"
-
-	"github.com/lild1tz/llm_coding_challenge/backend/libs/go/loctime"
 )
 
 type Line struct {
-	Date string `json:"date"`
+	Date    Date   `json:"date"`
+	DateRaw string `json:"date_raw,omitempty"`
+
+	// *Raw is the value recognized by Apollo, *Suggestion is the closest
+	// dictionary entry when the value could not be matched with confidence.
+	// *Yellow marks the value to highlight in the sheet.
+
+	Division           string `json:"division"`
+	DivisionRaw        string `json:"division_raw,omitempty"`
+	DivisionSuggestion string `json:"division_suggestion,omitempty"`
+	DivisionYellow     bool
 
-	Division       string `json:"division"`
-	DivisionYellow bool
+	// UnitID, PU and Department are set when the line resolves to a single unit
+	UnitID     int    `json:"unit_id,omitempty"`
+	PU         string `json:"pu,omitempty"`
+	Department string `json:"department,omitempty"`
 
-	Operation       string `json:"operation"`
-	OperationYellow bool
+	Operation           string `json:"operation"`
+	OperationRaw        string `json:"operation_raw,omitempty"`
+	OperationSuggestion string `json:"operation_suggestion,omitempty"`
+	OperationYellow     bool
 
-	Culture       string `json:"culture"`
-	CultureYellow bool
+	Culture           string `json:"culture"`
+	CultureRaw        string `json:"culture_raw,omitempty"`
+	CultureSuggestion string `json:"culture_suggestion,omitempty"`
+	CultureYellow     bool
 
 	PerDay       float64 `json:"per_day"`
 	PerOperation float64 `json:"per_operation"`
 	ValDay       float64 `json:"val_day"`
 	ValBeginning float64 `json:"val_beginning"`
+
+	// *Unit is the unit of the value, as reported by Apollo until the values
+	// are converted. Measures keeps the reported values that were converted,
+	// keyed by the json name of the field.
+	PerDayUnit       string             `json:"per_day_unit,omitempty"`
+	PerOperationUnit string             `json:"per_operation_unit,omitempty"`
+	ValDayUnit       string             `json:"val_day_unit,omitempty"`
+	ValBeginningUnit string             `json:"val_beginning_unit,omitempty"`
+	Measures         map[string]Measure `json:"measures,omitempty"`
+
+	Flags []Flag `json:"flags,omitempty"`
+}
+
+// ValUnit returns the unit of the gross values, ц when it is not reported.
+func (l Line) ValUnit() string {
+	switch {
+	case l.ValDayUnit != "":
+		return l.ValDayUnit
+	case l.ValBeginningUnit != "":
+		return l.ValBeginningUnit
+	}
+
+	return MassUnit
+}
+
+type FlagCode string
+
+const (
+	FlagCumulativeBelowDaily FlagCode = "cumulative_below_daily"
+	FlagAreaOutOfRange       FlagCode = "area_out_of_range"
+	FlagYieldOutOfRange      FlagCode = "yield_out_of_range"
+	FlagUnitAreaExceeded     FlagCode = "unit_area_exceeded"
+	// the cumulative value was missing and is computed from previous reports
+	FlagCumulativeFilled   FlagCode = "cumulative_filled"
+	FlagCumulativeMismatch FlagCode = "cumulative_mismatch"
+	// the date could not be parsed and the day of the message is used
+	FlagDateInvalid FlagCode = "date_invalid"
+	// the unit is unknown or does not fit the field or the operation
+	FlagUnitMismatch FlagCode = "unit_mismatch"
+)
+
+// Flag is an issue found in the line by validation.
+type Flag struct {
+	Code FlagCode `json:"code"`
+	// Field is the json name of the flagged value, e.g. "per_day"
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Color is an RGB color with components from 0 to 1.
+type Color struct {
+	Red, Green, Blue float64
+}
+
+var (
+	// YellowColor highlights the values not matched with the dictionaries
+	YellowColor = Color{Red: 1.0, Green: 1.0, Blue: 0.0}
+
+	// FlagColors tell the issues apart in the report tables
+	FlagColors = map[FlagCode]Color{
+		FlagCumulativeBelowDaily: {Red: 1.0, Green: 0.6, Blue: 0.0},
+		FlagAreaOutOfRange:       {Red: 0.8, Green: 0.6, Blue: 1.0},
+		FlagYieldOutOfRange:      {Red: 1.0, Green: 0.6, Blue: 0.6},
+		FlagUnitAreaExceeded:     {Red: 0.9, Green: 0.2, Blue: 0.2},
+		FlagCumulativeFilled:     {Red: 0.7, Green: 0.85, Blue: 1.0},
+		FlagCumulativeMismatch:   {Red: 1.0, Green: 0.4, Blue: 0.8},
+		FlagDateInvalid:          {Red: 1.0, Green: 0.8, Blue: 0.4},
+		FlagUnitMismatch:         {Red: 0.6, Green: 0.9, Blue: 0.6},
+	}
+)
+
+// FlagColor returns the highlight of the flag, yellow for unknown codes.
+func FlagColor(code FlagCode) Color {
+	color, ok := FlagColors[code]
+	if !ok {
+		return YellowColor
+	}
+
+	return color
 }
 
 type Table []Line
 
-func GetTableName(t time.Time, chatContextName string) string {
-	t = loctime.Transfer(t)
+// GetTableName names the table of the report started at t, the time is
+// formatted in the time zone of the report.
+func GetTableName(t time.Time, loc *time.Location, chatContextName string) string {
+	t = t.In(loc)
 
 	fileName := t.Format("15ч02/01/2006") + "_AgroScientists"
 	if chatContextName != "" {
@@ -39,8 +134,14 @@ func GetTableName(t time.Time, chatContextName string) string {
 	return fileName
 }
 
-func GetBasicName(name string, number int, t time.Time, chatContextName string) string {
-	t = loctime.Transfer(t)
+// GetXlsxName names the XLSX file of the report table, the same as the table
+// with the slashes of the date replaced.
+func GetXlsxName(t time.Time, loc *time.Location, chatContextName string) string {
+	return strings.ReplaceAll(GetTableName(t, loc, chatContextName), "/", ".") + ".xlsx"
+}
+
+func GetBasicName(name string, number int, t time.Time, loc *time.Location, chatContextName string) string {
+	t = t.In(loc)
 
 	fileName := fmt.Sprintf("%s_%d_%s", strings.ReplaceAll(name, " ", "-"), number, t.Format("04м15ч02/01/2006"))
 
@@ -51,10 +152,10 @@ func GetBasicName(name string, number int, t time.Time, chatContextName string)
 	return fileName
 }
 
-func GetFileName(name string, number int, timestamp time.Time, chatContextName string, postfix string) string {
-	return GetBasicName(name, number, timestamp, chatContextName) + postfix
+func GetFileName(name string, number int, timestamp time.Time, loc *time.Location, chatContextName string, postfix string) string {
+	return GetBasicName(name, number, timestamp, loc, chatContextName) + postfix
 }
 
-func GetDocxName(name string, number int, timestamp time.Time, chatContextName string) string {
-	return GetFileName(name, number, timestamp, chatContextName, ".docx")
+func GetDocxName(name string, number int, timestamp time.Time, loc *time.Location, chatContextName string) string {
+	return GetFileName(name, number, timestamp, loc, chatContextName, ".docx")
 }
@@ -42,3 +42,9 @@ func GetTelegramTimestamp(update tgbotapi.Update) time.Time {
 func GetTelegramName(update tgbotapi.Update) string {
 	return update.Message.From.UserName
 }
+
+// TelegramMention is the Telegram HTML link mentioning the user, the name
+// must be escaped.
+func TelegramMention(telegramID string, name string) string {
+	return `<a href="tg://user?id=` + strconv.FormatInt(ToTelegramID(telegramID), 10) + `">` + name + `</a>`
+}
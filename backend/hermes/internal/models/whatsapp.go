@@ -0,0 +1,12 @@
+package models
+
+import "strings"
+
+// WhatsappMention returns the tag of the account in a WhatsApp text, "@"
+// followed by its number, and the JID to mention without the device.
+func WhatsappMention(whatsappID string) (string, string) {
+	user, server, _ := strings.Cut(whatsappID, "@")
+	user, _, _ = strings.Cut(user, ":")
+
+	return "@" + user, user + "@" + server
+}
@@ -126,3 +126,22 @@ func (r *Repository) FindChat(ctx context.Context, chatID string) (bool, error)
 
 	return exists, nil
 }
+
+// IsListener reports whether the worker listens to a chat of the chat context.
+func (r *Repository) IsListener(ctx context.Context, workerID int, chatContextID int) (bool, error) {
+	query := `
+	SELECT EXISTS (
+		SELECT 1 FROM hermes_data.listener l
+		JOIN hermes_data.chat c ON c.id = l.chat_id
+		WHERE l.worker_id = $1 AND c.chat_context_id = $2
+	);
+	`
+
+	var exists bool
+	err := r.postgres.QueryRow(ctx, query, workerID, chatContextID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check listener: %w", err)
+	}
+
+	return exists, nil
+}
@@ -0,0 +1,125 @@
+package chats
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
+)
+
+// GetSettings returns the settings of the chat context, zero settings if none are saved.
+func (r *Repository) GetSettings(ctx context.Context, chatContextID int) (models.ChatContextSettings, error) {
+	query := `
+	SELECT chat_context_id, default_division, default_culture, expected_operations, prompt_hints, report_schedule, time_zone, report_delivery, review_required, remind_before_minutes, escalate_after_minutes, updated_at
+	FROM hermes_data.chat_context_settings
+	WHERE chat_context_id = $1;
+	`
+
+	settings, err := scanSettings(r.postgres.QueryRow(ctx, query, chatContextID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.ChatContextSettings{ChatContextID: chatContextID, ExpectedOperations: []string{}, ReportSchedule: []string{}, ReportDelivery: models.DeliveryLink}, nil
+	}
+	if err != nil {
+		return models.ChatContextSettings{}, fmt.Errorf("failed to get chat context settings: %w", err)
+	}
+
+	return settings, nil
+}
+
+func (r *Repository) SaveSettings(ctx context.Context, settings models.ChatContextSettings) (models.ChatContextSettings, error) {
+	if settings.ExpectedOperations == nil {
+		settings.ExpectedOperations = []string{}
+	}
+	if settings.ReportSchedule == nil {
+		settings.ReportSchedule = []string{}
+	}
+	if settings.ReportDelivery == "" {
+		settings.ReportDelivery = models.DeliveryLink
+	}
+
+	query := `
+	INSERT INTO hermes_data.chat_context_settings (chat_context_id, default_division, default_culture, expected_operations, prompt_hints, report_schedule, time_zone, report_delivery, review_required,
+		remind_before_minutes, escalate_after_minutes)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	ON CONFLICT (chat_context_id) DO UPDATE
+	SET default_division = EXCLUDED.default_division,
+	    default_culture = EXCLUDED.default_culture,
+	    expected_operations = EXCLUDED.expected_operations,
+	    prompt_hints = EXCLUDED.prompt_hints,
+	    report_schedule = EXCLUDED.report_schedule,
+	    time_zone = EXCLUDED.time_zone,
+	    report_delivery = EXCLUDED.report_delivery,
+	    review_required = EXCLUDED.review_required,
+	    remind_before_minutes = EXCLUDED.remind_before_minutes,
+	    escalate_after_minutes = EXCLUDED.escalate_after_minutes,
+	    updated_at = CURRENT_TIMESTAMP
+	RETURNING chat_context_id, default_division, default_culture, expected_operations, prompt_hints, report_schedule, time_zone, report_delivery, review_required, remind_before_minutes, escalate_after_minutes, updated_at;
+	`
+
+	saved, err := scanSettings(r.postgres.QueryRow(ctx, query,
+		settings.ChatContextID, settings.DefaultDivision, settings.DefaultCulture, settings.ExpectedOperations, settings.PromptHints,
+		settings.ReportSchedule, settings.TimeZone, settings.ReportDelivery, settings.ReviewRequired,
+		settings.RemindBefore, settings.EscalateAfter,
+	))
+	if err != nil {
+		return models.ChatContextSettings{}, fmt.Errorf("failed to save chat context settings: %w", err)
+	}
+
+	return saved, nil
+}
+
+// ListChatContexts returns all chat contexts with their settings.
+func (r *Repository) ListChatContexts(ctx context.Context) ([]models.ChatContext, error) {
+	query := `
+	SELECT cc.id, cc.name, cc.created_at,
+	       cc.id, COALESCE(s.default_division, ''), COALESCE(s.default_culture, ''),
+	       COALESCE(s.expected_operations, '{}'), COALESCE(s.prompt_hints, ''), COALESCE(s.report_schedule, '{}'),
+	       COALESCE(s.time_zone, ''), COALESCE(s.report_delivery, 'link'), COALESCE(s.review_required, false),
+	       s.remind_before_minutes, s.escalate_after_minutes, COALESCE(s.updated_at, cc.created_at)
+	FROM hermes_data.chat_context cc
+	LEFT JOIN hermes_data.chat_context_settings s ON s.chat_context_id = cc.id
+	ORDER BY cc.id;
+	`
+
+	rows, err := r.postgres.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chat contexts: %w", err)
+	}
+	defer rows.Close()
+
+	chatContexts := make([]models.ChatContext, 0)
+	for rows.Next() {
+		var chatContext models.ChatContext
+		settings := &chatContext.Settings
+		err := rows.Scan(
+			&chatContext.ID, &chatContext.Name, &chatContext.CreatedAt,
+			&settings.ChatContextID, &settings.DefaultDivision, &settings.DefaultCulture,
+			&settings.ExpectedOperations, &settings.PromptHints, &settings.ReportSchedule, &settings.TimeZone, &settings.ReportDelivery, &settings.ReviewRequired,
+			&settings.RemindBefore, &settings.EscalateAfter, &settings.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan chat context: %w", err)
+		}
+
+		chatContexts = append(chatContexts, chatContext)
+	}
+
+	return chatContexts, rows.Err()
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSettings(row rowScanner) (models.ChatContextSettings, error) {
+	var settings models.ChatContextSettings
+	err := row.Scan(
+		&settings.ChatContextID, &settings.DefaultDivision, &settings.DefaultCulture,
+		&settings.ExpectedOperations, &settings.PromptHints, &settings.ReportSchedule, &settings.TimeZone, &settings.ReportDelivery, &settings.ReviewRequired,
+		&settings.RemindBefore, &settings.EscalateAfter, &settings.UpdatedAt,
+	)
+
+	return settings, err
+}
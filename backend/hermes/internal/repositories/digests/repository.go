@@ -0,0 +1,163 @@
+package digests
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/clients/postgres"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
+)
+
+func NewRepository(postgres *postgres.Client) *Repository {
+	return &Repository{
+		postgres: postgres,
+	}
+}
+
+type Repository struct {
+	postgres *postgres.Client
+}
+
+func (r *Repository) ListRecipients(ctx context.Context) ([]models.DigestRecipient, error) {
+	query := `
+	SELECT id, chat_id, COALESCE(email, ''), created_at FROM hermes_data.digest_recipient ORDER BY id;
+	`
+
+	rows, err := r.postgres.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list digest recipients: %w", err)
+	}
+	defer rows.Close()
+
+	recipients := make([]models.DigestRecipient, 0)
+	for rows.Next() {
+		var recipient models.DigestRecipient
+		err := rows.Scan(&recipient.ID, &recipient.ChatID, &recipient.Email, &recipient.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan digest recipient: %w", err)
+		}
+
+		recipients = append(recipients, recipient)
+	}
+
+	return recipients, rows.Err()
+}
+
+// AddRecipient adds the chat or the email, an existing recipient is returned as is.
+func (r *Repository) AddRecipient(ctx context.Context, recipient models.DigestRecipient) (models.DigestRecipient, error) {
+	var email *string
+	if recipient.Email != "" {
+		email = &recipient.Email
+	}
+
+	query := `
+	INSERT INTO hermes_data.digest_recipient (chat_id, email)
+	VALUES ($1, $2)
+	ON CONFLICT DO NOTHING
+	RETURNING id, created_at;
+	`
+
+	err := r.postgres.QueryRow(ctx, query, recipient.ChatID, email).Scan(&recipient.ID, &recipient.CreatedAt)
+	if err == nil {
+		return recipient, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return models.DigestRecipient{}, fmt.Errorf("failed to add digest recipient: %w", err)
+	}
+
+	query = `
+	SELECT id, created_at FROM hermes_data.digest_recipient
+	WHERE chat_id = $1 OR lower(email) = lower($2);
+	`
+
+	err = r.postgres.QueryRow(ctx, query, recipient.ChatID, email).Scan(&recipient.ID, &recipient.CreatedAt)
+	if err != nil {
+		return models.DigestRecipient{}, fmt.Errorf("failed to get digest recipient: %w", err)
+	}
+
+	return recipient, nil
+}
+
+func (r *Repository) DeleteRecipient(ctx context.Context, recipientID int) error {
+	query := `
+	DELETE FROM hermes_data.digest_recipient WHERE id = $1;
+	`
+
+	tag, err := r.postgres.Exec(ctx, query, recipientID)
+	if err != nil {
+		return fmt.Errorf("failed to delete digest recipient: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("failed to delete digest recipient: %w", sql.ErrNoRows)
+	}
+
+	return nil
+}
+
+// ClaimDigest records that the instance delivers the digest of the period,
+// it is false when the digest is sent or claimed after staleBefore by
+// another instance. An older claim is left by a failed delivery and is taken over.
+func (r *Repository) ClaimDigest(ctx context.Context, from time.Time, to time.Time, staleBefore time.Time) (bool, error) {
+	query := `
+	INSERT INTO hermes_data.digest (period_from, period_to, claimed_at)
+	VALUES ($1, $2, $3)
+	ON CONFLICT (period_to) DO UPDATE
+	SET period_from = EXCLUDED.period_from, claimed_at = EXCLUDED.claimed_at
+	WHERE digest.sent_at IS NULL AND digest.claimed_at < $4;
+	`
+
+	tag, err := r.postgres.Exec(ctx, query, from.UTC(), to.UTC(), time.Now().UTC(), staleBefore.UTC())
+	if err != nil {
+		return false, fmt.Errorf("failed to claim digest: %w", err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// MarkDigestSent records that the digest of the period is delivered.
+func (r *Repository) MarkDigestSent(ctx context.Context, to time.Time) error {
+	query := `
+	UPDATE hermes_data.digest SET sent_at = $2 WHERE period_to = $1;
+	`
+
+	_, err := r.postgres.Exec(ctx, query, to.UTC(), time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to mark digest sent: %w", err)
+	}
+
+	return nil
+}
+
+// ReleaseDigest drops the claim of the digest that was not delivered, so
+// that it is tried again.
+func (r *Repository) ReleaseDigest(ctx context.Context, to time.Time) error {
+	query := `
+	DELETE FROM hermes_data.digest WHERE period_to = $1 AND sent_at IS NULL;
+	`
+
+	_, err := r.postgres.Exec(ctx, query, to.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to release digest: %w", err)
+	}
+
+	return nil
+}
+
+// IsDigestSent reports whether the digest of the period is delivered.
+func (r *Repository) IsDigestSent(ctx context.Context, to time.Time) (bool, error) {
+	query := `
+	SELECT EXISTS (SELECT 1 FROM hermes_data.digest WHERE period_to = $1 AND sent_at IS NOT NULL);
+	`
+
+	var sent bool
+	err := r.postgres.QueryRow(ctx, query, to.UTC()).Scan(&sent)
+	if err != nil {
+		return false, fmt.Errorf("failed to check digest: %w", err)
+	}
+
+	return sent, nil
+}
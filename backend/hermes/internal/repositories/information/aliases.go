@@ -0,0 +1,236 @@
+package information
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
+)
+
+var ErrUnknownValue = errors.New("value is not in the dictionary")
+
+type aliasTable struct {
+	table string
+	// value selects the dictionary entry name from alias row "a" joined with entry row "d"
+	value string
+	join  string
+
+	insert string
+}
+
+var aliasTables = map[models.Dictionary]aliasTable{
+	models.DictionaryCultures: {
+		table: "hermes_data.culture_aliases",
+		value: "d.name",
+		join:  "JOIN hermes_data.cultures d ON d.id = a.culture_id",
+		insert: `
+		INSERT INTO hermes_data.culture_aliases (alias, culture_id, chat_context_id, global, source, approved_at)
+		SELECT $1, id, $3, $4, $5, $6 FROM hermes_data.cultures WHERE name = $2 AND deleted_at IS NULL LIMIT 1
+		ON CONFLICT (LOWER(alias), COALESCE(chat_context_id, 0))
+		DO UPDATE SET culture_id = EXCLUDED.culture_id, source = EXCLUDED.source,
+			global = EXCLUDED.global OR (culture_aliases.global AND culture_aliases.culture_id = EXCLUDED.culture_id),
+			approved_at = CASE
+				WHEN EXCLUDED.global THEN EXCLUDED.approved_at
+				WHEN culture_aliases.culture_id = EXCLUDED.culture_id THEN culture_aliases.approved_at
+			END
+		RETURNING id;
+		`,
+	},
+	models.DictionaryOperations: {
+		table: "hermes_data.operation_aliases",
+		value: "d.name",
+		join:  "JOIN hermes_data.operations d ON d.id = a.operation_id",
+		insert: `
+		INSERT INTO hermes_data.operation_aliases (alias, operation_id, chat_context_id, global, source, approved_at)
+		SELECT $1, id, $3, $4, $5, $6 FROM hermes_data.operations WHERE name = $2 AND deleted_at IS NULL LIMIT 1
+		ON CONFLICT (LOWER(alias), COALESCE(chat_context_id, 0))
+		DO UPDATE SET operation_id = EXCLUDED.operation_id, source = EXCLUDED.source,
+			global = EXCLUDED.global OR (operation_aliases.global AND operation_aliases.operation_id = EXCLUDED.operation_id),
+			approved_at = CASE
+				WHEN EXCLUDED.global THEN EXCLUDED.approved_at
+				WHEN operation_aliases.operation_id = EXCLUDED.operation_id THEN operation_aliases.approved_at
+			END
+		RETURNING id;
+		`,
+	},
+	models.DictionaryUnits: {
+		table: "hermes_data.unit_aliases",
+		value: "a.division",
+		join:  "",
+		insert: `
+		INSERT INTO hermes_data.unit_aliases (alias, division, chat_context_id, global, source, approved_at)
+		SELECT $1, $2, $3, $4, $5, $6 WHERE EXISTS (SELECT 1 FROM hermes_data.units WHERE division = $2 AND deleted_at IS NULL)
+		ON CONFLICT (LOWER(alias), COALESCE(chat_context_id, 0))
+		DO UPDATE SET division = EXCLUDED.division, source = EXCLUDED.source,
+			global = EXCLUDED.global OR (unit_aliases.global AND unit_aliases.division = EXCLUDED.division),
+			approved_at = CASE
+				WHEN EXCLUDED.global THEN EXCLUDED.approved_at
+				WHEN unit_aliases.division = EXCLUDED.division THEN unit_aliases.approved_at
+			END
+		RETURNING id;
+		`,
+	},
+}
+
+func getAliasTable(dictionary models.Dictionary) (aliasTable, error) {
+	table, ok := aliasTables[dictionary]
+	if !ok {
+		return aliasTable{}, fmt.Errorf("unknown dictionary: %s", dictionary)
+	}
+
+	return table, nil
+}
+
+// GetAliases returns global aliases and aliases of the chat context.
+func (r *Repository) GetAliases(ctx context.Context, dictionary models.Dictionary, chatContextID int) ([]models.Alias, error) {
+	if cached, ok := r.cache.getAliases(dictionary); ok {
+		aliases := make([]models.Alias, 0)
+		for _, alias := range cached {
+			if alias.Global || (alias.ChatContextID != nil && *alias.ChatContextID == chatContextID) {
+				aliases = append(aliases, alias)
+			}
+		}
+
+		return aliases, nil
+	}
+
+	table, err := getAliasTable(dictionary)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+	SELECT a.id, a.alias, %s, a.chat_context_id, a.global, a.source, a.created_at, a.approved_at
+	FROM %s a %s
+	WHERE a.global OR a.chat_context_id = $1;
+	`, table.value, table.table, table.join)
+
+	return r.queryAliases(ctx, dictionary, query, chatContextID)
+}
+
+// ListAliases returns all aliases of the dictionary, only not yet global if pendingOnly.
+func (r *Repository) ListAliases(ctx context.Context, dictionary models.Dictionary, pendingOnly bool) ([]models.Alias, error) {
+	table, err := getAliasTable(dictionary)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+	SELECT a.id, a.alias, %s, a.chat_context_id, a.global, a.source, a.created_at, a.approved_at
+	FROM %s a %s
+	WHERE NOT ($1 AND a.global)
+	ORDER BY a.created_at;
+	`, table.value, table.table, table.join)
+
+	return r.queryAliases(ctx, dictionary, query, pendingOnly)
+}
+
+func (r *Repository) queryAliases(ctx context.Context, dictionary models.Dictionary, query string, args ...any) ([]models.Alias, error) {
+	rows, err := r.postgres.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get aliases: %w", err)
+	}
+	defer rows.Close()
+
+	aliases := make([]models.Alias, 0)
+	for rows.Next() {
+		alias := models.Alias{Dictionary: dictionary}
+		err := rows.Scan(
+			&alias.ID, &alias.Alias, &alias.Value, &alias.ChatContextID,
+			&alias.Global, &alias.Source, &alias.CreatedAt, &alias.ApprovedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan alias: %w", err)
+		}
+
+		aliases = append(aliases, alias)
+	}
+
+	return aliases, rows.Err()
+}
+
+// AddAlias inserts the alias or replaces the value of the existing one
+// with the same spelling in the same chat context. An approved alias
+// given another value by a chat goes back to pending approval.
+func (r *Repository) AddAlias(ctx context.Context, alias models.Alias) (int, error) {
+	table, err := getAliasTable(alias.Dictionary)
+	if err != nil {
+		return 0, err
+	}
+
+	var approvedAt *time.Time
+	if alias.Global {
+		now := time.Now()
+		approvedAt = &now
+	}
+
+	var aliasID int
+	err = r.postgres.QueryRow(
+		ctx, table.insert,
+		alias.Alias, alias.Value, alias.ChatContextID, alias.Global, alias.Source, approvedAt,
+	).Scan(&aliasID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownValue, alias.Value)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to add alias: %w", err)
+	}
+
+	r.refresh(ctx, alias.Dictionary)
+
+	return aliasID, nil
+}
+
+// ApproveAlias makes the alias global.
+func (r *Repository) ApproveAlias(ctx context.Context, dictionary models.Dictionary, aliasID int) error {
+	table, err := getAliasTable(dictionary)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`
+	UPDATE %s
+	SET global = TRUE, approved_at = $2
+	WHERE id = $1;
+	`, table.table)
+
+	tag, err := r.postgres.Exec(ctx, query, aliasID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to approve alias: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("failed to approve alias: %w", sql.ErrNoRows)
+	}
+
+	r.refresh(ctx, dictionary)
+
+	return nil
+}
+
+func (r *Repository) DeleteAlias(ctx context.Context, dictionary models.Dictionary, aliasID int) error {
+	table, err := getAliasTable(dictionary)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`
+	DELETE FROM %s WHERE id = $1;
+	`, table.table)
+
+	tag, err := r.postgres.Exec(ctx, query, aliasID)
+	if err != nil {
+		return fmt.Errorf("failed to delete alias: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("failed to delete alias: %w", sql.ErrNoRows)
+	}
+
+	r.refresh(ctx, dictionary)
+
+	return nil
+}
@@ -0,0 +1,258 @@
+package information
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
+)
+
+// dictionaryChannel is notified by triggers with the name of the changed table.
+const dictionaryChannel = "hermes_dictionaries"
+
+const listenRetryInterval = 5 * time.Second
+
+var notifiedTables = map[string]models.Dictionary{
+	"cultures":          models.DictionaryCultures,
+	"culture_aliases":   models.DictionaryCultures,
+	"operations":        models.DictionaryOperations,
+	"operation_aliases": models.DictionaryOperations,
+	"units":             models.DictionaryUnits,
+	"unit_aliases":      models.DictionaryUnits,
+}
+
+// dictionaryCache keeps every version of the entries and all aliases, so
+// lookups by date and chat context are served without queries.
+type dictionaryCache struct {
+	mu      sync.RWMutex
+	entries map[models.Dictionary][]models.DictionaryEntry
+	aliases map[models.Dictionary][]models.Alias
+}
+
+func newDictionaryCache() *dictionaryCache {
+	return &dictionaryCache{
+		entries: make(map[models.Dictionary][]models.DictionaryEntry),
+		aliases: make(map[models.Dictionary][]models.Alias),
+	}
+}
+
+func (c *dictionaryCache) getEntries(dictionary models.Dictionary) ([]models.DictionaryEntry, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entries, ok := c.entries[dictionary]
+	return entries, ok
+}
+
+func (c *dictionaryCache) getAliases(dictionary models.Dictionary) ([]models.Alias, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	aliases, ok := c.aliases[dictionary]
+	return aliases, ok
+}
+
+func (c *dictionaryCache) set(dictionary models.Dictionary, entries []models.DictionaryEntry, aliases []models.Alias) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[dictionary] = entries
+	c.aliases[dictionary] = aliases
+}
+
+// Watch loads the dictionaries into memory and keeps them fresh: a dictionary
+// is reloaded when its tables notify about changes and all of them are
+// reloaded every reloadInterval in case a notification was missed.
+// Until Watch is called the repository reads dictionaries from the database,
+// dictionaries that failed to load are read from it until the next reload.
+func (r *Repository) Watch(ctx context.Context, reloadInterval time.Duration) error {
+	err := r.Reload(ctx)
+
+	go r.listen(ctx)
+
+	if reloadInterval > 0 {
+		go func() {
+			ticker := time.NewTicker(reloadInterval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					err := r.Reload(ctx)
+					if err != nil {
+						log.Printf("failed to reload dictionaries: %v", err)
+					}
+				}
+			}
+		}()
+	}
+
+	return err
+}
+
+func (r *Repository) Reload(ctx context.Context) error {
+	for _, dictionary := range models.Dictionaries {
+		err := r.reload(ctx, dictionary)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *Repository) reload(ctx context.Context, dictionary models.Dictionary) error {
+	entries, err := r.ListEntries(ctx, dictionary, nil, true)
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", dictionary, err)
+	}
+
+	aliases, err := r.ListAliases(ctx, dictionary, false)
+	if err != nil {
+		return fmt.Errorf("failed to load %s aliases: %w", dictionary, err)
+	}
+
+	r.cache.set(dictionary, entries, aliases)
+
+	return nil
+}
+
+// refresh reloads the dictionary after this instance changed it, without
+// waiting for the notification. Only loaded dictionaries are refreshed.
+func (r *Repository) refresh(ctx context.Context, dictionary models.Dictionary) {
+	if _, ok := r.cache.getEntries(dictionary); !ok {
+		return
+	}
+
+	err := r.reload(ctx, dictionary)
+	if err != nil {
+		log.Printf("failed to refresh %s: %v", dictionary, err)
+	}
+}
+
+func (r *Repository) listen(ctx context.Context) {
+	for {
+		err := r.waitForNotifications(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		log.Printf("failed to listen for dictionary changes: %v", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(listenRetryInterval):
+		}
+
+		// changes made while the connection was lost were not notified
+		err = r.Reload(ctx)
+		if err != nil {
+			log.Printf("failed to reload dictionaries: %v", err)
+		}
+	}
+}
+
+func (r *Repository) waitForNotifications(ctx context.Context) error {
+	conn, err := r.postgres.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	// the connection stays subscribed, so it must not return to the pool
+	listener := conn.Hijack()
+	defer listener.Close(context.Background())
+
+	_, err = listener.Exec(ctx, "LISTEN "+dictionaryChannel)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+
+	for {
+		notification, err := listener.WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to wait for notification: %w", err)
+		}
+
+		dictionary, ok := notifiedTables[notification.Payload]
+		if !ok {
+			continue
+		}
+
+		err = r.reload(ctx, dictionary)
+		if err != nil {
+			log.Printf("failed to reload %s: %v", dictionary, err)
+		}
+	}
+}
+
+// activeNames returns distinct names of the entries in force at the moment.
+func activeNames(entries []models.DictionaryEntry, at time.Time) []string {
+	seen := make(map[string]struct{}, len(entries))
+	names := make([]string, 0, len(entries))
+
+	for _, entry := range entries {
+		if !entry.IsActiveAt(at) {
+			continue
+		}
+
+		if _, ok := seen[entry.Name]; ok {
+			continue
+		}
+
+		seen[entry.Name] = struct{}{}
+		names = append(names, entry.Name)
+	}
+
+	return names
+}
+
+// LineCheck tells which values of the table line are dictionary entries.
+type LineCheck struct {
+	Culture   bool
+	Operation bool
+	Division  bool
+}
+
+// ValidateTable checks cultures, operations and divisions of all lines
+// against the dictionaries in force at the moment.
+func (r *Repository) ValidateTable(ctx context.Context, at time.Time, table models.Table) ([]LineCheck, error) {
+	cultures, err := r.GetCultures(ctx, at)
+	if err != nil {
+		return nil, err
+	}
+
+	operations, err := r.GetOperations(ctx, at)
+	if err != nil {
+		return nil, err
+	}
+
+	divisions, err := r.GetDivisions(ctx, at)
+	if err != nil {
+		return nil, err
+	}
+
+	cultureSet, operationSet, divisionSet := toSet(cultures), toSet(operations), toSet(divisions)
+
+	checks := make([]LineCheck, len(table))
+	for i, line := range table {
+		_, checks[i].Culture = cultureSet[line.Culture]
+		_, checks[i].Operation = operationSet[line.Operation]
+		_, checks[i].Division = divisionSet[line.Division]
+	}
+
+	return checks, nil
+}
+
+func toSet(values []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(values))
+	for _, value := range values {
+		set[value] = struct{}{}
+	}
+
+	return set
+}
@@ -0,0 +1,398 @@
+package information
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
+)
+
+type dictionaryTable struct {
+	table string
+	// columns selects id, name, note, pu, department, area, expected_unit, effective_from, deleted_at, created_at, updated_at
+	columns string
+
+	insert     string
+	insertArgs func(entry models.DictionaryEntry) []any
+
+	update     string
+	updateArgs func(entryID int, entry models.DictionaryEntry) []any
+
+	moveAliases     string
+	moveAliasesArgs func(from, to models.DictionaryEntry) []any
+}
+
+var dictionaryTables = map[models.Dictionary]dictionaryTable{
+	models.DictionaryCultures: {
+		table:   "hermes_data.cultures",
+		columns: "id, name, '', '', '', NULL::DOUBLE PRECISION, '', effective_from, deleted_at, created_at, updated_at",
+		insert: `
+		INSERT INTO hermes_data.cultures (name, effective_from)
+		VALUES ($1, $2)
+		RETURNING id, name, '', '', '', NULL::DOUBLE PRECISION, '', effective_from, deleted_at, created_at, updated_at;
+		`,
+		insertArgs: func(entry models.DictionaryEntry) []any {
+			return []any{entry.Name, entry.EffectiveFrom}
+		},
+		update: `
+		UPDATE hermes_data.cultures
+		SET name = $2, effective_from = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1;
+		`,
+		updateArgs: func(entryID int, entry models.DictionaryEntry) []any {
+			return []any{entryID, entry.Name, entry.EffectiveFrom}
+		},
+		moveAliases: `
+		UPDATE hermes_data.culture_aliases SET culture_id = $2 WHERE culture_id = $1;
+		`,
+		moveAliasesArgs: func(from, to models.DictionaryEntry) []any {
+			return []any{from.ID, to.ID}
+		},
+	},
+	models.DictionaryOperations: {
+		table:   "hermes_data.operations",
+		columns: "id, name, note, '', '', NULL::DOUBLE PRECISION, COALESCE(expected_unit, ''), effective_from, deleted_at, created_at, updated_at",
+		insert: `
+		INSERT INTO hermes_data.operations (name, note, expected_unit, effective_from)
+		VALUES ($1, $2, NULLIF($3, ''), $4)
+		RETURNING id, name, note, '', '', NULL::DOUBLE PRECISION, COALESCE(expected_unit, ''), effective_from, deleted_at, created_at, updated_at;
+		`,
+		insertArgs: func(entry models.DictionaryEntry) []any {
+			return []any{entry.Name, entry.Note, entry.ExpectedUnit, entry.EffectiveFrom}
+		},
+		update: `
+		UPDATE hermes_data.operations
+		SET name = $2, note = $3, expected_unit = NULLIF($4, ''), effective_from = $5, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1;
+		`,
+		updateArgs: func(entryID int, entry models.DictionaryEntry) []any {
+			return []any{entryID, entry.Name, entry.Note, entry.ExpectedUnit, entry.EffectiveFrom}
+		},
+		moveAliases: `
+		UPDATE hermes_data.operation_aliases SET operation_id = $2 WHERE operation_id = $1;
+		`,
+		moveAliasesArgs: func(from, to models.DictionaryEntry) []any {
+			return []any{from.ID, to.ID}
+		},
+	},
+	models.DictionaryUnits: {
+		table:   "hermes_data.units",
+		columns: "id, division, '', pu, department, area_ha, '', effective_from, deleted_at, created_at, updated_at",
+		insert: `
+		INSERT INTO hermes_data.units (division, pu, department, area_ha, effective_from)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, division, '', pu, department, area_ha, '', effective_from, deleted_at, created_at, updated_at;
+		`,
+		insertArgs: func(entry models.DictionaryEntry) []any {
+			return []any{entry.Name, entry.PU, entry.Department, entry.Area, entry.EffectiveFrom}
+		},
+		update: `
+		UPDATE hermes_data.units
+		SET division = $2, pu = $3, department = $4, area_ha = $5, effective_from = $6, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1;
+		`,
+		updateArgs: func(entryID int, entry models.DictionaryEntry) []any {
+			return []any{entryID, entry.Name, entry.PU, entry.Department, entry.Area, entry.EffectiveFrom}
+		},
+		// unit aliases point to the division name
+		moveAliases: `
+		UPDATE hermes_data.unit_aliases SET division = $2 WHERE division = $1;
+		`,
+		moveAliasesArgs: func(from, to models.DictionaryEntry) []any {
+			return []any{from.Name, to.Name}
+		},
+	},
+}
+
+func getDictionaryTable(dictionary models.Dictionary) (dictionaryTable, error) {
+	table, ok := dictionaryTables[dictionary]
+	if !ok {
+		return dictionaryTable{}, fmt.Errorf("unknown dictionary: %s", dictionary)
+	}
+
+	return table, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanEntry(row rowScanner, dictionary models.Dictionary) (models.DictionaryEntry, error) {
+	entry := models.DictionaryEntry{Dictionary: dictionary}
+	err := row.Scan(
+		&entry.ID, &entry.Name, &entry.Note, &entry.PU, &entry.Department, &entry.Area, &entry.ExpectedUnit,
+		&entry.EffectiveFrom, &entry.DeletedAt, &entry.CreatedAt, &entry.UpdatedAt,
+	)
+
+	return entry, err
+}
+
+// ListEntries returns the entries in force at the date, or all current entries
+// when at is nil. Deleted and closed versions are included if withDeleted.
+func (r *Repository) ListEntries(ctx context.Context, dictionary models.Dictionary, at *time.Time, withDeleted bool) ([]models.DictionaryEntry, error) {
+	table, err := getDictionaryTable(dictionary)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+	SELECT %s
+	FROM %s
+	WHERE $2
+		OR ($1::TIMESTAMP IS NULL AND deleted_at IS NULL)
+		OR (effective_from <= $1 AND (deleted_at IS NULL OR deleted_at > $1))
+	ORDER BY id;
+	`, table.columns, table.table)
+
+	rows, err := r.postgres.Query(ctx, query, at, withDeleted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]models.DictionaryEntry, 0)
+	for rows.Next() {
+		entry, err := scanEntry(rows, dictionary)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan entry: %w", err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+func (r *Repository) GetEntry(ctx context.Context, dictionary models.Dictionary, entryID int) (models.DictionaryEntry, error) {
+	table, err := getDictionaryTable(dictionary)
+	if err != nil {
+		return models.DictionaryEntry{}, err
+	}
+
+	query := fmt.Sprintf(`
+	SELECT %s FROM %s WHERE id = $1;
+	`, table.columns, table.table)
+
+	entry, err := scanEntry(r.postgres.QueryRow(ctx, query, entryID), dictionary)
+	if err != nil {
+		return models.DictionaryEntry{}, fmt.Errorf("failed to get entry: %w", err)
+	}
+
+	return entry, nil
+}
+
+func (r *Repository) CreateEntry(ctx context.Context, entry models.DictionaryEntry, changedBy string) (models.DictionaryEntry, error) {
+	table, err := getDictionaryTable(entry.Dictionary)
+	if err != nil {
+		return models.DictionaryEntry{}, err
+	}
+
+	tx, err := r.postgres.Begin(ctx)
+	if err != nil {
+		return models.DictionaryEntry{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	created, err := insertEntry(ctx, tx, table, entry)
+	if err != nil {
+		return models.DictionaryEntry{}, err
+	}
+
+	err = addHistory(ctx, tx, models.DictionaryActionCreate, created, changedBy)
+	if err != nil {
+		return models.DictionaryEntry{}, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return models.DictionaryEntry{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	r.refresh(ctx, entry.Dictionary)
+
+	return created, nil
+}
+
+// UpdateEntry changes the current version of the entry. A change effective
+// after the version start closes it and creates a new version, so reports
+// dated before keep the old one; otherwise the version is corrected in place.
+func (r *Repository) UpdateEntry(ctx context.Context, entryID int, entry models.DictionaryEntry, changedBy string) (models.DictionaryEntry, error) {
+	table, err := getDictionaryTable(entry.Dictionary)
+	if err != nil {
+		return models.DictionaryEntry{}, err
+	}
+
+	tx, err := r.postgres.Begin(ctx)
+	if err != nil {
+		return models.DictionaryEntry{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := fmt.Sprintf(`
+	SELECT %s FROM %s WHERE id = $1 AND deleted_at IS NULL FOR UPDATE;
+	`, table.columns, table.table)
+
+	current, err := scanEntry(tx.QueryRow(ctx, query, entryID), entry.Dictionary)
+	if err != nil {
+		return models.DictionaryEntry{}, fmt.Errorf("failed to get entry: %w", err)
+	}
+
+	var updated models.DictionaryEntry
+	if entry.EffectiveFrom.After(current.EffectiveFrom) {
+		query = fmt.Sprintf(`
+		UPDATE %s SET deleted_at = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $1;
+		`, table.table)
+
+		_, err = tx.Exec(ctx, query, entryID, entry.EffectiveFrom)
+		if err != nil {
+			return models.DictionaryEntry{}, fmt.Errorf("failed to close entry: %w", err)
+		}
+
+		updated, err = insertEntry(ctx, tx, table, entry)
+		if err != nil {
+			return models.DictionaryEntry{}, err
+		}
+	} else {
+		_, err = tx.Exec(ctx, table.update, table.updateArgs(entryID, entry)...)
+		if err != nil {
+			return models.DictionaryEntry{}, fmt.Errorf("failed to update entry: %w", err)
+		}
+
+		query = fmt.Sprintf(`
+		SELECT %s FROM %s WHERE id = $1;
+		`, table.columns, table.table)
+
+		updated, err = scanEntry(tx.QueryRow(ctx, query, entryID), entry.Dictionary)
+		if err != nil {
+			return models.DictionaryEntry{}, fmt.Errorf("failed to get entry: %w", err)
+		}
+	}
+
+	// learned aliases follow the entry to its new version
+	_, err = tx.Exec(ctx, table.moveAliases, table.moveAliasesArgs(current, updated)...)
+	if err != nil {
+		return models.DictionaryEntry{}, fmt.Errorf("failed to move aliases: %w", err)
+	}
+
+	err = addHistory(ctx, tx, models.DictionaryActionUpdate, updated, changedBy)
+	if err != nil {
+		return models.DictionaryEntry{}, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return models.DictionaryEntry{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	r.refresh(ctx, entry.Dictionary)
+
+	return updated, nil
+}
+
+// DeleteEntry marks the current version deleted from the moment, the row is kept
+// for reports dated before.
+func (r *Repository) DeleteEntry(ctx context.Context, dictionary models.Dictionary, entryID int, at time.Time, changedBy string) error {
+	table, err := getDictionaryTable(dictionary)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.postgres.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := fmt.Sprintf(`
+	UPDATE %s SET deleted_at = $2, updated_at = CURRENT_TIMESTAMP
+	WHERE id = $1 AND deleted_at IS NULL
+	RETURNING %s;
+	`, table.table, table.columns)
+
+	deleted, err := scanEntry(tx.QueryRow(ctx, query, entryID, at), dictionary)
+	if err != nil {
+		return fmt.Errorf("failed to delete entry: %w", err)
+	}
+
+	err = addHistory(ctx, tx, models.DictionaryActionDelete, deleted, changedBy)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	r.refresh(ctx, dictionary)
+
+	return nil
+}
+
+// GetHistory returns changes of the dictionary, of the entry only if entryID is set.
+func (r *Repository) GetHistory(ctx context.Context, dictionary models.Dictionary, entryID *int) ([]models.DictionaryChange, error) {
+	query := `
+	SELECT id, entry_id, action, data, changed_by, changed_at
+	FROM hermes_data.dictionary_history
+	WHERE dictionary = $1 AND ($2::INTEGER IS NULL OR entry_id = $2)
+	ORDER BY changed_at, id;
+	`
+
+	rows, err := r.postgres.Query(ctx, query, dictionary, entryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get history: %w", err)
+	}
+	defer rows.Close()
+
+	changes := make([]models.DictionaryChange, 0)
+	for rows.Next() {
+		change := models.DictionaryChange{Dictionary: dictionary}
+
+		var data []byte
+		err := rows.Scan(&change.ID, &change.EntryID, &change.Action, &data, &change.ChangedBy, &change.ChangedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan change: %w", err)
+		}
+
+		err = json.Unmarshal(data, &change.Entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal entry: %w", err)
+		}
+
+		changes = append(changes, change)
+	}
+
+	return changes, rows.Err()
+}
+
+func insertEntry(ctx context.Context, tx pgx.Tx, table dictionaryTable, entry models.DictionaryEntry) (models.DictionaryEntry, error) {
+	created, err := scanEntry(tx.QueryRow(ctx, table.insert, table.insertArgs(entry)...), entry.Dictionary)
+	if err != nil {
+		return models.DictionaryEntry{}, fmt.Errorf("failed to insert entry: %w", err)
+	}
+
+	return created, nil
+}
+
+func addHistory(ctx context.Context, tx pgx.Tx, action models.DictionaryAction, entry models.DictionaryEntry, changedBy string) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal entry: %w", err)
+	}
+
+	query := `
+	INSERT INTO hermes_data.dictionary_history (dictionary, entry_id, action, data, changed_by)
+	VALUES ($1, $2, $3, $4, $5);
+	`
+
+	_, err = tx.Exec(ctx, query, entry.Dictionary, entry.ID, action, data, changedBy)
+	if err != nil {
+		return fmt.Errorf("failed to add history: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package information
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
+)
+
+func (r *Repository) GetValidationLimits(ctx context.Context) ([]models.ValidationLimit, error) {
+	query := `
+	SELECT operation, culture, max_per_day_ha, min_yield, max_yield
+	FROM hermes_data.validation_limits;
+	`
+
+	rows, err := r.postgres.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get validation limits: %w", err)
+	}
+	defer rows.Close()
+
+	limits := make([]models.ValidationLimit, 0)
+	for rows.Next() {
+		var limit models.ValidationLimit
+		err := rows.Scan(&limit.Operation, &limit.Culture, &limit.MaxPerDay, &limit.MinYield, &limit.MaxYield)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan validation limit: %w", err)
+		}
+
+		limits = append(limits, limit)
+	}
+
+	return limits, rows.Err()
+}
@@ -0,0 +1,57 @@
+package information
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
+)
+
+func (r *Repository) GetMeasureUnits(ctx context.Context) ([]models.MeasureUnit, error) {
+	query := `
+	SELECT code, dimension, factor, aliases
+	FROM hermes_data.measure_units;
+	`
+
+	rows, err := r.postgres.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get measure units: %w", err)
+	}
+	defer rows.Close()
+
+	units := make([]models.MeasureUnit, 0)
+	for rows.Next() {
+		var unit models.MeasureUnit
+		err := rows.Scan(&unit.Code, &unit.Dimension, &unit.Factor, &unit.Aliases)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan measure unit: %w", err)
+		}
+
+		units = append(units, unit)
+	}
+
+	return units, rows.Err()
+}
+
+// GetExpectedUnits returns measure units of the operations in force at the
+// moment, operations without an expected unit are omitted.
+func (r *Repository) GetExpectedUnits(ctx context.Context, at time.Time) (map[string]string, error) {
+	entries, ok := r.cache.getEntries(models.DictionaryOperations)
+	if !ok {
+		var err error
+		entries, err = r.ListEntries(ctx, models.DictionaryOperations, &at, false)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	units := make(map[string]string)
+	for _, entry := range entries {
+		if entry.IsActiveAt(at) && entry.ExpectedUnit != "" {
+			units[entry.Name] = entry.ExpectedUnit
+		}
+	}
+
+	return units, nil
+}
@@ -3,58 +3,96 @@ package information
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/clients/postgres"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
 )
 
 func NewRepository(postgres *postgres.Client) *Repository {
 	return &Repository{
 		postgres: postgres,
+		cache:    newDictionaryCache(),
 	}
 }
 
 type Repository struct {
 	postgres *postgres.Client
+	cache    *dictionaryCache
 }
 
-func (r *Repository) CheckCulture(ctx context.Context, culture string) (bool, error) {
+// GetCultures returns the entries in force at the moment.
+func (r *Repository) GetCultures(ctx context.Context, at time.Time) ([]string, error) {
 	query := `
-	SELECT EXISTS (SELECT 1 FROM hermes_data.cultures WHERE name = $1);
+	SELECT DISTINCT name FROM hermes_data.cultures
+	WHERE effective_from <= $1 AND (deleted_at IS NULL OR deleted_at > $1);
 	`
 
-	var exists bool
-	err := r.postgres.QueryRow(ctx, query, culture).Scan(&exists)
-	if err != nil {
-		return false, fmt.Errorf("failed to check culture: %w", err)
-	}
+	return r.getActiveNames(ctx, models.DictionaryCultures, query, at)
+}
+
+// GetOperations returns the entries in force at the moment.
+func (r *Repository) GetOperations(ctx context.Context, at time.Time) ([]string, error) {
+	query := `
+	SELECT DISTINCT name FROM hermes_data.operations
+	WHERE effective_from <= $1 AND (deleted_at IS NULL OR deleted_at > $1);
+	`
 
-	return exists, nil
+	return r.getActiveNames(ctx, models.DictionaryOperations, query, at)
 }
 
-func (r *Repository) CheckOperation(ctx context.Context, operation string) (bool, error) {
+// GetDivisions returns the entries in force at the moment.
+func (r *Repository) GetDivisions(ctx context.Context, at time.Time) ([]string, error) {
 	query := `
-	SELECT EXISTS (SELECT 1 FROM hermes_data.operations WHERE name = $1);
+	SELECT DISTINCT division FROM hermes_data.units
+	WHERE effective_from <= $1 AND (deleted_at IS NULL OR deleted_at > $1);
 	`
 
-	var exists bool
-	err := r.postgres.QueryRow(ctx, query, operation).Scan(&exists)
-	if err != nil {
-		return false, fmt.Errorf("failed to check operation: %w", err)
+	return r.getActiveNames(ctx, models.DictionaryUnits, query, at)
+}
+
+// GetUnits returns the units in force at the moment.
+func (r *Repository) GetUnits(ctx context.Context, at time.Time) ([]models.DictionaryEntry, error) {
+	entries, ok := r.cache.getEntries(models.DictionaryUnits)
+	if !ok {
+		return r.ListEntries(ctx, models.DictionaryUnits, &at, false)
 	}
 
-	return exists, nil
+	units := make([]models.DictionaryEntry, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsActiveAt(at) {
+			units = append(units, entry)
+		}
+	}
+
+	return units, nil
 }
 
-func (r *Repository) CheckDivision(ctx context.Context, division string) (bool, error) {
-	query := `
-	SELECT EXISTS (SELECT 1 FROM hermes_data.units WHERE division = $1);
-	`
+func (r *Repository) getActiveNames(ctx context.Context, dictionary models.Dictionary, query string, at time.Time) ([]string, error) {
+	if entries, ok := r.cache.getEntries(dictionary); ok {
+		return activeNames(entries, at), nil
+	}
 
-	var exists bool
-	err := r.postgres.QueryRow(ctx, query, division).Scan(&exists)
+	return r.getNames(ctx, query, at)
+}
+
+func (r *Repository) getNames(ctx context.Context, query string, args ...any) ([]string, error) {
+	rows, err := r.postgres.Query(ctx, query, args...)
 	if err != nil {
-		return false, fmt.Errorf("failed to check division: %w", err)
+		return nil, fmt.Errorf("failed to get names: %w", err)
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		err := rows.Scan(&name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan name: %w", err)
+		}
+
+		names = append(names, name)
 	}
 
-	return exists, nil
+	return names, rows.Err()
 }
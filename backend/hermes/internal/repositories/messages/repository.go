@@ -49,6 +49,36 @@ func (r *Repository) UpdateMessage(ctx context.Context, messageID int, text stri
 	return nil
 }
 
+func (r *Repository) SetReport(ctx context.Context, messageID int, reportID int) error {
+	query := `
+	UPDATE hermes_data.messages
+	SET report_id = $2
+	WHERE id = $1;
+	`
+
+	_, err := r.postgres.Exec(ctx, query, messageID, reportID)
+	if err != nil {
+		return fmt.Errorf("failed to set report: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) SetTranscription(ctx context.Context, messageID int, duration time.Duration, codec string, cost float64) error {
+	query := `
+	UPDATE hermes_data.messages
+	SET audio_duration_seconds = $2, audio_codec = $3, transcription_cost = $4
+	WHERE id = $1;
+	`
+
+	_, err := r.postgres.Exec(ctx, query, messageID, duration.Seconds(), codec, cost)
+	if err != nil {
+		return fmt.Errorf("failed to set transcription: %w", err)
+	}
+
+	return nil
+}
+
 func (r *Repository) AddImage(ctx context.Context, messageID int, url string) error {
 	query := `
 	INSERT INTO hermes_data.images (message_id, image_url)
@@ -0,0 +1,147 @@
+package progress
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/clients/postgres"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
+)
+
+func NewRepository(postgres *postgres.Client) *Repository {
+	return &Repository{
+		postgres: postgres,
+	}
+}
+
+type Repository struct {
+	postgres *postgres.Client
+}
+
+// GetLast returns the latest entry of the key dated up to the date inclusive.
+func (r *Repository) GetLast(ctx context.Context, key models.ProgressKey, until time.Time) (models.ProgressEntry, error) {
+	query := `
+	SELECT date, message_id, per_day, per_operation_reported, per_operation_computed,
+		val_day, val_beginning_reported, val_beginning_computed
+	FROM hermes_data.progress_ledger
+	WHERE season = $1 AND division = $2 AND operation = $3 AND culture = $4 AND date <= $5
+	ORDER BY date DESC, id DESC
+	LIMIT 1;
+	`
+
+	entry := models.ProgressEntry{ProgressKey: key}
+	err := r.postgres.QueryRow(
+		ctx, query,
+		key.Season, key.Division, key.Operation, key.Culture, until,
+	).Scan(
+		&entry.Date, &entry.MessageID, &entry.PerDay, &entry.PerOperationReported, &entry.PerOperationComputed,
+		&entry.ValDay, &entry.ValBeginningReported, &entry.ValBeginningComputed,
+	)
+	if err != nil {
+		return models.ProgressEntry{}, fmt.Errorf("failed to get last progress entry: %w", err)
+	}
+
+	return entry, nil
+}
+
+// ListEntries returns the entries of the key dated from the date on, in the
+// order they are accounted.
+func (r *Repository) ListEntries(ctx context.Context, key models.ProgressKey, from time.Time) ([]models.ProgressEntry, error) {
+	query := `
+	SELECT id, date, message_id, per_day, per_operation_reported, per_operation_computed,
+		val_day, val_beginning_reported, val_beginning_computed
+	FROM hermes_data.progress_ledger
+	WHERE season = $1 AND division = $2 AND operation = $3 AND culture = $4 AND date >= $5
+	ORDER BY date, id;
+	`
+
+	rows, err := r.postgres.Query(ctx, query, key.Season, key.Division, key.Operation, key.Culture, from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list progress entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]models.ProgressEntry, 0)
+	for rows.Next() {
+		entry := models.ProgressEntry{ProgressKey: key}
+		err := rows.Scan(
+			&entry.ID, &entry.Date, &entry.MessageID, &entry.PerDay, &entry.PerOperationReported, &entry.PerOperationComputed,
+			&entry.ValDay, &entry.ValBeginningReported, &entry.ValBeginningComputed,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan progress entry: %w", err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// UpdateComputed sets the computed running totals of the entry.
+func (r *Repository) UpdateComputed(ctx context.Context, entry models.ProgressEntry) error {
+	query := `
+	UPDATE hermes_data.progress_ledger
+	SET per_operation_computed = $2, val_beginning_computed = $3
+	WHERE id = $1;
+	`
+
+	_, err := r.postgres.Exec(ctx, query, entry.ID, entry.PerOperationComputed, entry.ValBeginningComputed)
+	if err != nil {
+		return fmt.Errorf("failed to update progress entry: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteMessageEntries removes the entries of the message and returns them.
+func (r *Repository) DeleteMessageEntries(ctx context.Context, messageID int) ([]models.ProgressEntry, error) {
+	query := `
+	DELETE FROM hermes_data.progress_ledger
+	WHERE message_id = $1
+	RETURNING id, season, division, operation, culture, date;
+	`
+
+	rows, err := r.postgres.Query(ctx, query, messageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete progress entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]models.ProgressEntry, 0)
+	for rows.Next() {
+		entry := models.ProgressEntry{MessageID: messageID}
+		err := rows.Scan(&entry.ID, &entry.Season, &entry.Division, &entry.Operation, &entry.Culture, &entry.Date)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan progress entry: %w", err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+func (r *Repository) AddEntry(ctx context.Context, entry models.ProgressEntry) error {
+	query := `
+	INSERT INTO hermes_data.progress_ledger (
+		season, division, operation, culture, date, message_id,
+		per_day, per_operation_reported, per_operation_computed,
+		val_day, val_beginning_reported, val_beginning_computed
+	)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12);
+	`
+
+	_, err := r.postgres.Exec(
+		ctx, query,
+		entry.Season, entry.Division, entry.Operation, entry.Culture, entry.Date, entry.MessageID,
+		entry.PerDay, entry.PerOperationReported, entry.PerOperationComputed,
+		entry.ValDay, entry.ValBeginningReported, entry.ValBeginningComputed,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to add progress entry: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,149 @@
+package reminders
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/clients/postgres"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
+)
+
+func NewRepository(postgres *postgres.Client) *Repository {
+	return &Repository{
+		postgres: postgres,
+	}
+}
+
+type Repository struct {
+	postgres *postgres.Client
+}
+
+// ListExpectedReporters returns the expected reporters of the chat context.
+func (r *Repository) ListExpectedReporters(ctx context.Context, chatContextID int) ([]models.ExpectedReporter, error) {
+	query := `
+	SELECT er.id, er.chat_context_id, er.division, er.created_at, w.id, COALESCE(w.name, ''),
+	       ARRAY(SELECT wa.whatsapp_id FROM hermes_data.whatsapp wa WHERE wa.worker_id = w.id ORDER BY wa.id),
+	       ARRAY(SELECT tg.telegram_id FROM hermes_data.telegram tg WHERE tg.worker_id = w.id ORDER BY tg.id)
+	FROM hermes_data.expected_reporter er
+	JOIN hermes_data.worker w ON w.id = er.worker_id
+	WHERE er.chat_context_id = $1
+	ORDER BY er.id;
+	`
+
+	return r.queryExpectedReporters(ctx, query, chatContextID)
+}
+
+// GetMissingReporters returns the expected reporters of the chat context
+// whose messages in its chats after since have no report lines, of their
+// division when it is set.
+func (r *Repository) GetMissingReporters(ctx context.Context, chatContextID int, since time.Time) ([]models.ExpectedReporter, error) {
+	query := `
+	SELECT er.id, er.chat_context_id, er.division, er.created_at, w.id, COALESCE(w.name, ''),
+	       ARRAY(SELECT wa.whatsapp_id FROM hermes_data.whatsapp wa WHERE wa.worker_id = w.id ORDER BY wa.id),
+	       ARRAY(SELECT tg.telegram_id FROM hermes_data.telegram tg WHERE tg.worker_id = w.id ORDER BY tg.id)
+	FROM hermes_data.expected_reporter er
+	JOIN hermes_data.worker w ON w.id = er.worker_id
+	WHERE er.chat_context_id = $1 AND NOT EXISTS (
+		SELECT 1 FROM hermes_data.messages m
+		JOIN hermes_data.chat c ON c.id = m.chat_id
+		JOIN hermes_data.report_line_current l ON l.message_id = m.id
+		WHERE m.worker_id = er.worker_id AND c.chat_context_id = er.chat_context_id AND m.created_at > $2
+		  AND (er.division = '' OR LOWER(l.division) = LOWER(er.division))
+	)
+	ORDER BY er.id;
+	`
+
+	return r.queryExpectedReporters(ctx, query, chatContextID, since.UTC())
+}
+
+func (r *Repository) queryExpectedReporters(ctx context.Context, query string, args ...any) ([]models.ExpectedReporter, error) {
+	rows, err := r.postgres.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expected reporters: %w", err)
+	}
+	defer rows.Close()
+
+	reporters := make([]models.ExpectedReporter, 0)
+	for rows.Next() {
+		var reporter models.ExpectedReporter
+		err := rows.Scan(
+			&reporter.ID, &reporter.ChatContextID, &reporter.Division, &reporter.CreatedAt,
+			&reporter.WorkerID, &reporter.WorkerName, &reporter.WhatsappIDs, &reporter.TelegramIDs,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan expected reporter: %w", err)
+		}
+
+		reporters = append(reporters, reporter)
+	}
+
+	return reporters, rows.Err()
+}
+
+// AddExpectedReporter adds the worker to the expected reporters of the chat
+// context, an existing one is returned with its id.
+func (r *Repository) AddExpectedReporter(ctx context.Context, reporter models.ExpectedReporter) (models.ExpectedReporter, error) {
+	query := `
+	INSERT INTO hermes_data.expected_reporter (chat_context_id, worker_id, division)
+	VALUES ($1, $2, $3)
+	ON CONFLICT DO NOTHING
+	RETURNING id, created_at;
+	`
+
+	err := r.postgres.QueryRow(ctx, query, reporter.ChatContextID, reporter.WorkerID, reporter.Division).Scan(&reporter.ID, &reporter.CreatedAt)
+	if err == nil {
+		return reporter, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return models.ExpectedReporter{}, fmt.Errorf("failed to add expected reporter: %w", err)
+	}
+
+	query = `
+	SELECT id, created_at FROM hermes_data.expected_reporter
+	WHERE chat_context_id = $1 AND worker_id = $2 AND division = $3;
+	`
+
+	err = r.postgres.QueryRow(ctx, query, reporter.ChatContextID, reporter.WorkerID, reporter.Division).Scan(&reporter.ID, &reporter.CreatedAt)
+	if err != nil {
+		return models.ExpectedReporter{}, fmt.Errorf("failed to get expected reporter: %w", err)
+	}
+
+	return reporter, nil
+}
+
+func (r *Repository) DeleteExpectedReporter(ctx context.Context, chatContextID int, reporterID int) error {
+	query := `
+	DELETE FROM hermes_data.expected_reporter WHERE id = $1 AND chat_context_id = $2;
+	`
+
+	tag, err := r.postgres.Exec(ctx, query, reporterID, chatContextID)
+	if err != nil {
+		return fmt.Errorf("failed to delete expected reporter: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("failed to delete expected reporter: %w", sql.ErrNoRows)
+	}
+
+	return nil
+}
+
+// ClaimReminder records the reminder of the cutoff with the workers it is
+// about, it is false when the reminder is already sent, e.g. by another instance.
+func (r *Repository) ClaimReminder(ctx context.Context, chatContextID int, cutoff time.Time, kind models.ReminderKind, workerIDs []int) (bool, error) {
+	query := `
+	INSERT INTO hermes_data.reminder (chat_context_id, cutoff, kind, worker_ids)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT (chat_context_id, cutoff, kind) DO NOTHING;
+	`
+
+	tag, err := r.postgres.Exec(ctx, query, chatContextID, cutoff.UTC(), kind, workerIDs)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim reminder: %w", err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
@@ -0,0 +1,166 @@
+package reports
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
+)
+
+// ListExpectations returns the expectations of the chat context.
+func (r *Repository) ListExpectations(ctx context.Context, chatContextID int) ([]models.ReportExpectation, error) {
+	query := `
+	SELECT id, chat_context_id, division, operation, season_from, season_to, created_at
+	FROM hermes_data.report_expectation
+	WHERE chat_context_id = $1
+	ORDER BY operation, division, id;
+	`
+
+	rows, err := r.postgres.Query(ctx, query, chatContextID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list report expectations: %w", err)
+	}
+	defer rows.Close()
+
+	expectations := make([]models.ReportExpectation, 0)
+	for rows.Next() {
+		var expectation models.ReportExpectation
+		err := rows.Scan(
+			&expectation.ID, &expectation.ChatContextID, &expectation.Division, &expectation.Operation,
+			&expectation.SeasonFrom, &expectation.SeasonTo, &expectation.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan report expectation: %w", err)
+		}
+
+		expectations = append(expectations, expectation)
+	}
+
+	return expectations, rows.Err()
+}
+
+func (r *Repository) AddExpectation(ctx context.Context, expectation models.ReportExpectation) (models.ReportExpectation, error) {
+	query := `
+	INSERT INTO hermes_data.report_expectation (chat_context_id, division, operation, season_from, season_to)
+	VALUES ($1, $2, $3, $4, $5)
+	RETURNING id, created_at;
+	`
+
+	err := r.postgres.QueryRow(ctx, query,
+		expectation.ChatContextID, expectation.Division, expectation.Operation, expectation.SeasonFrom, expectation.SeasonTo,
+	).Scan(&expectation.ID, &expectation.CreatedAt)
+	if err != nil {
+		return models.ReportExpectation{}, fmt.Errorf("failed to add report expectation: %w", err)
+	}
+
+	return expectation, nil
+}
+
+func (r *Repository) DeleteExpectation(ctx context.Context, chatContextID int, expectationID int) error {
+	query := `
+	DELETE FROM hermes_data.report_expectation WHERE id = $1 AND chat_context_id = $2;
+	`
+
+	tag, err := r.postgres.Exec(ctx, query, expectationID, chatContextID)
+	if err != nil {
+		return fmt.Errorf("failed to delete report expectation: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("failed to delete report expectation: %w", sql.ErrNoRows)
+	}
+
+	return nil
+}
+
+// GetCompleteness returns the saved completeness of the report with its
+// missing items, sql.ErrNoRows when it has not been checked.
+func (r *Repository) GetCompleteness(ctx context.Context, reportID int) (models.Completeness, error) {
+	query := `
+	SELECT report_id, expected, covered
+	FROM hermes_data.report_completeness
+	WHERE report_id = $1;
+	`
+
+	var completeness models.Completeness
+	err := r.postgres.QueryRow(ctx, query, reportID).Scan(&completeness.ReportID, &completeness.Expected, &completeness.Covered)
+	if err != nil {
+		return models.Completeness{}, fmt.Errorf("failed to get report completeness: %w", err)
+	}
+
+	query = `
+	SELECT division, operation
+	FROM hermes_data.report_missing_item
+	WHERE report_id = $1
+	ORDER BY id;
+	`
+
+	rows, err := r.postgres.Query(ctx, query, reportID)
+	if err != nil {
+		return models.Completeness{}, fmt.Errorf("failed to get missing items: %w", err)
+	}
+	defer rows.Close()
+
+	completeness.Missing = make([]models.ExpectedItem, 0)
+	for rows.Next() {
+		var item models.ExpectedItem
+		err := rows.Scan(&item.Division, &item.Operation)
+		if err != nil {
+			return models.Completeness{}, fmt.Errorf("failed to scan missing item: %w", err)
+		}
+
+		completeness.Missing = append(completeness.Missing, item)
+	}
+
+	return completeness, rows.Err()
+}
+
+// SaveCompleteness replaces the completeness of the report and its missing items.
+func (r *Repository) SaveCompleteness(ctx context.Context, completeness models.Completeness) error {
+	tx, err := r.postgres.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+	INSERT INTO hermes_data.report_completeness (report_id, expected, covered)
+	VALUES ($1, $2, $3)
+	ON CONFLICT (report_id) DO UPDATE
+	SET expected = EXCLUDED.expected, covered = EXCLUDED.covered, computed_at = CURRENT_TIMESTAMP;
+	`
+
+	_, err = tx.Exec(ctx, query, completeness.ReportID, completeness.Expected, completeness.Covered)
+	if err != nil {
+		return fmt.Errorf("failed to save report completeness: %w", err)
+	}
+
+	query = `
+	DELETE FROM hermes_data.report_missing_item WHERE report_id = $1;
+	`
+
+	_, err = tx.Exec(ctx, query, completeness.ReportID)
+	if err != nil {
+		return fmt.Errorf("failed to delete missing items: %w", err)
+	}
+
+	query = `
+	INSERT INTO hermes_data.report_missing_item (report_id, division, operation)
+	VALUES ($1, $2, $3);
+	`
+
+	for _, item := range completeness.Missing {
+		_, err = tx.Exec(ctx, query, completeness.ReportID, item.Division, item.Operation)
+		if err != nil {
+			return fmt.Errorf("failed to insert missing item: %w", err)
+		}
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
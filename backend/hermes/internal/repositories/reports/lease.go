@@ -0,0 +1,46 @@
+package reports
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// AcquireLease takes the lease of the chat context for the owner, or renews
+// it. It is false when another owner holds a lease that has not expired.
+func (r *Repository) AcquireLease(ctx context.Context, chatContextID int, owner string, ttl time.Duration) (bool, error) {
+	query := `
+	INSERT INTO hermes_data.report_lease (chat_context_id, owner, expires_at)
+	VALUES ($1, $2, now() + make_interval(secs => $3))
+	ON CONFLICT (chat_context_id) DO UPDATE
+	SET owner = EXCLUDED.owner, expires_at = EXCLUDED.expires_at
+	WHERE report_lease.owner = EXCLUDED.owner OR report_lease.expires_at < now()
+	RETURNING owner;
+	`
+
+	var leaseOwner string
+	err := r.postgres.QueryRow(ctx, query, chatContextID, owner, ttl.Seconds()).Scan(&leaseOwner)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire report lease: %w", err)
+	}
+
+	return true, nil
+}
+
+func (r *Repository) ReleaseLease(ctx context.Context, chatContextID int, owner string) error {
+	query := `
+	DELETE FROM hermes_data.report_lease WHERE chat_context_id = $1 AND owner = $2;
+	`
+
+	_, err := r.postgres.Exec(ctx, query, chatContextID, owner)
+	if err != nil {
+		return fmt.Errorf("failed to release report lease: %w", err)
+	}
+
+	return nil
+}
@@ -2,11 +2,13 @@ package reports
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/clients/postgres"
 	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
 )
@@ -21,43 +23,49 @@ type Repository struct {
 	postgres *postgres.Client
 }
 
-func (r *Repository) AddTable(ctx context.Context, messageID int, createdAt time.Time, table models.Table) error {
+// AddTable saves the lines as json to hermes_data.tables and as a new version
+// of the message lines of the report to hermes_data.report_line.
+func (r *Repository) AddTable(ctx context.Context, reportID int, messageID int, createdAt time.Time, table models.Table) error {
 	if len(table) == 0 {
 		return nil
 	}
 
-	const query = `
-        INSERT INTO hermes_data.tables (message_id, created_at, data)
-        VALUES %s
-    `
+	tx, err := r.postgres.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
 
-	valueStrings := make([]string, 0, len(table))
-	args := make([]interface{}, 0, len(table)*3)
-	for i, line := range table {
-		valueStrings = append(valueStrings, fmt.Sprintf("($%d, $%d, $%d)", i*3+1, i*3+2, i*3+3))
+	query := `
+	SELECT COALESCE(MAX(version), 0) + 1 FROM hermes_data.report_line WHERE message_id = $1;
+	`
 
-		jsonLine, err := json.Marshal(line)
+	var version int
+	err = tx.QueryRow(ctx, query, messageID).Scan(&version)
+	if err != nil {
+		return fmt.Errorf("failed to get line version: %w", err)
+	}
+
+	for i, line := range table {
+		err = insertLine(ctx, tx, reportID, messageID, createdAt, version, i+1, line)
 		if err != nil {
-			return fmt.Errorf("failed to marshal line: %w", err)
+			return err
 		}
-
-		args = append(args, messageID, createdAt, json.RawMessage(jsonLine))
 	}
 
-	formattedQuery := fmt.Sprintf(query, strings.Join(valueStrings, ","))
-
-	_, err := r.postgres.Exec(ctx, formattedQuery, args...)
+	err = tx.Commit(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to bulk insert table lines: %w", err)
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
 	return nil
 }
 
-func (r *Repository) AddTableLine(ctx context.Context, messageID int, createdAt time.Time, line models.Line) error {
+func insertLine(ctx context.Context, tx pgx.Tx, reportID int, messageID int, createdAt time.Time, version int, number int, line models.Line) error {
 	query := `
 	INSERT INTO hermes_data.tables (message_id, created_at, data)
-	VALUES ($1, $2, $3);
+	VALUES ($1, $2, $3)
+	RETURNING id;
 	`
 
 	jsonLine, err := json.Marshal(line)
@@ -65,17 +73,104 @@ func (r *Repository) AddTableLine(ctx context.Context, messageID int, createdAt
 		return fmt.Errorf("failed to marshal line: %w", err)
 	}
 
-	_, err = r.postgres.Exec(ctx, query, messageID, createdAt, json.RawMessage(jsonLine))
+	var tableID int
+	err = tx.QueryRow(ctx, query, messageID, createdAt, json.RawMessage(jsonLine)).Scan(&tableID)
 	if err != nil {
 		return fmt.Errorf("failed to insert table line: %w", err)
 	}
 
+	flags := line.Flags
+	if flags == nil {
+		flags = []models.Flag{}
+	}
+
+	jsonFlags, err := json.Marshal(flags)
+	if err != nil {
+		return fmt.Errorf("failed to marshal flags: %w", err)
+	}
+
+	var unitID *int
+	if line.UnitID != 0 {
+		unitID = &line.UnitID
+	}
+
+	var measures []byte
+	if len(line.Measures) > 0 {
+		measures, err = json.Marshal(line.Measures)
+		if err != nil {
+			return fmt.Errorf("failed to marshal measures: %w", err)
+		}
+	}
+
+	var reportIDArg *int
+	if reportID != 0 {
+		reportIDArg = &reportID
+	}
+
+	// dictionary ids are of the versions in force at the line date
+	query = `
+	INSERT INTO hermes_data.report_line (
+		report_id, message_id, table_id, line_number, version,
+		date_from, date_to, date_raw,
+		division, division_raw, unit_id, pu, department,
+		operation, operation_raw, operation_id,
+		culture, culture_raw, culture_id,
+		per_day, per_operation, val_day, val_beginning,
+		per_day_unit, per_operation_unit, val_day_unit, val_beginning_unit, measures,
+		division_yellow, division_suggestion, operation_yellow, operation_suggestion,
+		culture_yellow, culture_suggestion,
+		flags, created_at
+	)
+	SELECT
+		$23, $1, $2, $3, $4,
+		$5, $6, NULLIF($7, ''),
+		$8, NULLIF($9, ''), $10, NULLIF($11, ''), NULLIF($12, ''),
+		$13, NULLIF($14, ''),
+		(
+			SELECT o.id
+			FROM hermes_data.operations o
+			WHERE o.name = $13 AND o.effective_from <= $6 AND (o.deleted_at IS NULL OR o.deleted_at > $6)
+			ORDER BY o.effective_from DESC
+			LIMIT 1
+		),
+		$15, NULLIF($16, ''),
+		(
+			SELECT cu.id
+			FROM hermes_data.cultures cu
+			WHERE cu.name = $15 AND cu.effective_from <= $6 AND (cu.deleted_at IS NULL OR cu.deleted_at > $6)
+			ORDER BY cu.effective_from DESC
+			LIMIT 1
+		),
+		$17, $18, $19, $20,
+		COALESCE(NULLIF($24, ''), 'га'), COALESCE(NULLIF($25, ''), 'га'),
+		COALESCE(NULLIF($26, ''), 'ц'), COALESCE(NULLIF($27, ''), 'ц'), $28,
+		$29, NULLIF($30, ''), $31, NULLIF($32, ''),
+		$33, NULLIF($34, ''),
+		$21, $22;
+	`
+
+	_, err = tx.Exec(ctx, query,
+		messageID, tableID, number, version,
+		line.Date.From, line.Date.To, line.DateRaw,
+		line.Division, line.DivisionRaw, unitID, line.PU, line.Department,
+		line.Operation, line.OperationRaw,
+		line.Culture, line.CultureRaw,
+		line.PerDay, line.PerOperation, line.ValDay, line.ValBeginning,
+		json.RawMessage(jsonFlags), createdAt, reportIDArg,
+		line.PerDayUnit, line.PerOperationUnit, line.ValDayUnit, line.ValBeginningUnit, measures,
+		line.DivisionYellow, line.DivisionSuggestion, line.OperationYellow, line.OperationSuggestion,
+		line.CultureYellow, line.CultureSuggestion,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert report line: %w", err)
+	}
+
 	return nil
 }
 
 func (r *Repository) GetNotFinishedReports(ctx context.Context, chatContextID int) ([]models.Report, error) {
 	query := `
-	SELECT id, chat_context_id, started_at, last_updated_at FROM hermes_data.report WHERE chat_context_id = $1 AND finished_at IS NULL;
+	SELECT id, chat_context_id, started_at, last_updated_at, closes_at, time_zone, status FROM hermes_data.report WHERE chat_context_id = $1 AND finished_at IS NULL;
 	`
 
 	rows, err := r.postgres.Query(ctx, query, chatContextID)
@@ -86,7 +181,62 @@ func (r *Repository) GetNotFinishedReports(ctx context.Context, chatContextID in
 	reports := make([]models.Report, 0)
 	for rows.Next() {
 		var report models.Report
-		err := rows.Scan(&report.ID, &report.ChatContextID, &report.StartedAt, &report.LastUpdatedAt)
+		err := rows.Scan(&report.ID, &report.ChatContextID, &report.StartedAt, &report.LastUpdatedAt, &report.ClosesAt, &report.TimeZone, &report.Status)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan report: %w", err)
+		}
+
+		reports = append(reports, report)
+	}
+
+	return reports, rows.Err()
+}
+
+// GetOpenReports returns the not finished reports of all chat contexts.
+func (r *Repository) GetOpenReports(ctx context.Context) ([]models.Report, error) {
+	query := `
+	SELECT id, chat_context_id, started_at, last_updated_at, closes_at, time_zone, status FROM hermes_data.report WHERE finished_at IS NULL ORDER BY id;
+	`
+
+	rows, err := r.postgres.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get open reports: %w", err)
+	}
+	defer rows.Close()
+
+	reports := make([]models.Report, 0)
+	for rows.Next() {
+		var report models.Report
+		err := rows.Scan(&report.ID, &report.ChatContextID, &report.StartedAt, &report.LastUpdatedAt, &report.ClosesAt, &report.TimeZone, &report.Status)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan report: %w", err)
+		}
+
+		reports = append(reports, report)
+	}
+
+	return reports, rows.Err()
+}
+
+// GetClosedReports returns the finished reports of all chat contexts closed
+// in (from, to]: by a cutoff in the period or, without one, finished in it.
+func (r *Repository) GetClosedReports(ctx context.Context, from time.Time, to time.Time) ([]models.Report, error) {
+	query := `
+	SELECT id, chat_context_id, started_at, last_updated_at, finished_at, closes_at, time_zone, status FROM hermes_data.report
+	WHERE finished_at IS NOT NULL AND LEAST(closes_at, finished_at) > $1 AND LEAST(closes_at, finished_at) <= $2
+	ORDER BY chat_context_id, id;
+	`
+
+	rows, err := r.postgres.Query(ctx, query, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get closed reports: %w", err)
+	}
+	defer rows.Close()
+
+	reports := make([]models.Report, 0)
+	for rows.Next() {
+		var report models.Report
+		err := rows.Scan(&report.ID, &report.ChatContextID, &report.StartedAt, &report.LastUpdatedAt, &report.FinishedAt, &report.ClosesAt, &report.TimeZone, &report.Status)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan report: %w", err)
 		}
@@ -94,29 +244,43 @@ func (r *Repository) GetNotFinishedReports(ctx context.Context, chatContextID in
 		reports = append(reports, report)
 	}
 
-	return reports, nil
+	return reports, rows.Err()
 }
 
-func (r *Repository) CreateReport(ctx context.Context, report models.Report) (int, error) {
+// CreateReport creates the report unless the chat context has an open one,
+// e.g. created by another instance, which is returned instead.
+func (r *Repository) CreateReport(ctx context.Context, report models.Report) (models.Report, error) {
 	query := `
-	INSERT INTO hermes_data.report (chat_context_id, started_at, last_updated_at)
-	VALUES ($1, $2, $2)
+	INSERT INTO hermes_data.report (chat_context_id, started_at, last_updated_at, closes_at, time_zone)
+	VALUES ($1, $2, $2, $3, $4)
+	ON CONFLICT (chat_context_id) WHERE finished_at IS NULL DO NOTHING
 	RETURNING id;
 	`
 
-	var reportID int
-	err := r.postgres.QueryRow(ctx, query, report.ChatContextID, report.StartedAt).Scan(&reportID)
+	err := r.postgres.QueryRow(ctx, query, report.ChatContextID, report.StartedAt, report.ClosesAt, report.TimeZone).Scan(&report.ID)
+	if err == nil {
+		report.Status = models.StatusCollecting
+		return report, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return models.Report{}, fmt.Errorf("failed to create report: %w", err)
+	}
+
+	reports, err := r.GetNotFinishedReports(ctx, report.ChatContextID)
 	if err != nil {
-		return 0, fmt.Errorf("failed to create report: %w", err)
+		return models.Report{}, err
+	}
+	if len(reports) == 0 {
+		return models.Report{}, fmt.Errorf("failed to create report: open report of chat context %d is finished", report.ChatContextID)
 	}
 
-	return reportID, nil
+	return reports[0], nil
 }
 
 func (r *Repository) UpdateReport(ctx context.Context, reportID int, timestamp time.Time) error {
 	query := `
 	UPDATE hermes_data.report
-	SET last_updated_at = $1
+	SET last_updated_at = GREATEST(last_updated_at, $1)
 	WHERE id = $2;
 	`
 
@@ -128,17 +292,168 @@ func (r *Repository) UpdateReport(ctx context.Context, reportID int, timestamp t
 	return nil
 }
 
-func (r *Repository) FinishReport(ctx context.Context, reportID int, timestamp time.Time) error {
+func (r *Repository) SetClosesAt(ctx context.Context, reportID int, closesAt time.Time) error {
 	query := `
 	UPDATE hermes_data.report
-	SET finished_at = $1
+	SET closes_at = $1
 	WHERE id = $2;
 	`
 
-	_, err := r.postgres.Exec(ctx, query, timestamp, reportID)
+	_, err := r.postgres.Exec(ctx, query, closesAt, reportID)
 	if err != nil {
-		return fmt.Errorf("failed to finish report: %w", err)
+		return fmt.Errorf("failed to set report cutoff: %w", err)
 	}
 
 	return nil
 }
+
+// FinishReport finishes the report with the status, it is false when the
+// report is already finished.
+func (r *Repository) FinishReport(ctx context.Context, reportID int, timestamp time.Time, status models.ReportStatus) (bool, error) {
+	query := `
+	UPDATE hermes_data.report
+	SET finished_at = $1, status = $3
+	WHERE id = $2 AND finished_at IS NULL;
+	`
+
+	tag, err := r.postgres.Exec(ctx, query, timestamp, reportID, status)
+	if err != nil {
+		return false, fmt.Errorf("failed to finish report: %w", err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// GetReport returns the report with its messages, their media and senders,
+// and the last version of the recognized lines.
+func (r *Repository) GetReport(ctx context.Context, reportID int) (models.ReportDetails, error) {
+	query := `
+	SELECT r.id, r.chat_context_id, r.started_at, r.last_updated_at, r.finished_at, r.closes_at, r.time_zone, r.status, cc.name
+	FROM hermes_data.report r
+	JOIN hermes_data.chat_context cc ON cc.id = r.chat_context_id
+	WHERE r.id = $1;
+	`
+
+	var details models.ReportDetails
+	err := r.postgres.QueryRow(ctx, query, reportID).Scan(
+		&details.ID, &details.ChatContextID, &details.StartedAt, &details.LastUpdatedAt, &details.FinishedAt, &details.ClosesAt, &details.TimeZone, &details.Status,
+		&details.ChatContextName,
+	)
+	if err != nil {
+		return models.ReportDetails{}, fmt.Errorf("failed to get report: %w", err)
+	}
+
+	details.Messages, err = r.getReportMessages(ctx, reportID)
+	if err != nil {
+		return models.ReportDetails{}, err
+	}
+
+	details.Lines, err = r.GetReportLines(ctx, reportID)
+	if err != nil {
+		return models.ReportDetails{}, err
+	}
+
+	details.Reviews, err = r.GetReviews(ctx, reportID)
+	if err != nil {
+		return models.ReportDetails{}, err
+	}
+
+	return details, nil
+}
+
+func (r *Repository) getReportMessages(ctx context.Context, reportID int) ([]models.ReportMessage, error) {
+	query := `
+	SELECT m.id, m.worker_id, COALESCE(w.name, ''), m.chat_id, c.type, c.chat_name, m.created_at, m.content, m.role,
+	       ARRAY(SELECT i.image_url FROM hermes_data.images i WHERE i.message_id = m.id ORDER BY i.id),
+	       ARRAY(SELECT a.audio_url FROM hermes_data.audios a WHERE a.message_id = m.id ORDER BY a.id)
+	FROM hermes_data.messages m
+	JOIN hermes_data.worker w ON w.id = m.worker_id
+	JOIN hermes_data.chat c ON c.id = m.chat_id
+	WHERE m.report_id = $1
+	ORDER BY m.created_at, m.id;
+	`
+
+	rows, err := r.postgres.Query(ctx, query, reportID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get report messages: %w", err)
+	}
+	defer rows.Close()
+
+	messages := make([]models.ReportMessage, 0)
+	for rows.Next() {
+		var message models.ReportMessage
+		err := rows.Scan(
+			&message.ID, &message.WorkerID, &message.WorkerName, &message.ChatID, &message.ChatType, &message.ChatName,
+			&message.CreatedAt, &message.Content, &message.Role, &message.Images, &message.Audios,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan report message: %w", err)
+		}
+
+		messages = append(messages, message)
+	}
+
+	return messages, rows.Err()
+}
+
+// GetReportLines returns the last version of the lines of every message of the report.
+func (r *Repository) GetReportLines(ctx context.Context, reportID int) ([]models.ReportLine, error) {
+	query := `
+	SELECT id, message_id, line_number, version, operation_id, culture_id,
+	       date_from, date_to, COALESCE(date_raw, ''),
+	       division, COALESCE(division_raw, ''), COALESCE(unit_id, 0), COALESCE(pu, ''), COALESCE(department, ''),
+	       operation, COALESCE(operation_raw, ''),
+	       culture, COALESCE(culture_raw, ''),
+	       per_day, per_operation, val_day, val_beginning,
+	       per_day_unit, per_operation_unit, val_day_unit, val_beginning_unit, measures,
+	       division_yellow, COALESCE(division_suggestion, ''), operation_yellow, COALESCE(operation_suggestion, ''),
+	       culture_yellow, COALESCE(culture_suggestion, ''),
+	       flags
+	FROM hermes_data.report_line_current
+	WHERE report_id = $1
+	ORDER BY message_id, line_number;
+	`
+
+	rows, err := r.postgres.Query(ctx, query, reportID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get report lines: %w", err)
+	}
+	defer rows.Close()
+
+	lines := make([]models.ReportLine, 0)
+	for rows.Next() {
+		var line models.ReportLine
+		var measures, flags []byte
+		err := rows.Scan(
+			&line.ID, &line.MessageID, &line.Number, &line.Version, &line.OperationID, &line.CultureID,
+			&line.Date.From, &line.Date.To, &line.DateRaw,
+			&line.Division, &line.DivisionRaw, &line.UnitID, &line.PU, &line.Department,
+			&line.Operation, &line.OperationRaw,
+			&line.Culture, &line.CultureRaw,
+			&line.PerDay, &line.PerOperation, &line.ValDay, &line.ValBeginning,
+			&line.PerDayUnit, &line.PerOperationUnit, &line.ValDayUnit, &line.ValBeginningUnit, &measures,
+			&line.DivisionYellow, &line.DivisionSuggestion, &line.OperationYellow, &line.OperationSuggestion,
+			&line.CultureYellow, &line.CultureSuggestion,
+			&flags,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan report line: %w", err)
+		}
+
+		err = json.Unmarshal(flags, &line.Flags)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal flags: %w", err)
+		}
+
+		if measures != nil {
+			err = json.Unmarshal(measures, &line.Measures)
+			if err != nil {
+				return nil, fmt.Errorf("failed to unmarshal measures: %w", err)
+			}
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines, rows.Err()
+}
@@ -0,0 +1,97 @@
+package reports
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
+)
+
+// SetStatus changes the status of the finished report when it is one of from,
+// it is false when the report has another status, e.g. it was approved by
+// another reviewer meanwhile.
+func (r *Repository) SetStatus(ctx context.Context, reportID int, status models.ReportStatus, from ...models.ReportStatus) (bool, error) {
+	statuses := make([]string, 0, len(from))
+	for _, s := range from {
+		statuses = append(statuses, string(s))
+	}
+
+	query := `
+	UPDATE hermes_data.report
+	SET status = $1
+	WHERE id = $2 AND finished_at IS NOT NULL AND status = ANY($3);
+	`
+
+	tag, err := r.postgres.Exec(ctx, query, status, reportID, statuses)
+	if err != nil {
+		return false, fmt.Errorf("failed to set report status: %w", err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// GetReviewableReport returns the last closed report of the chat context
+// waiting for the review or rejected.
+func (r *Repository) GetReviewableReport(ctx context.Context, chatContextID int) (models.Report, error) {
+	query := `
+	SELECT id, chat_context_id, started_at, last_updated_at, finished_at, closes_at, time_zone, status FROM hermes_data.report
+	WHERE chat_context_id = $1 AND finished_at IS NOT NULL AND status IN ('pending_review', 'rejected')
+	ORDER BY finished_at DESC, id DESC
+	LIMIT 1;
+	`
+
+	var report models.Report
+	err := r.postgres.QueryRow(ctx, query, chatContextID).Scan(
+		&report.ID, &report.ChatContextID, &report.StartedAt, &report.LastUpdatedAt, &report.FinishedAt, &report.ClosesAt, &report.TimeZone, &report.Status,
+	)
+	if err != nil {
+		return models.Report{}, fmt.Errorf("failed to get reviewable report: %w", err)
+	}
+
+	return report, nil
+}
+
+func (r *Repository) AddReview(ctx context.Context, review models.ReportReview) error {
+	query := `
+	INSERT INTO hermes_data.report_review (report_id, worker_id, action, comment, created_at)
+	VALUES ($1, $2, $3, $4, $5);
+	`
+
+	_, err := r.postgres.Exec(ctx, query, review.ReportID, review.WorkerID, review.Action, review.Comment, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to add report review: %w", err)
+	}
+
+	return nil
+}
+
+// GetReviews returns the review actions on the report, oldest first.
+func (r *Repository) GetReviews(ctx context.Context, reportID int) ([]models.ReportReview, error) {
+	query := `
+	SELECT rr.id, rr.report_id, rr.worker_id, COALESCE(w.name, ''), rr.action, rr.comment, rr.created_at
+	FROM hermes_data.report_review rr
+	JOIN hermes_data.worker w ON w.id = rr.worker_id
+	WHERE rr.report_id = $1
+	ORDER BY rr.created_at, rr.id;
+	`
+
+	rows, err := r.postgres.Query(ctx, query, reportID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get report reviews: %w", err)
+	}
+	defer rows.Close()
+
+	reviews := make([]models.ReportReview, 0)
+	for rows.Next() {
+		var review models.ReportReview
+		err := rows.Scan(&review.ID, &review.ReportID, &review.WorkerID, &review.WorkerName, &review.Action, &review.Comment, &review.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan report review: %w", err)
+		}
+
+		reviews = append(reviews, review)
+	}
+
+	return reviews, rows.Err()
+}
@@ -3,22 +3,31 @@ package repositories
 import (
 	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/clients/postgres"
 	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/repositories/chats"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/repositories/digests"
 	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/repositories/information"
 	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/repositories/messages"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/repositories/progress"
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/repositories/reminders"
 	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/repositories/reports"
 	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/repositories/workers"
 )
 
 func NewRepositories(postgres *postgres.Client) *Repositories {
 	chatsRepo := chats.NewRepository(postgres)
+	digestsRepo := digests.NewRepository(postgres)
 	informationRepo := information.NewRepository(postgres)
 	messagesRepo := messages.NewRepository(postgres)
+	progressRepo := progress.NewRepository(postgres)
+	remindersRepo := reminders.NewRepository(postgres)
 	reportsRepo := reports.NewRepository(postgres)
 	workersRepo := workers.NewRepository(postgres)
 	return &Repositories{
 		ChatsRepo:       chatsRepo,
+		DigestsRepo:     digestsRepo,
 		InformationRepo: informationRepo,
 		MessagesRepo:    messagesRepo,
+		ProgressRepo:    progressRepo,
+		RemindersRepo:   remindersRepo,
 		ReportsRepo:     reportsRepo,
 		WorkersRepo:     workersRepo,
 	}
@@ -26,8 +35,11 @@ func NewRepositories(postgres *postgres.Client) *Repositories {
 
 type Repositories struct {
 	ChatsRepo       *chats.Repository
+	DigestsRepo     *digests.Repository
 	InformationRepo *information.Repository
 	MessagesRepo    *messages.Repository
+	ProgressRepo    *progress.Repository
+	RemindersRepo   *reminders.Repository
 	ReportsRepo     *reports.Repository
 	WorkersRepo     *workers.Repository
 }
@@ -0,0 +1,78 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lild1tz/llm_coding_challenge/backend/hermes/internal/models"
+)
+
+// ListContacts returns the workers with their messenger accounts.
+func (r *Repository) ListContacts(ctx context.Context) ([]models.Contact, error) {
+	query := `
+	SELECT w.id, COALESCE(w.name, ''),
+	       ARRAY(SELECT wa.whatsapp_id FROM hermes_data.whatsapp wa WHERE wa.worker_id = w.id ORDER BY wa.id),
+	       ARRAY(SELECT tg.telegram_id FROM hermes_data.telegram tg WHERE tg.worker_id = w.id ORDER BY tg.id)
+	FROM hermes_data.worker w
+	ORDER BY w.id;
+	`
+
+	return r.queryContacts(ctx, query)
+}
+
+func (r *Repository) GetContact(ctx context.Context, workerID int) (models.Contact, error) {
+	query := `
+	SELECT w.id, COALESCE(w.name, ''),
+	       ARRAY(SELECT wa.whatsapp_id FROM hermes_data.whatsapp wa WHERE wa.worker_id = w.id ORDER BY wa.id),
+	       ARRAY(SELECT tg.telegram_id FROM hermes_data.telegram tg WHERE tg.worker_id = w.id ORDER BY tg.id)
+	FROM hermes_data.worker w
+	WHERE w.id = $1;
+	`
+
+	var contact models.Contact
+	err := r.postgres.QueryRow(ctx, query, workerID).Scan(&contact.WorkerID, &contact.WorkerName, &contact.WhatsappIDs, &contact.TelegramIDs)
+	if err != nil {
+		return models.Contact{}, fmt.Errorf("failed to get worker: %w", err)
+	}
+
+	return contact, nil
+}
+
+// GetListeners returns the listeners of the chats of the chat context.
+func (r *Repository) GetListeners(ctx context.Context, chatContextID int) ([]models.Contact, error) {
+	query := `
+	SELECT w.id, COALESCE(w.name, ''),
+	       ARRAY(SELECT wa.whatsapp_id FROM hermes_data.whatsapp wa WHERE wa.worker_id = w.id ORDER BY wa.id),
+	       ARRAY(SELECT tg.telegram_id FROM hermes_data.telegram tg WHERE tg.worker_id = w.id ORDER BY tg.id)
+	FROM hermes_data.worker w
+	WHERE w.id IN (
+		SELECT l.worker_id FROM hermes_data.listener l
+		JOIN hermes_data.chat c ON c.id = l.chat_id
+		WHERE c.chat_context_id = $1
+	)
+	ORDER BY w.id;
+	`
+
+	return r.queryContacts(ctx, query, chatContextID)
+}
+
+func (r *Repository) queryContacts(ctx context.Context, query string, args ...any) ([]models.Contact, error) {
+	rows, err := r.postgres.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workers: %w", err)
+	}
+	defer rows.Close()
+
+	contacts := make([]models.Contact, 0)
+	for rows.Next() {
+		var contact models.Contact
+		err := rows.Scan(&contact.WorkerID, &contact.WorkerName, &contact.WhatsappIDs, &contact.TelegramIDs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan worker: %w", err)
+		}
+
+		contacts = append(contacts, contact)
+	}
+
+	return contacts, rows.Err()
+}
@@ -0,0 +1,4 @@
+ALTER TABLE hermes_data.messages
+    DROP COLUMN audio_duration_seconds,
+    DROP COLUMN audio_codec,
+    DROP COLUMN transcription_cost;
@@ -0,0 +1,4 @@
+ALTER TABLE hermes_data.messages
+    ADD COLUMN audio_duration_seconds DOUBLE PRECISION,
+    ADD COLUMN audio_codec VARCHAR(255),
+    ADD COLUMN transcription_cost NUMERIC(12, 6);
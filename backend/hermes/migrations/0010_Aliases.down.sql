@@ -0,0 +1,3 @@
+DROP TABLE hermes_data.unit_aliases;
+DROP TABLE hermes_data.operation_aliases;
+DROP TABLE hermes_data.culture_aliases;
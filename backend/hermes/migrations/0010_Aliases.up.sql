@@ -0,0 +1,50 @@
+CREATE TABLE hermes_data.culture_aliases (
+    id SERIAL PRIMARY KEY,
+    alias VARCHAR(1023) NOT NULL,
+    culture_id INTEGER NOT NULL,
+    chat_context_id INTEGER,
+    global BOOLEAN NOT NULL DEFAULT FALSE,
+    source VARCHAR(255) NOT NULL,
+
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    approved_at TIMESTAMP,
+
+    FOREIGN KEY (culture_id) REFERENCES hermes_data.cultures,
+    FOREIGN KEY (chat_context_id) REFERENCES hermes_data.chat_context
+);
+
+CREATE UNIQUE INDEX culture_aliases_alias_idx ON hermes_data.culture_aliases (LOWER(alias), COALESCE(chat_context_id, 0));
+
+CREATE TABLE hermes_data.operation_aliases (
+    id SERIAL PRIMARY KEY,
+    alias VARCHAR(1023) NOT NULL,
+    operation_id INTEGER NOT NULL,
+    chat_context_id INTEGER,
+    global BOOLEAN NOT NULL DEFAULT FALSE,
+    source VARCHAR(255) NOT NULL,
+
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    approved_at TIMESTAMP,
+
+    FOREIGN KEY (operation_id) REFERENCES hermes_data.operations,
+    FOREIGN KEY (chat_context_id) REFERENCES hermes_data.chat_context
+);
+
+CREATE UNIQUE INDEX operation_aliases_alias_idx ON hermes_data.operation_aliases (LOWER(alias), COALESCE(chat_context_id, 0));
+
+-- units have several rows per division, so aliases point to the division name
+CREATE TABLE hermes_data.unit_aliases (
+    id SERIAL PRIMARY KEY,
+    alias VARCHAR(1023) NOT NULL,
+    division VARCHAR(1023) NOT NULL,
+    chat_context_id INTEGER,
+    global BOOLEAN NOT NULL DEFAULT FALSE,
+    source VARCHAR(255) NOT NULL,
+
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    approved_at TIMESTAMP,
+
+    FOREIGN KEY (chat_context_id) REFERENCES hermes_data.chat_context
+);
+
+CREATE UNIQUE INDEX unit_aliases_alias_idx ON hermes_data.unit_aliases (LOWER(alias), COALESCE(chat_context_id, 0));
@@ -0,0 +1,13 @@
+DROP TABLE hermes_data.dictionary_history;
+
+ALTER TABLE hermes_data.units
+    DROP COLUMN effective_from,
+    DROP COLUMN deleted_at;
+
+ALTER TABLE hermes_data.operations
+    DROP COLUMN effective_from,
+    DROP COLUMN deleted_at;
+
+ALTER TABLE hermes_data.cultures
+    DROP COLUMN effective_from,
+    DROP COLUMN deleted_at;
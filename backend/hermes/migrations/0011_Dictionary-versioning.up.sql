@@ -0,0 +1,24 @@
+-- a version of an entry is in force from effective_from until deleted_at
+ALTER TABLE hermes_data.cultures
+    ADD COLUMN effective_from DATE NOT NULL DEFAULT '2000-01-01',
+    ADD COLUMN deleted_at TIMESTAMP;
+
+ALTER TABLE hermes_data.operations
+    ADD COLUMN effective_from DATE NOT NULL DEFAULT '2000-01-01',
+    ADD COLUMN deleted_at TIMESTAMP;
+
+ALTER TABLE hermes_data.units
+    ADD COLUMN effective_from DATE NOT NULL DEFAULT '2000-01-01',
+    ADD COLUMN deleted_at TIMESTAMP;
+
+CREATE TABLE hermes_data.dictionary_history (
+    id SERIAL PRIMARY KEY,
+    dictionary VARCHAR(255) NOT NULL,
+    entry_id INTEGER NOT NULL,
+    action VARCHAR(255) NOT NULL,
+    data JSONB NOT NULL,
+    changed_by VARCHAR(1023) NOT NULL,
+    changed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX dictionary_history_entry_idx ON hermes_data.dictionary_history (dictionary, entry_id);
@@ -0,0 +1,8 @@
+DROP TRIGGER unit_aliases_notify ON hermes_data.unit_aliases;
+DROP TRIGGER operation_aliases_notify ON hermes_data.operation_aliases;
+DROP TRIGGER culture_aliases_notify ON hermes_data.culture_aliases;
+DROP TRIGGER units_notify ON hermes_data.units;
+DROP TRIGGER operations_notify ON hermes_data.operations;
+DROP TRIGGER cultures_notify ON hermes_data.cultures;
+
+DROP FUNCTION hermes_data.notify_dictionary_change();
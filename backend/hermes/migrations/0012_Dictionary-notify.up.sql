@@ -0,0 +1,25 @@
+-- hermes keeps dictionaries in memory and reloads the table named in the payload
+CREATE FUNCTION hermes_data.notify_dictionary_change() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('hermes_dictionaries', TG_TABLE_NAME);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER cultures_notify AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON hermes_data.cultures
+    FOR EACH STATEMENT EXECUTE FUNCTION hermes_data.notify_dictionary_change();
+
+CREATE TRIGGER operations_notify AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON hermes_data.operations
+    FOR EACH STATEMENT EXECUTE FUNCTION hermes_data.notify_dictionary_change();
+
+CREATE TRIGGER units_notify AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON hermes_data.units
+    FOR EACH STATEMENT EXECUTE FUNCTION hermes_data.notify_dictionary_change();
+
+CREATE TRIGGER culture_aliases_notify AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON hermes_data.culture_aliases
+    FOR EACH STATEMENT EXECUTE FUNCTION hermes_data.notify_dictionary_change();
+
+CREATE TRIGGER operation_aliases_notify AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON hermes_data.operation_aliases
+    FOR EACH STATEMENT EXECUTE FUNCTION hermes_data.notify_dictionary_change();
+
+CREATE TRIGGER unit_aliases_notify AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON hermes_data.unit_aliases
+    FOR EACH STATEMENT EXECUTE FUNCTION hermes_data.notify_dictionary_change();
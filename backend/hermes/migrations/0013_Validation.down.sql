@@ -0,0 +1,4 @@
+DROP TABLE hermes_data.validation_limits;
+
+ALTER TABLE hermes_data.units
+    DROP COLUMN area_ha;
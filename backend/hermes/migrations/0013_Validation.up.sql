@@ -0,0 +1,30 @@
+-- cumulative hectares of a unit must not exceed its area
+ALTER TABLE hermes_data.units
+    ADD COLUMN area_ha DOUBLE PRECISION;
+
+-- sanity ranges for report lines, the most specific row wins:
+-- operation and culture, operation, culture, then the row with neither
+CREATE TABLE hermes_data.validation_limits (
+    id SERIAL PRIMARY KEY,
+    operation VARCHAR(1023),
+    culture VARCHAR(1023),
+    max_per_day_ha DOUBLE PRECISION,
+    min_yield DOUBLE PRECISION,
+    max_yield DOUBLE PRECISION,
+
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX validation_limits_key_idx ON hermes_data.validation_limits (COALESCE(operation, ''), COALESCE(culture, ''));
+
+-- yields are in c/ha
+INSERT INTO hermes_data.validation_limits (operation, culture, max_per_day_ha, min_yield, max_yield)
+VALUES (NULL, 'Пшеница озимая товарная', NULL, 10, 120),
+       (NULL, 'Пшеница озимая семенная', NULL, 10, 120),
+       (NULL, 'Ячмень озимый', NULL, 10, 110),
+       (NULL, 'Подсолнечник товарный', NULL, 5, 55),
+       (NULL, 'Подсолнечник семенной', NULL, 5, 55),
+       (NULL, 'Кукуруза товарная', NULL, 10, 160),
+       (NULL, 'Соя товарная', NULL, 5, 50),
+       (NULL, 'Свекла сахарная', NULL, 150, 900);
@@ -0,0 +1,2 @@
+DROP VIEW hermes_data.progress_totals;
+DROP TABLE hermes_data.progress_ledger;
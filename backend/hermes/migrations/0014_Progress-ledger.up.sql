@@ -0,0 +1,38 @@
+-- running totals of report lines per division, operation, culture and season
+CREATE TABLE hermes_data.progress_ledger (
+    id SERIAL PRIMARY KEY,
+    season INTEGER NOT NULL,
+    division VARCHAR(1023) NOT NULL,
+    operation VARCHAR(1023) NOT NULL,
+    culture VARCHAR(1023) NOT NULL,
+    date DATE NOT NULL,
+    message_id INTEGER NOT NULL,
+
+    per_day DOUBLE PRECISION NOT NULL,
+    per_operation_reported DOUBLE PRECISION,
+    per_operation_computed DOUBLE PRECISION NOT NULL,
+    val_day DOUBLE PRECISION NOT NULL,
+    val_beginning_reported DOUBLE PRECISION,
+    val_beginning_computed DOUBLE PRECISION NOT NULL,
+
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (message_id) REFERENCES hermes_data.messages
+);
+
+CREATE INDEX progress_ledger_key_idx ON hermes_data.progress_ledger (season, division, operation, culture, date);
+
+-- used by Superset
+CREATE VIEW hermes_data.progress_totals AS
+SELECT season, division, operation, culture,
+       MIN(date) AS started_on,
+       MAX(date) AS last_reported_on,
+       SUM(per_day) AS total_ha,
+       SUM(val_day) AS total_val,
+       COUNT(*) AS lines,
+       COUNT(*) FILTER (
+           WHERE per_operation_reported IS NOT NULL
+             AND ABS(per_operation_reported - per_operation_computed) > GREATEST(1, per_operation_computed * 0.05)
+       ) AS discrepancies
+FROM hermes_data.progress_ledger
+GROUP BY season, division, operation, culture;
@@ -0,0 +1,2 @@
+DROP VIEW hermes_data.report_line_current;
+DROP TABLE hermes_data.report_line;
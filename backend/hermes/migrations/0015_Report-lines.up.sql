@@ -0,0 +1,258 @@
+-- recognized lines with typed columns, hermes_data.tables keeps the raw json
+-- of the same lines. A message recognized again gets a new version of its
+-- lines, the previous versions are kept.
+CREATE TABLE hermes_data.report_line (
+    id SERIAL PRIMARY KEY,
+    report_id INTEGER,
+    message_id INTEGER NOT NULL,
+    table_id INTEGER,
+    line_number INTEGER NOT NULL,
+    version INTEGER NOT NULL DEFAULT 1,
+
+    date_from DATE NOT NULL,
+    date_to DATE NOT NULL,
+    date_raw VARCHAR(1023),
+
+    division VARCHAR(1023) NOT NULL,
+    division_raw VARCHAR(1023),
+    unit_id INTEGER,
+    pu VARCHAR(1023),
+    department VARCHAR(1023),
+
+    operation VARCHAR(1023) NOT NULL,
+    operation_raw VARCHAR(1023),
+    operation_id INTEGER,
+
+    culture VARCHAR(1023) NOT NULL,
+    culture_raw VARCHAR(1023),
+    culture_id INTEGER,
+
+    per_day DOUBLE PRECISION NOT NULL,
+    per_operation DOUBLE PRECISION NOT NULL,
+    val_day DOUBLE PRECISION NOT NULL,
+    val_beginning DOUBLE PRECISION NOT NULL,
+
+    flags JSONB NOT NULL DEFAULT '[]',
+
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (report_id) REFERENCES hermes_data.report,
+    FOREIGN KEY (message_id) REFERENCES hermes_data.messages,
+    FOREIGN KEY (table_id) REFERENCES hermes_data.tables,
+    FOREIGN KEY (unit_id) REFERENCES hermes_data.units,
+    FOREIGN KEY (operation_id) REFERENCES hermes_data.operations,
+    FOREIGN KEY (culture_id) REFERENCES hermes_data.cultures,
+
+    UNIQUE (message_id, version, line_number)
+);
+
+CREATE INDEX report_line_report_idx ON hermes_data.report_line (report_id);
+CREATE INDEX report_line_date_idx ON hermes_data.report_line (date_to);
+
+-- the last version of every message, used by Superset
+CREATE VIEW hermes_data.report_line_current AS
+SELECT l.*
+FROM hermes_data.report_line l
+WHERE l.version = (
+    SELECT MAX(v.version) FROM hermes_data.report_line v WHERE v.message_id = l.message_id
+);
+
+-- parse_line_day and parse_line_date mirror models.ParseDate without month
+-- names: "02.01.2006", "02.01.06", Apollo's "02.01" and "02", the ISO
+-- "2006-01-02" of the stub, "вчера" and ranges like "30.12-02.01". A day
+-- without the year or the month is taken from the message day, the previous
+-- year or month when it would be more than a day after it.
+CREATE FUNCTION hermes_data.parse_line_day(value TEXT, default_month INTEGER, default_year INTEGER, today DATE) RETURNS DATE AS $$
+DECLARE
+    parts TEXT[];
+    d INTEGER;
+    m INTEGER;
+    y INTEGER;
+    result DATE;
+    previous DATE;
+BEGIN
+    parts := regexp_match(btrim(value), '^(\d{1,2})(?:[./](\d{1,2})(?:[./](\d{2}|\d{4}))?)?$');
+    IF parts IS NULL THEN
+        RETURN NULL;
+    END IF;
+
+    d := parts[1]::INTEGER;
+    m := COALESCE(NULLIF(parts[2]::INTEGER, 0), default_month);
+    y := COALESCE(NULLIF(parts[3]::INTEGER, 0), default_year);
+    IF y < 100 THEN
+        y := y + 2000;
+    END IF;
+
+    IF m IS NOT NULL AND y IS NOT NULL THEN
+        RETURN make_date(y, m, d);
+    END IF;
+
+    IF today IS NULL THEN
+        RETURN NULL;
+    END IF;
+
+    -- "31.12" in a message of January 1st is about the last year
+    IF m IS NOT NULL THEN
+        result := make_date(EXTRACT(YEAR FROM today)::INTEGER, m, d);
+        IF result > today + 1 THEN
+            result := make_date(EXTRACT(YEAR FROM today)::INTEGER - 1, m, d);
+        END IF;
+
+        RETURN result;
+    END IF;
+
+    -- "30" in a message of May 1st is about April
+    previous := today - EXTRACT(DAY FROM today)::INTEGER;
+    BEGIN
+        result := make_date(EXTRACT(YEAR FROM today)::INTEGER, EXTRACT(MONTH FROM today)::INTEGER, d);
+    EXCEPTION WHEN OTHERS THEN
+        result := NULL;
+    END;
+
+    IF result IS NULL OR result > today + 1 THEN
+        result := make_date(EXTRACT(YEAR FROM previous)::INTEGER, EXTRACT(MONTH FROM previous)::INTEGER, d);
+    END IF;
+
+    RETURN result;
+EXCEPTION WHEN OTHERS THEN
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE FUNCTION hermes_data.parse_line_date(value TEXT, today DATE, OUT date_from DATE, OUT date_to DATE) AS $$
+DECLARE
+    s TEXT;
+    parts TEXT[];
+    from_text TEXT;
+    to_text TEXT;
+    previous DATE;
+BEGIN
+    s := btrim(regexp_replace(lower(btrim(value)), '^(за|на|от)\s+', ''));
+
+    IF s IN ('сегодня', 'вчера', 'позавчера') THEN
+        date_to := today - CASE s WHEN 'вчера' THEN 1 WHEN 'позавчера' THEN 2 ELSE 0 END;
+        date_from := date_to;
+        RETURN;
+    END IF;
+
+    parts := regexp_match(s, '^(\d{4})-(\d{1,2})-(\d{1,2})$');
+    IF parts IS NOT NULL THEN
+        date_to := make_date(parts[1]::INTEGER, parts[2]::INTEGER, parts[3]::INTEGER);
+        date_from := date_to;
+        RETURN;
+    END IF;
+
+    to_text := s;
+    parts := regexp_match(s, '^(.+?)\s*[-–—]\s*(.+)$');
+    IF parts IS NOT NULL THEN
+        from_text := parts[1];
+        to_text := parts[2];
+    END IF;
+
+    date_to := hermes_data.parse_line_day(to_text, NULL, NULL, today);
+    IF date_to IS NULL OR from_text IS NULL THEN
+        date_from := date_to;
+        RETURN;
+    END IF;
+
+    date_from := hermes_data.parse_line_day(from_text,
+        EXTRACT(MONTH FROM date_to)::INTEGER, EXTRACT(YEAR FROM date_to)::INTEGER, NULL);
+
+    -- "30-02.05" starts in the previous month, "30.12-02.01" in the previous year
+    IF date_from > date_to THEN
+        CASE length(from_text) - length(translate(from_text, './', ''))
+        WHEN 0 THEN
+            previous := date_to - EXTRACT(DAY FROM date_to)::INTEGER;
+            date_from := make_date(EXTRACT(YEAR FROM previous)::INTEGER,
+                EXTRACT(MONTH FROM previous)::INTEGER, EXTRACT(DAY FROM date_from)::INTEGER);
+        WHEN 1 THEN
+            date_from := make_date(EXTRACT(YEAR FROM date_from)::INTEGER - 1,
+                EXTRACT(MONTH FROM date_from)::INTEGER, EXTRACT(DAY FROM date_from)::INTEGER);
+        ELSE
+            NULL;
+        END CASE;
+    END IF;
+
+    IF date_from IS NULL OR date_from > date_to THEN
+        date_from := NULL;
+        date_to := NULL;
+    END IF;
+EXCEPTION WHEN OTHERS THEN
+    date_from := NULL;
+    date_to := NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+INSERT INTO hermes_data.report_line (
+    report_id, message_id, table_id, line_number, version,
+    date_from, date_to, date_raw,
+    division, division_raw, unit_id, pu, department,
+    operation, operation_raw, operation_id,
+    culture, culture_raw, culture_id,
+    per_day, per_operation, val_day, val_beginning,
+    flags, created_at
+)
+SELECT
+    (
+        SELECT r.id
+        FROM hermes_data.report r
+        WHERE r.chat_context_id = c.chat_context_id
+          AND r.started_at <= m.created_at
+          AND (r.finished_at IS NULL OR r.finished_at >= m.created_at)
+        ORDER BY r.started_at DESC
+        LIMIT 1
+    ),
+    t.message_id,
+    t.id,
+    ROW_NUMBER() OVER (PARTITION BY t.message_id, t.created_at ORDER BY t.id),
+    DENSE_RANK() OVER (PARTITION BY t.message_id ORDER BY t.created_at),
+    d.date_from,
+    d.date_to,
+    NULLIF(t.data->>'date_raw', ''),
+    COALESCE(t.data->>'division', ''),
+    COALESCE(NULLIF(t.data->>'division_raw', ''), t.data->>'division'),
+    u.id,
+    NULLIF(t.data->>'pu', ''),
+    NULLIF(t.data->>'department', ''),
+    COALESCE(t.data->>'operation', ''),
+    COALESCE(NULLIF(t.data->>'operation_raw', ''), t.data->>'operation'),
+    (
+        SELECT o.id
+        FROM hermes_data.operations o
+        WHERE o.name = t.data->>'operation'
+          AND o.effective_from <= d.date_to
+          AND (o.deleted_at IS NULL OR o.deleted_at > d.date_to)
+        ORDER BY o.effective_from DESC
+        LIMIT 1
+    ),
+    COALESCE(t.data->>'culture', ''),
+    COALESCE(NULLIF(t.data->>'culture_raw', ''), t.data->>'culture'),
+    (
+        SELECT cu.id
+        FROM hermes_data.cultures cu
+        WHERE cu.name = t.data->>'culture'
+          AND cu.effective_from <= d.date_to
+          AND (cu.deleted_at IS NULL OR cu.deleted_at > d.date_to)
+        ORDER BY cu.effective_from DESC
+        LIMIT 1
+    ),
+    COALESCE((t.data->>'per_day')::DOUBLE PRECISION, 0),
+    COALESCE((t.data->>'per_operation')::DOUBLE PRECISION, 0),
+    COALESCE((t.data->>'val_day')::DOUBLE PRECISION, 0),
+    COALESCE((t.data->>'val_beginning')::DOUBLE PRECISION, 0),
+    COALESCE(t.data->'flags', '[]'),
+    t.created_at
+FROM hermes_data.tables t
+JOIN hermes_data.messages m ON m.id = t.message_id
+JOIN hermes_data.chat c ON c.id = m.chat_id
+LEFT JOIN hermes_data.units u ON u.id = NULLIF(t.data->>'unit_id', '')::INTEGER
+-- unparsable dates fall back to the message day
+CROSS JOIN LATERAL hermes_data.parse_line_date(t.data->>'date', m.created_at::DATE) p
+CROSS JOIN LATERAL (
+    SELECT
+        COALESCE(p.date_from, m.created_at::DATE) AS date_from,
+        COALESCE(p.date_to, m.created_at::DATE) AS date_to
+) d;
+
+DROP FUNCTION hermes_data.parse_line_date(TEXT, DATE);
+DROP FUNCTION hermes_data.parse_line_day(TEXT, INTEGER, INTEGER, DATE);
@@ -0,0 +1,2 @@
+ALTER TABLE hermes_data.messages
+    DROP COLUMN report_id;
@@ -0,0 +1,29 @@
+-- messages are linked to the report they were registered in
+ALTER TABLE hermes_data.messages
+    ADD COLUMN report_id INTEGER REFERENCES hermes_data.report;
+
+CREATE INDEX messages_report_idx ON hermes_data.messages (report_id);
+
+UPDATE hermes_data.messages m
+SET report_id = l.report_id
+FROM (
+    SELECT DISTINCT ON (message_id) message_id, report_id
+    FROM hermes_data.report_line
+    WHERE report_id IS NOT NULL
+    ORDER BY message_id, version DESC
+) l
+WHERE l.message_id = m.id;
+
+-- messages without lines, e.g. not recognized, fall back to the report window
+UPDATE hermes_data.messages m
+SET report_id = (
+    SELECT r.id
+    FROM hermes_data.report r
+    JOIN hermes_data.chat c ON c.chat_context_id = r.chat_context_id
+    WHERE c.id = m.chat_id
+      AND r.started_at <= m.created_at
+      AND (r.finished_at IS NULL OR r.finished_at >= m.created_at)
+    ORDER BY r.started_at DESC
+    LIMIT 1
+)
+WHERE m.report_id IS NULL;
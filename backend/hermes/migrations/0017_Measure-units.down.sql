@@ -0,0 +1,20 @@
+DROP VIEW hermes_data.report_line_current;
+
+ALTER TABLE hermes_data.report_line
+    DROP COLUMN per_day_unit,
+    DROP COLUMN per_operation_unit,
+    DROP COLUMN val_day_unit,
+    DROP COLUMN val_beginning_unit,
+    DROP COLUMN measures;
+
+CREATE VIEW hermes_data.report_line_current AS
+SELECT l.*
+FROM hermes_data.report_line l
+WHERE l.version = (
+    SELECT MAX(v.version) FROM hermes_data.report_line v WHERE v.message_id = l.message_id
+);
+
+ALTER TABLE hermes_data.operations
+    DROP COLUMN expected_unit;
+
+DROP TABLE hermes_data.measure_units;
@@ -0,0 +1,46 @@
+-- units of measure of reported quantities, factor converts the value to the
+-- base unit of the dimension: га for area, ц for mass, л for volume, шт for count
+CREATE TABLE hermes_data.measure_units (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(255) NOT NULL UNIQUE,
+    dimension VARCHAR(255) NOT NULL,
+    factor DOUBLE PRECISION NOT NULL,
+    -- spellings used by workers, lower case
+    aliases TEXT[] NOT NULL DEFAULT '{}',
+
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO hermes_data.measure_units (code, dimension, factor, aliases)
+VALUES ('га', 'area', 1, '{га, гa, гект, гектар, гектара, гектаров, ha}'),
+       ('ц', 'mass', 1, '{ц, цн, центнер, центнера, центнеров}'),
+       ('т', 'mass', 10, '{т, тн, тонна, тонны, тонн, тонну}'),
+       ('кг', 'mass', 0.01, '{кг, килограмм, килограмма, килограммов, kg}'),
+       ('л', 'volume', 1, '{л, литр, литра, литров, l}'),
+       ('м3', 'volume', 1000, '{м3, м³, куб, кубов, кубометр, кубометров}'),
+       ('шт', 'count', 1, '{шт, штук, штуки, штука, тюк, тюка, тюков, рулон, рулона, рулонов}');
+
+-- unit of the gross values (val_day, val_beginning) of the operation, ц if not set
+ALTER TABLE hermes_data.operations
+    ADD COLUMN expected_unit VARCHAR(255) REFERENCES hermes_data.measure_units (code);
+
+UPDATE hermes_data.operations SET expected_unit = 'ц' WHERE name = 'Уборка';
+
+-- unit of the stored values and the values as reported when they were converted
+ALTER TABLE hermes_data.report_line
+    ADD COLUMN per_day_unit VARCHAR(255) NOT NULL DEFAULT 'га',
+    ADD COLUMN per_operation_unit VARCHAR(255) NOT NULL DEFAULT 'га',
+    ADD COLUMN val_day_unit VARCHAR(255) NOT NULL DEFAULT 'ц',
+    ADD COLUMN val_beginning_unit VARCHAR(255) NOT NULL DEFAULT 'ц',
+    ADD COLUMN measures JSONB;
+
+-- the view lists the columns it was created with
+DROP VIEW hermes_data.report_line_current;
+
+CREATE VIEW hermes_data.report_line_current AS
+SELECT l.*
+FROM hermes_data.report_line l
+WHERE l.version = (
+    SELECT MAX(v.version) FROM hermes_data.report_line v WHERE v.message_id = l.message_id
+);
@@ -0,0 +1 @@
+DROP TABLE hermes_data.chat_context_settings;
@@ -0,0 +1,13 @@
+-- recognition defaults of a chat context, e.g. a chat of one division and crop
+CREATE TABLE hermes_data.chat_context_settings (
+    chat_context_id INTEGER PRIMARY KEY,
+    default_division VARCHAR(1023) NOT NULL DEFAULT '',
+    default_culture VARCHAR(1023) NOT NULL DEFAULT '',
+    expected_operations TEXT[] NOT NULL DEFAULT '{}',
+    -- free text passed to Apollo with every message of the context
+    prompt_hints TEXT NOT NULL DEFAULT '',
+
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (chat_context_id) REFERENCES hermes_data.chat_context
+);
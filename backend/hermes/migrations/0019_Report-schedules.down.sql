@@ -0,0 +1,3 @@
+ALTER TABLE hermes_data.report DROP COLUMN closes_at;
+
+ALTER TABLE hermes_data.chat_context_settings DROP COLUMN report_schedule;
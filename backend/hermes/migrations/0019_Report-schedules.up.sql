@@ -0,0 +1,5 @@
+-- cutoffs closing the reports of the context: cron expressions or "HH:MM", "пн HH:MM"
+ALTER TABLE hermes_data.chat_context_settings ADD COLUMN report_schedule TEXT[] NOT NULL DEFAULT '{}';
+
+-- NULL for reports opened before schedules, the reporter fills it by the schedule
+ALTER TABLE hermes_data.report ADD COLUMN closes_at TIMESTAMP;
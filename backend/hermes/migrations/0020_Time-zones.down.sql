@@ -0,0 +1,3 @@
+ALTER TABLE hermes_data.report DROP COLUMN time_zone;
+
+ALTER TABLE hermes_data.chat_context_settings DROP COLUMN time_zone;
@@ -0,0 +1,6 @@
+-- IANA time zone of the context, e.g. Asia/Novosibirsk; empty for the default one
+ALTER TABLE hermes_data.chat_context_settings ADD COLUMN time_zone VARCHAR(255) NOT NULL DEFAULT '';
+
+-- the time zone the report was named and windowed in, earlier reports used Moscow time
+ALTER TABLE hermes_data.report ADD COLUMN time_zone VARCHAR(255) NOT NULL DEFAULT 'Europe/Moscow';
+ALTER TABLE hermes_data.report ALTER COLUMN time_zone DROP DEFAULT;
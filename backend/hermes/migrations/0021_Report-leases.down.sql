@@ -0,0 +1,3 @@
+DROP TABLE hermes_data.report_lease;
+
+DROP INDEX hermes_data.report_open_chat_context_idx;
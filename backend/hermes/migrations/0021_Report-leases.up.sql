@@ -0,0 +1,19 @@
+-- a chat context has at most one open report, the earlier duplicates are finished
+UPDATE hermes_data.report r
+SET finished_at = r.last_updated_at
+WHERE r.finished_at IS NULL AND EXISTS (
+    SELECT 1 FROM hermes_data.report o
+    WHERE o.chat_context_id = r.chat_context_id AND o.finished_at IS NULL AND o.id > r.id
+);
+
+CREATE UNIQUE INDEX report_open_chat_context_idx ON hermes_data.report (chat_context_id) WHERE finished_at IS NULL;
+
+-- the hermes instance processing the messages of the chat context, the lease
+-- is renewed while the instance is alive and taken over when it expires
+CREATE TABLE hermes_data.report_lease (
+    chat_context_id INTEGER PRIMARY KEY,
+    owner VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+
+    FOREIGN KEY (chat_context_id) REFERENCES hermes_data.chat_context
+);
@@ -0,0 +1 @@
+ALTER TABLE hermes_data.chat_context_settings DROP COLUMN report_delivery;
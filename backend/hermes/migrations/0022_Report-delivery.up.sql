@@ -0,0 +1,2 @@
+-- how the closed report is delivered to the chats: link, file or link_and_file
+ALTER TABLE hermes_data.chat_context_settings ADD COLUMN report_delivery VARCHAR(32) NOT NULL DEFAULT 'link';
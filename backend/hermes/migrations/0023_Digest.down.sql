@@ -0,0 +1,3 @@
+DROP TABLE hermes_data.digest;
+
+DROP TABLE hermes_data.digest_recipient;
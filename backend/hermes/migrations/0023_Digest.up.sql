@@ -0,0 +1,21 @@
+-- the chats and the emails receiving the management digest
+CREATE TABLE hermes_data.digest_recipient (
+    id SERIAL PRIMARY KEY,
+    chat_id INTEGER,
+    email VARCHAR(255),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (chat_id) REFERENCES hermes_data.chat ON DELETE CASCADE,
+    CHECK ((chat_id IS NULL) <> (email IS NULL))
+);
+
+CREATE UNIQUE INDEX digest_recipient_chat_idx ON hermes_data.digest_recipient(chat_id) WHERE chat_id IS NOT NULL;
+CREATE UNIQUE INDEX digest_recipient_email_idx ON hermes_data.digest_recipient(lower(email)) WHERE email IS NOT NULL;
+
+-- the digests sent, a digest is sent by the instance that inserted its period
+CREATE TABLE hermes_data.digest (
+    id SERIAL PRIMARY KEY,
+    period_from TIMESTAMP NOT NULL,
+    period_to TIMESTAMP NOT NULL UNIQUE,
+    sent_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
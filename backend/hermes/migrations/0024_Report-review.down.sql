@@ -0,0 +1,5 @@
+DROP TABLE hermes_data.report_review;
+
+ALTER TABLE hermes_data.chat_context_settings DROP COLUMN review_required;
+
+ALTER TABLE hermes_data.report DROP COLUMN status;
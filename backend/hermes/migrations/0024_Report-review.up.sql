@@ -0,0 +1,25 @@
+-- the review state of the report: collecting while it is open, then
+-- pending_review or approved when it is closed, approved or rejected by a
+-- reviewer; the reports closed before are approved
+ALTER TABLE hermes_data.report ADD COLUMN status VARCHAR(32) NOT NULL DEFAULT 'collecting';
+
+UPDATE hermes_data.report SET status = 'approved' WHERE finished_at IS NOT NULL;
+
+-- the closed reports of the context wait for the review before they are delivered
+ALTER TABLE hermes_data.chat_context_settings ADD COLUMN review_required BOOLEAN NOT NULL DEFAULT false;
+
+-- the review actions on the reports: approve, reject or edit
+CREATE TABLE hermes_data.report_review (
+    id SERIAL,
+    report_id INTEGER NOT NULL,
+    worker_id INTEGER NOT NULL,
+    action VARCHAR(32) NOT NULL,
+    comment TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+
+    PRIMARY KEY (id),
+    FOREIGN KEY (report_id) REFERENCES hermes_data.report,
+    FOREIGN KEY (worker_id) REFERENCES hermes_data.worker
+);
+
+CREATE INDEX report_review_report_idx ON hermes_data.report_review (report_id);
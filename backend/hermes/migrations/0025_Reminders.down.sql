@@ -0,0 +1,6 @@
+DROP TABLE hermes_data.reminder;
+
+ALTER TABLE hermes_data.chat_context_settings DROP COLUMN escalate_before_minutes;
+ALTER TABLE hermes_data.chat_context_settings DROP COLUMN remind_before_minutes;
+
+DROP TABLE hermes_data.expected_reporter;
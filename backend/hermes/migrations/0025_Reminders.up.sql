@@ -0,0 +1,32 @@
+-- the workers expected to report in the chat context, e.g. one per division
+CREATE TABLE hermes_data.expected_reporter (
+    id SERIAL,
+    chat_context_id INTEGER NOT NULL,
+    worker_id INTEGER NOT NULL,
+    division VARCHAR(1023) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (id),
+    UNIQUE (chat_context_id, worker_id, division),
+    FOREIGN KEY (chat_context_id) REFERENCES hermes_data.chat_context,
+    FOREIGN KEY (worker_id) REFERENCES hermes_data.worker
+);
+
+-- the workers who have not reported are reminded remind_before_minutes before
+-- the cutoff, the listeners are told escalate_before_minutes before it
+ALTER TABLE hermes_data.chat_context_settings ADD COLUMN remind_before_minutes INTEGER;
+ALTER TABLE hermes_data.chat_context_settings ADD COLUMN escalate_before_minutes INTEGER;
+
+-- the sent reminders, a reminder of a cutoff is sent once among the instances
+CREATE TABLE hermes_data.reminder (
+    id SERIAL,
+    chat_context_id INTEGER NOT NULL,
+    cutoff TIMESTAMP NOT NULL,
+    kind VARCHAR(32) NOT NULL,
+    worker_ids INTEGER[] NOT NULL,
+    sent_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (id),
+    UNIQUE (chat_context_id, cutoff, kind),
+    FOREIGN KEY (chat_context_id) REFERENCES hermes_data.chat_context
+);
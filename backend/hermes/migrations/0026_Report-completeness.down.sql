@@ -0,0 +1,5 @@
+DROP TABLE hermes_data.report_missing_item;
+
+DROP TABLE hermes_data.report_completeness;
+
+DROP TABLE hermes_data.report_expectation;
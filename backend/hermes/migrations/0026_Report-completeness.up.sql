@@ -0,0 +1,40 @@
+-- the divisions and operations expected in every report of the chat context,
+-- in the season between season_from and season_to when they are set
+CREATE TABLE hermes_data.report_expectation (
+    id SERIAL,
+    chat_context_id INTEGER NOT NULL,
+    division VARCHAR(1023) NOT NULL,
+    operation VARCHAR(1023) NOT NULL,
+    season_from DATE,
+    season_to DATE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (id),
+    FOREIGN KEY (chat_context_id) REFERENCES hermes_data.chat_context
+);
+
+CREATE INDEX report_expectation_chat_context_idx ON hermes_data.report_expectation (chat_context_id);
+
+-- the coverage of the expectations by the closed report
+CREATE TABLE hermes_data.report_completeness (
+    report_id INTEGER NOT NULL,
+    expected INTEGER NOT NULL,
+    covered INTEGER NOT NULL,
+    computed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (report_id),
+    FOREIGN KEY (report_id) REFERENCES hermes_data.report
+);
+
+-- the expected divisions and operations missing in the report
+CREATE TABLE hermes_data.report_missing_item (
+    id SERIAL,
+    report_id INTEGER NOT NULL,
+    division VARCHAR(1023) NOT NULL,
+    operation VARCHAR(1023) NOT NULL,
+
+    PRIMARY KEY (id),
+    FOREIGN KEY (report_id) REFERENCES hermes_data.report
+);
+
+CREATE INDEX report_missing_item_report_idx ON hermes_data.report_missing_item (report_id);
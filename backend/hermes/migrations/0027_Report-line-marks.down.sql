@@ -0,0 +1,16 @@
+DROP VIEW hermes_data.report_line_current;
+
+ALTER TABLE hermes_data.report_line
+    DROP COLUMN division_yellow,
+    DROP COLUMN division_suggestion,
+    DROP COLUMN operation_yellow,
+    DROP COLUMN operation_suggestion,
+    DROP COLUMN culture_yellow,
+    DROP COLUMN culture_suggestion;
+
+CREATE VIEW hermes_data.report_line_current AS
+SELECT l.*
+FROM hermes_data.report_line l
+WHERE l.version = (
+    SELECT MAX(v.version) FROM hermes_data.report_line v WHERE v.message_id = l.message_id
+);
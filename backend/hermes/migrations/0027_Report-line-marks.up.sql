@@ -0,0 +1,41 @@
+-- the values not matched with the dictionaries and the closest entries, the
+-- marks were kept in the json of hermes_data.tables only
+ALTER TABLE hermes_data.report_line
+    ADD COLUMN division_yellow BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN division_suggestion VARCHAR(1023),
+    ADD COLUMN operation_yellow BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN operation_suggestion VARCHAR(1023),
+    ADD COLUMN culture_yellow BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN culture_suggestion VARCHAR(1023);
+
+-- the json of some lines lacks the marks, those are checked against the
+-- dictionaries in force at the line date
+UPDATE hermes_data.report_line l
+SET
+    division_suggestion = NULLIF(t.data->>'division_suggestion', ''),
+    operation_suggestion = NULLIF(t.data->>'operation_suggestion', ''),
+    culture_suggestion = NULLIF(t.data->>'culture_suggestion', ''),
+    division_yellow = COALESCE(
+        (t.data->>'DivisionYellow')::BOOLEAN,
+        l.division = '' OR NOT EXISTS (
+            SELECT 1
+            FROM hermes_data.units u
+            WHERE u.division = l.division
+              AND u.effective_from <= l.date_to
+              AND (u.deleted_at IS NULL OR u.deleted_at > l.date_to)
+        )
+    ),
+    operation_yellow = COALESCE((t.data->>'OperationYellow')::BOOLEAN, l.operation_id IS NULL),
+    culture_yellow = COALESCE((t.data->>'CultureYellow')::BOOLEAN, l.culture_id IS NULL)
+FROM hermes_data.tables t
+WHERE t.id = l.table_id;
+
+-- the view lists the columns it was created with
+DROP VIEW hermes_data.report_line_current;
+
+CREATE VIEW hermes_data.report_line_current AS
+SELECT l.*
+FROM hermes_data.report_line l
+WHERE l.version = (
+    SELECT MAX(v.version) FROM hermes_data.report_line v WHERE v.message_id = l.message_id
+);
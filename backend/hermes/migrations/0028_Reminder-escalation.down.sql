@@ -0,0 +1,3 @@
+ALTER TABLE hermes_data.chat_context_settings ADD COLUMN escalate_before_minutes INTEGER;
+
+ALTER TABLE hermes_data.chat_context_settings DROP COLUMN escalate_after_minutes;
@@ -0,0 +1,9 @@
+-- the listeners are told about the missing reports escalate_after_minutes
+-- after the cutoff, the escalations set before the cutoff move to the cutoff
+ALTER TABLE hermes_data.chat_context_settings ADD COLUMN escalate_after_minutes INTEGER;
+
+UPDATE hermes_data.chat_context_settings
+SET escalate_after_minutes = 0
+WHERE escalate_before_minutes IS NOT NULL;
+
+ALTER TABLE hermes_data.chat_context_settings DROP COLUMN escalate_before_minutes;
@@ -0,0 +1,6 @@
+DELETE FROM hermes_data.digest WHERE sent_at IS NULL;
+
+ALTER TABLE hermes_data.digest ALTER COLUMN sent_at SET NOT NULL;
+ALTER TABLE hermes_data.digest ALTER COLUMN sent_at SET DEFAULT CURRENT_TIMESTAMP;
+
+ALTER TABLE hermes_data.digest DROP COLUMN claimed_at;
@@ -0,0 +1,8 @@
+-- a digest is claimed before it is delivered and marked sent after it, a
+-- claim left without delivery, e.g. by a crashed instance, is taken over
+ALTER TABLE hermes_data.digest ADD COLUMN claimed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+UPDATE hermes_data.digest SET claimed_at = sent_at;
+
+ALTER TABLE hermes_data.digest ALTER COLUMN sent_at DROP DEFAULT;
+ALTER TABLE hermes_data.digest ALTER COLUMN sent_at DROP NOT NULL;
@@ -0,0 +1 @@
+-- the parsed dates are kept, they are what 0015 backfills now
@@ -0,0 +1,143 @@
+-- the lines backfilled by 0015 took only "02.01.2006" dates, Apollo's
+-- year-less and the stub's ISO dates fell back to the message day. The
+-- lines left on the message day are parsed again.
+
+-- parse_line_day and parse_line_date mirror models.ParseDate without month
+-- names: "02.01.2006", "02.01.06", Apollo's "02.01" and "02", the ISO
+-- "2006-01-02" of the stub, "вчера" and ranges like "30.12-02.01". A day
+-- without the year or the month is taken from the message day, the previous
+-- year or month when it would be more than a day after it.
+CREATE FUNCTION hermes_data.parse_line_day(value TEXT, default_month INTEGER, default_year INTEGER, today DATE) RETURNS DATE AS $$
+DECLARE
+    parts TEXT[];
+    d INTEGER;
+    m INTEGER;
+    y INTEGER;
+    result DATE;
+    previous DATE;
+BEGIN
+    parts := regexp_match(btrim(value), '^(\d{1,2})(?:[./](\d{1,2})(?:[./](\d{2}|\d{4}))?)?$');
+    IF parts IS NULL THEN
+        RETURN NULL;
+    END IF;
+
+    d := parts[1]::INTEGER;
+    m := COALESCE(NULLIF(parts[2]::INTEGER, 0), default_month);
+    y := COALESCE(NULLIF(parts[3]::INTEGER, 0), default_year);
+    IF y < 100 THEN
+        y := y + 2000;
+    END IF;
+
+    IF m IS NOT NULL AND y IS NOT NULL THEN
+        RETURN make_date(y, m, d);
+    END IF;
+
+    IF today IS NULL THEN
+        RETURN NULL;
+    END IF;
+
+    -- "31.12" in a message of January 1st is about the last year
+    IF m IS NOT NULL THEN
+        result := make_date(EXTRACT(YEAR FROM today)::INTEGER, m, d);
+        IF result > today + 1 THEN
+            result := make_date(EXTRACT(YEAR FROM today)::INTEGER - 1, m, d);
+        END IF;
+
+        RETURN result;
+    END IF;
+
+    -- "30" in a message of May 1st is about April
+    previous := today - EXTRACT(DAY FROM today)::INTEGER;
+    BEGIN
+        result := make_date(EXTRACT(YEAR FROM today)::INTEGER, EXTRACT(MONTH FROM today)::INTEGER, d);
+    EXCEPTION WHEN OTHERS THEN
+        result := NULL;
+    END;
+
+    IF result IS NULL OR result > today + 1 THEN
+        result := make_date(EXTRACT(YEAR FROM previous)::INTEGER, EXTRACT(MONTH FROM previous)::INTEGER, d);
+    END IF;
+
+    RETURN result;
+EXCEPTION WHEN OTHERS THEN
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE FUNCTION hermes_data.parse_line_date(value TEXT, today DATE, OUT date_from DATE, OUT date_to DATE) AS $$
+DECLARE
+    s TEXT;
+    parts TEXT[];
+    from_text TEXT;
+    to_text TEXT;
+    previous DATE;
+BEGIN
+    s := btrim(regexp_replace(lower(btrim(value)), '^(за|на|от)\s+', ''));
+
+    IF s IN ('сегодня', 'вчера', 'позавчера') THEN
+        date_to := today - CASE s WHEN 'вчера' THEN 1 WHEN 'позавчера' THEN 2 ELSE 0 END;
+        date_from := date_to;
+        RETURN;
+    END IF;
+
+    parts := regexp_match(s, '^(\d{4})-(\d{1,2})-(\d{1,2})$');
+    IF parts IS NOT NULL THEN
+        date_to := make_date(parts[1]::INTEGER, parts[2]::INTEGER, parts[3]::INTEGER);
+        date_from := date_to;
+        RETURN;
+    END IF;
+
+    to_text := s;
+    parts := regexp_match(s, '^(.+?)\s*[-–—]\s*(.+)$');
+    IF parts IS NOT NULL THEN
+        from_text := parts[1];
+        to_text := parts[2];
+    END IF;
+
+    date_to := hermes_data.parse_line_day(to_text, NULL, NULL, today);
+    IF date_to IS NULL OR from_text IS NULL THEN
+        date_from := date_to;
+        RETURN;
+    END IF;
+
+    date_from := hermes_data.parse_line_day(from_text,
+        EXTRACT(MONTH FROM date_to)::INTEGER, EXTRACT(YEAR FROM date_to)::INTEGER, NULL);
+
+    -- "30-02.05" starts in the previous month, "30.12-02.01" in the previous year
+    IF date_from > date_to THEN
+        CASE length(from_text) - length(translate(from_text, './', ''))
+        WHEN 0 THEN
+            previous := date_to - EXTRACT(DAY FROM date_to)::INTEGER;
+            date_from := make_date(EXTRACT(YEAR FROM previous)::INTEGER,
+                EXTRACT(MONTH FROM previous)::INTEGER, EXTRACT(DAY FROM date_from)::INTEGER);
+        WHEN 1 THEN
+            date_from := make_date(EXTRACT(YEAR FROM date_from)::INTEGER - 1,
+                EXTRACT(MONTH FROM date_from)::INTEGER, EXTRACT(DAY FROM date_from)::INTEGER);
+        ELSE
+            NULL;
+        END CASE;
+    END IF;
+
+    IF date_from IS NULL OR date_from > date_to THEN
+        date_from := NULL;
+        date_to := NULL;
+    END IF;
+EXCEPTION WHEN OTHERS THEN
+    date_from := NULL;
+    date_to := NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+UPDATE hermes_data.report_line l
+SET date_from = p.date_from, date_to = p.date_to
+FROM hermes_data.tables t
+JOIN hermes_data.messages m ON m.id = t.message_id
+CROSS JOIN LATERAL hermes_data.parse_line_date(t.data->>'date', m.created_at::DATE) p
+WHERE l.table_id = t.id
+  AND l.date_from = m.created_at::DATE
+  AND l.date_to = m.created_at::DATE
+  AND p.date_to IS NOT NULL
+  AND (p.date_from, p.date_to) IS DISTINCT FROM (l.date_from, l.date_to);
+
+DROP FUNCTION hermes_data.parse_line_date(TEXT, DATE);
+DROP FUNCTION hermes_data.parse_line_day(TEXT, INTEGER, INTEGER, DATE);
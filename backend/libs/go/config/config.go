@@ -53,6 +53,14 @@ func loadConfig(val reflect.Value) error {
 
 			val.Field(i).SetInt(x)
 
+		case reflect.Float64:
+			x, err := strconv.ParseFloat(x, 64)
+			if err != nil {
+				return err // TODO: wrap error
+			}
+
+			val.Field(i).SetFloat(x)
+
 		case reflect.Bool:
 			x, err := strconv.ParseBool(x)
 			if err != nil {
@@ -1,13 +1,57 @@
 package loctime
 
 import (
+	"fmt"
 	"time"
 )
 
+// DefaultName is the time zone used until SetDefault is called.
+const DefaultName = "Europe/Moscow"
+
 var loc *time.Location
 
 func init() {
-	loc, _ = time.LoadLocation("Europe/Moscow")
+	loc, _ = time.LoadLocation(DefaultName)
+}
+
+// SetDefault replaces the time zone used by Transfer and Load.
+func SetDefault(name string) error {
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return fmt.Errorf("failed to load time zone %q: %w", name, err)
+	}
+
+	loc = location
+	return nil
+}
+
+// Default returns the default time zone.
+func Default() *time.Location {
+	return loc
+}
+
+// Load returns the time zone by its IANA name, the default one for an empty name.
+func Load(name string) (*time.Location, error) {
+	if name == "" {
+		return loc, nil
+	}
+
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load time zone %q: %w", name, err)
+	}
+
+	return location, nil
+}
+
+// LoadOrDefault returns the time zone by its name, the default one when it can not be loaded.
+func LoadOrDefault(name string) *time.Location {
+	location, err := Load(name)
+	if err != nil {
+		return loc
+	}
+
+	return location
 }
 
 func Transfer(t time.Time) time.Time {